@@ -0,0 +1,93 @@
+// Package kongkit integrates the configuration templates of the template package with Kong command lines.
+package kongkit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/vsysa/kongkit/template"
+)
+
+// ConfigTemplateFlag is a flag type printing a configuration template and exiting with a 0 exit status,
+// as kong.VersionFlag does for the version. The configuration struct of the template is bound to the parser
+// with BindConfigTemplate:
+//
+//	type CLI struct {
+//		DumpConfig kongkit.ConfigTemplateFlag `help:"Print a config template and exit."`
+//	}
+//
+//	kong.Parse(&cli, kongkit.BindConfigTemplate(Config{}))
+//
+// The flag takes the format of the template as an optional value: --dump-config prints a YAML template,
+// --dump-config=json a JSON template and --dump-config=env a .env template.
+type ConfigTemplateFlag string
+
+// The formats of the templates printed by ConfigTemplateFlag.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatEnv  = "env"
+)
+
+// configTemplate is the configuration struct bound to the parser for ConfigTemplateFlag.
+type configTemplate struct {
+	cfg  interface{}
+	opts []template.TemplateOption
+}
+
+// BindConfigTemplate binds the configuration struct printed by ConfigTemplateFlag, with the options of the template.
+func BindConfigTemplate(cfg interface{}, opts ...template.TemplateOption) kong.Option {
+	return kong.Bind(configTemplate{cfg: cfg, opts: opts})
+}
+
+// Decode reads the format from the value of the flag, if any, defaulting to YAML.
+func (f *ConfigTemplateFlag) Decode(ctx *kong.DecodeContext) error {
+	if ctx.Scan.Peek().Type != kong.FlagValueToken {
+		*f = FormatYAML
+		return nil
+	}
+	token := ctx.Scan.Pop()
+	format, ok := token.Value.(string)
+	if !ok {
+		return fmt.Errorf("expected a template format but got %q (%T)", token, token.Value)
+	}
+	switch format = strings.ToLower(format); format {
+	case FormatYAML, FormatJSON, FormatEnv:
+		*f = ConfigTemplateFlag(format)
+		return nil
+	default:
+		return fmt.Errorf("template format must be %s, %s or %s but got %q", FormatYAML, FormatJSON, FormatEnv, format)
+	}
+}
+
+// BeforeReset writes the template in the format of the flag and terminates with a 0 exit status.
+// It runs before required flags are checked, so that the template can be printed on its own.
+func (f ConfigTemplateFlag) BeforeReset(app *kong.Kong, ctx *kong.Context, trace *kong.Path, target configTemplate) error {
+	format, _ := ctx.FlagValue(trace.Flag).(ConfigTemplateFlag)
+	if format == "" {
+		return nil
+	}
+	if err := writeConfigTemplate(app.Stdout, string(format), target); err != nil {
+		return err
+	}
+	app.Exit(0)
+	return nil
+}
+
+// Writes the template of the bound configuration struct in a format.
+func writeConfigTemplate(w io.Writer, format string, target configTemplate) error {
+	var output string
+	switch format {
+	case FormatJSON:
+		output = template.GenerateJSONTemplate(target.cfg, target.opts...)
+	case FormatEnv:
+		output = template.GenerateEnvTemplate(target.cfg, target.opts...)
+	default:
+		output = template.GenerateYAMLTemplate(target.cfg, target.opts...)
+	}
+	_, err := io.WriteString(w, output)
+	return err
+}
@@ -0,0 +1,77 @@
+package kongkit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/template"
+)
+
+type flagConfig struct {
+	Host string `yaml:"host" env:"APP_HOST" default:"localhost" help:"The hostname"`
+	Port int    `yaml:"port" env:"APP_PORT" default:"8080"`
+}
+
+type flagCLI struct {
+	DumpConfig ConfigTemplateFlag `help:"Print a config template and exit."`
+	Name       string             `required:""`
+}
+
+// Parses the arguments with a ConfigTemplateFlag, returning the output and the exit status, or -1 if the parser did not exit.
+func parseFlagCLI(t *testing.T, args ...string) (output string, status int, err error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	status = -1
+	parser, err := kong.New(&flagCLI{},
+		BindConfigTemplate(flagConfig{}),
+		kong.Writers(&stdout, &stdout),
+		kong.Exit(func(code int) {
+			status = code
+			panic(status)
+		}))
+	require.NoError(t, err)
+	defer func() {
+		if recovered := recover(); recovered != nil && recovered != status {
+			panic(recovered)
+		}
+		output = stdout.String()
+	}()
+	_, err = parser.Parse(args)
+	return
+}
+
+// Test printing the template in each format, without the required flags.
+func TestConfigTemplateFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--dump-config"}, template.GenerateYAMLTemplate(flagConfig{})},
+		{[]string{"--dump-config=yaml"}, template.GenerateYAMLTemplate(flagConfig{})},
+		{[]string{"--dump-config=json"}, template.GenerateJSONTemplate(flagConfig{})},
+		{[]string{"--dump-config=env"}, template.GenerateEnvTemplate(flagConfig{})},
+	}
+	for _, tt := range tests {
+		output, status, err := parseFlagCLI(t, tt.args...)
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, 0, status, tt.args)
+		assert.Equal(t, tt.expected, output, tt.args)
+	}
+	assert.Contains(t, template.GenerateEnvTemplate(flagConfig{}), "APP_PORT=8080")
+}
+
+// Test that the flag does nothing when it is not given, and that unknown formats are rejected.
+func TestConfigTemplateFlag_NotGiven(t *testing.T) {
+	output, status, err := parseFlagCLI(t, "--name=app")
+	require.NoError(t, err)
+	assert.Equal(t, -1, status, "The parser should not exit")
+	assert.Empty(t, output)
+
+	_, status, err = parseFlagCLI(t, "--dump-config=xml")
+	assert.ErrorContains(t, err, `template format must be yaml, json or env but got "xml"`)
+	assert.Equal(t, -1, status)
+}
@@ -0,0 +1,129 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ShellType identifies the shell a completion script is generated for.
+type ShellType int
+
+const (
+	// ShellBash generates a completion function registered with `complete -F`.
+	ShellBash ShellType = iota
+	// ShellZsh generates a completion function registered with `compdef`.
+	ShellZsh
+	// ShellFish generates `complete` commands.
+	ShellFish
+)
+
+// nonIdentifier matches the characters that are not allowed in shell function names.
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// GenerateShellCompletion generates a completion script of the given shell for an application using
+// the configuration struct as its Kong flags. All flags are completed by name, and the values of flags
+// with an `enum` tag are completed from the enum. Flag names are resolved as in ParseKongTagsFromStruct.
+// The script can be sourced from the shell profile or installed in the completion directory of the shell.
+func GenerateShellCompletion(cfg interface{}, appName string, shell ShellType) (string, error) {
+	if appName == "" || strings.ContainsAny(appName, " \t\n'\"") {
+		return "", fmt.Errorf("invalid application name %q", appName)
+	}
+	if _, _, err := configStruct(cfg); err != nil {
+		return "", err
+	}
+	fields := ParseKongTagsFromStruct(cfg)
+	function := nonIdentifier.ReplaceAllString(appName, "_")
+
+	switch shell {
+	case ShellBash:
+		return bashCompletion(fields, appName, function), nil
+	case ShellZsh:
+		return zshCompletion(fields, appName, function), nil
+	case ShellFish:
+		return fishCompletion(fields, appName), nil
+	default:
+		return "", fmt.Errorf("unsupported shell type %d", shell)
+	}
+}
+
+func bashCompletion(fields []KongFieldInfo, appName, function string) string {
+	var builder strings.Builder
+	var flags []string
+
+	builder.WriteString(fmt.Sprintf("_%s_completions() {\n", function))
+	builder.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	builder.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	builder.WriteString("    case \"$prev\" in\n")
+	for _, field := range fields {
+		flags = append(flags, "--"+field.Name)
+		if len(field.Enum) == 0 {
+			continue
+		}
+		pattern := "--" + field.Name
+		if field.Short != "" {
+			pattern += "|-" + field.Short
+		}
+		builder.WriteString(fmt.Sprintf("        %s)\n", pattern))
+		builder.WriteString(fmt.Sprintf("            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(field.Enum, " ")))
+		builder.WriteString("            return\n")
+		builder.WriteString("            ;;\n")
+	}
+	builder.WriteString("    esac\n")
+	builder.WriteString(fmt.Sprintf("    COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(flags, " ")))
+	builder.WriteString("}\n")
+	builder.WriteString(fmt.Sprintf("complete -F _%s_completions %s\n", function, appName))
+	return builder.String()
+}
+
+func zshCompletion(fields []KongFieldInfo, appName, function string) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("#compdef %s\n\n", appName))
+	builder.WriteString(fmt.Sprintf("_%s() {\n", function))
+	builder.WriteString("    _arguments")
+	for _, field := range fields {
+		spec := fmt.Sprintf("--%s[%s]", field.Name, zshEscape(field.Help))
+		switch {
+		case len(field.Enum) > 0:
+			spec += fmt.Sprintf(":%s:(%s)", field.Name, strings.Join(field.Enum, " "))
+		case field.Type != "bool":
+			spec += fmt.Sprintf(":%s:", field.Name)
+		}
+		builder.WriteString(" \\\n        " + shellQuote(spec))
+	}
+	builder.WriteString("\n}\n\n")
+	builder.WriteString(fmt.Sprintf("compdef _%s %s\n", function, appName))
+	return builder.String()
+}
+
+func fishCompletion(fields []KongFieldInfo, appName string) string {
+	var builder strings.Builder
+	for _, field := range fields {
+		builder.WriteString(fmt.Sprintf("complete -c %s -l %s", appName, field.Name))
+		if field.Short != "" {
+			builder.WriteString(" -s " + field.Short)
+		}
+		if field.Help != "" {
+			builder.WriteString(" -d " + shellQuote(field.Help))
+		}
+		switch {
+		case len(field.Enum) > 0:
+			builder.WriteString(" -x -a " + shellQuote(strings.Join(field.Enum, " ")))
+		case field.Type != "bool":
+			builder.WriteString(" -r")
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Escapes the brackets closing the description of a zsh _arguments specification.
+func zshEscape(text string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text)
+}
+
+// Quotes a text with single quotes for the shell.
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
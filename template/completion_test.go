@@ -0,0 +1,75 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type completionConfig struct {
+	Host     string `yaml:"host" help:"The hostname"`
+	LogLevel string `yaml:"log_level" short:"l" enum:"debug,info,warn" help:"Log level"`
+	Verbose  bool   `yaml:"verbose"`
+}
+
+// Test bash completion generation.
+func TestGenerateShellCompletion_Bash(t *testing.T) {
+	script, err := GenerateShellCompletion(completionConfig{}, "my-app", ShellBash)
+	require.NoError(t, err)
+
+	expected := `_my_app_completions() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    case "$prev" in
+        --log-level|-l)
+            COMPREPLY=($(compgen -W "debug info warn" -- "$cur"))
+            return
+            ;;
+    esac
+    COMPREPLY=($(compgen -W "--host --log-level --verbose" -- "$cur"))
+}
+complete -F _my_app_completions my-app
+`
+	assert.Equal(t, expected, script)
+}
+
+// Test zsh completion generation.
+func TestGenerateShellCompletion_Zsh(t *testing.T) {
+	script, err := GenerateShellCompletion(completionConfig{}, "my-app", ShellZsh)
+	require.NoError(t, err)
+
+	expected := `#compdef my-app
+
+_my_app() {
+    _arguments \
+        '--host[The hostname]:host:' \
+        '--log-level[Log level]:log-level:(debug info warn)' \
+        '--verbose[]'
+}
+
+compdef _my_app my-app
+`
+	assert.Equal(t, expected, script)
+}
+
+// Test fish completion generation.
+func TestGenerateShellCompletion_Fish(t *testing.T) {
+	script, err := GenerateShellCompletion(completionConfig{}, "my-app", ShellFish)
+	require.NoError(t, err)
+
+	expected := `complete -c my-app -l host -d 'The hostname' -r
+complete -c my-app -l log-level -s l -d 'Log level' -x -a 'debug info warn'
+complete -c my-app -l verbose
+`
+	assert.Equal(t, expected, script)
+}
+
+// Test that invalid arguments are reported.
+func TestGenerateShellCompletion_Errors(t *testing.T) {
+	_, err := GenerateShellCompletion(completionConfig{}, "", ShellBash)
+	assert.Error(t, err, "Empty application name should be rejected")
+
+	_, err = GenerateShellCompletion(completionConfig{}, "app", ShellType(42))
+	assert.EqualError(t, err, "unsupported shell type 42")
+}
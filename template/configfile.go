@@ -0,0 +1,206 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a configuration file by ValidateConfigFile.
+type ValidationError struct {
+	// Field is the dotted path of the field or key; elements of slices of structs are indexed, e.g. servers[0].name.
+	Field string
+	// Message describes the problem.
+	Message string
+	// Line is the line of the file the problem was found at, or 0 if it is not known.
+	Line int
+}
+
+func (e ValidationError) Error() string {
+	var builder strings.Builder
+	if e.Line > 0 {
+		builder.WriteString(fmt.Sprintf("line %d: ", e.Line))
+	}
+	if e.Field != "" {
+		builder.WriteString(e.Field + ": ")
+	}
+	builder.WriteString(e.Message)
+	return builder.String()
+}
+
+// validationOptions holds the settings of ValidateConfigFile.
+type validationOptions struct {
+	strictKeys bool
+}
+
+// ValidationOption defines a function signature for setting the options of ValidateConfigFile.
+type ValidationOption func(*validationOptions)
+
+// WithStrictKeys
+// This option makes ValidateConfigFile report the top-level keys of the file that do not match any field of the struct,
+// e.g. misspelled keys that would otherwise be silently ignored.
+func WithStrictKeys() ValidationOption {
+	return func(o *validationOptions) {
+		o.strictKeys = true
+	}
+}
+
+// ValidateConfigFile checks a YAML configuration file against the metadata of its configuration struct,
+// turning the tags used for templates into a lightweight linter:
+// required fields must be present and not null, string fields with an `enum` tag must have one of the allowed values,
+// and numeric fields with `min` or `max` tags must be within range. Unknown top-level keys are reported with WithStrictKeys.
+// Keys are resolved with the same tag rules as GenerateYAMLTemplate, and every element of a slice of structs is checked.
+// Problems reading or parsing the file, or found in the struct, are returned as a single error without a field.
+func ValidateConfigFile(cfg interface{}, configFilePath string, opts ...ValidationOption) []ValidationError {
+	options := &validationOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return []ValidationError{{Message: fmt.Sprintf("failed to read config file: %v", err)}}
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return []ValidationError{{Message: fmt.Sprintf("failed to parse YAML: %v", err)}}
+	}
+	root, err := ParseConfigTree(cfg)
+	if err != nil {
+		return []ValidationError{{Message: fmt.Sprintf("invalid configuration struct: %v", err)}}
+	}
+
+	// An empty file is an empty mapping
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	if len(document.Content) > 0 && !isYAMLNull(document.Content[0]) {
+		mapping = document.Content[0]
+	}
+	if mapping.Kind != yaml.MappingNode {
+		return []ValidationError{{Message: "YAML document is not a mapping", Line: mapping.Line}}
+	}
+
+	v := &configValidator{options: options}
+	v.checkMapping(root.Children, mapping, "")
+	if options.strictKeys {
+		v.checkUnknownKeys(root.Children, mapping)
+	}
+	return v.errs
+}
+
+// configValidator collects the problems found by ValidateConfigFile.
+type configValidator struct {
+	options *validationOptions
+	errs    []ValidationError
+}
+
+// Appends a problem to the result.
+func (v *configValidator) add(field string, line int, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...), Line: line})
+}
+
+// Checks the values of a mapping against the fields of a struct.
+// The fields of nested structs missing from the file are checked as well, so that required fields are reported.
+func (v *configValidator) checkMapping(nodes []*Node, mapping *yaml.Node, parent string) {
+	for _, node := range nodes {
+		path := joinPath(parent, node.Name)
+		value := mappingValue(mapping, node.Name)
+
+		if value == nil || isYAMLNull(value) {
+			if node.Required {
+				line := mapping.Line
+				message := "required field is missing"
+				if value != nil {
+					line, message = value.Line, "required field is null"
+				}
+				v.add(path, line, message)
+			}
+			if node.Kind == KindStruct {
+				v.checkMapping(node.Children, &yaml.Node{Kind: yaml.MappingNode, Line: mapping.Line}, path)
+			}
+			continue
+		}
+
+		switch node.Kind {
+		case KindStruct:
+			if value.Kind == yaml.MappingNode {
+				v.checkMapping(node.Children, value, path)
+			}
+		case KindStructList:
+			if value.Kind != yaml.SequenceNode {
+				continue
+			}
+			for i, item := range value.Content {
+				if item.Kind == yaml.MappingNode {
+					v.checkMapping(node.Children, item, fmt.Sprintf("%s[%d]", path, i))
+				}
+			}
+		case KindScalar:
+			if value.Kind == yaml.ScalarNode {
+				v.checkScalar(node, value, path)
+			}
+		}
+	}
+}
+
+// Checks a scalar value against the enum and range constraints of its field.
+func (v *configValidator) checkScalar(node *Node, value *yaml.Node, path string) {
+	t := node.field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if len(node.Enum) > 0 && t.Kind() == reflect.String && !slices.Contains(node.Enum, value.Value) {
+		v.add(path, value.Line, "value %q is not one of: %s", value.Value, strings.Join(node.Enum, ", "))
+	}
+
+	minValue, maxValue := node.field.Tag.Get("min"), node.field.Tag.Get("max")
+	if (minValue == "" && maxValue == "") || !isNumericKind(t.Kind()) {
+		return
+	}
+	number, err := strconv.ParseFloat(value.Value, 64)
+	if err != nil {
+		v.add(path, value.Line, "value %q is not a number", value.Value)
+		return
+	}
+	if bound, err := strconv.ParseFloat(minValue, 64); err == nil && number < bound {
+		v.add(path, value.Line, "value %s is below the minimum of %s", value.Value, minValue)
+	}
+	if bound, err := strconv.ParseFloat(maxValue, 64); err == nil && number > bound {
+		v.add(path, value.Line, "value %s is above the maximum of %s", value.Value, maxValue)
+	}
+}
+
+// Reports the keys of a mapping that do not match any of the fields of a struct.
+func (v *configValidator) checkUnknownKeys(nodes []*Node, mapping *yaml.Node) {
+	known := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		known[node.Name] = true
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if key := mapping.Content[i]; !known[key.Value] {
+			v.add(key.Value, key.Line, "unknown key")
+		}
+	}
+}
+
+// Reports whether a YAML node is an explicit null value.
+func isYAMLNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+// Reports whether a kind is an integer or floating point number.
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,83 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lintConfig struct {
+	Name     string `yaml:"name" required:"true"`
+	LogLevel string `yaml:"log_level" enum:"debug,info,warn"`
+	Port     int    `yaml:"port" min:"1" max:"65535"`
+	Database struct {
+		DSN     string  `yaml:"dsn" required:"true"`
+		Ratio   float64 `yaml:"ratio" max:"1"`
+		MaxOpen int     `yaml:"max_open" min:"1"`
+	} `yaml:"database"`
+	Servers []struct {
+		Host string `yaml:"host" required:"true"`
+	} `yaml:"servers"`
+}
+
+// Writes a configuration file to a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Test validation of a configuration file against the tags of its struct.
+func TestValidateConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `name:
+log_level: trace
+port: 70000
+database:
+  ratio: 1.5
+  max_open: 0
+servers:
+  - host: a
+  - port: 80
+legacy: true
+`)
+
+	expected := []ValidationError{
+		{Field: "name", Message: "required field is null", Line: 1},
+		{Field: "log_level", Message: `value "trace" is not one of: debug, info, warn`, Line: 2},
+		{Field: "port", Message: "value 70000 is above the maximum of 65535", Line: 3},
+		{Field: "database.dsn", Message: "required field is missing", Line: 5},
+		{Field: "database.ratio", Message: "value 1.5 is above the maximum of 1", Line: 5},
+		{Field: "database.max_open", Message: "value 0 is below the minimum of 1", Line: 6},
+		{Field: "servers[1].host", Message: "required field is missing", Line: 9},
+	}
+	assert.Equal(t, expected, ValidateConfigFile(lintConfig{}, path))
+
+	errs := ValidateConfigFile(lintConfig{}, path, WithStrictKeys())
+	assert.Equal(t, ValidationError{Field: "legacy", Message: "unknown key", Line: 10}, errs[len(errs)-1])
+	assert.Equal(t, "line 10: legacy: unknown key", errs[len(errs)-1].Error())
+}
+
+// Test that a valid file has no errors, and that unreadable or invalid files are reported.
+func TestValidateConfigFile_Errors(t *testing.T) {
+	path := writeConfigFile(t, "name: app\nport: 8080\ndatabase:\n  dsn: postgres://localhost\n")
+	assert.Empty(t, ValidateConfigFile(lintConfig{}, path, WithStrictKeys()))
+
+	errs := ValidateConfigFile(lintConfig{}, writeConfigFile(t, ""))
+	assert.Equal(t, []ValidationError{
+		{Field: "name", Message: "required field is missing"},
+		{Field: "database.dsn", Message: "required field is missing"},
+	}, errs)
+
+	errs = ValidateConfigFile(lintConfig{}, filepath.Join(t.TempDir(), "missing.yaml"))
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Message, "failed to read config file")
+	}
+
+	errs = ValidateConfigFile(lintConfig{}, writeConfigFile(t, "- item\n"))
+	assert.Equal(t, []ValidationError{{Message: "YAML document is not a mapping", Line: 1}}, errs)
+}
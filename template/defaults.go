@@ -0,0 +1,277 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// BuildDefaultMap builds a nested map of the default (or placeholder) values of a configuration struct,
+// for callers that want to merge the defaults with other sources or marshal them with their own encoder.
+// Values are typed by the field kind: ints as int, unsigned ints as uint, floats as float64, bools as bool,
+// slices as []any and nested structs as map[string]any. Fields without a default are mapped to nil,
+// and maps to a map of their default entries, see parseMapDefault. Keys follow the options affecting them,
+// such as WithNamingStrategy, like in GenerateYAMLTemplate.
+func BuildDefaultMap(cfg interface{}, opts ...TemplateOption) map[string]any {
+	return buildDefaultMap(reflect.TypeOf(cfg), applyTemplateOptions(opts).keyNaming())
+}
+
+func buildDefaultMap(t reflect.Type, naming keyNaming) map[string]any {
+	result := make(map[string]any)
+	walkStruct(t, "", keyTags, naming, func(f structField) bool {
+		defaultValue := fieldDefault(f.StructField)
+
+		switch {
+		case isTextScalar(f.Type):
+			result[f.Key] = typedDefault(f.Type, defaultValue)
+
+		case f.Type.Kind() == reflect.Struct:
+			result[f.Key] = buildDefaultMap(f.Type, naming)
+
+		case isBytesType(f.Type):
+			result[f.Key] = nil
+			if defaultValue != "" {
+				result[f.Key] = bytesText(f.StructField, []byte(defaultValue))
+			}
+
+		case f.Type.Kind() == reflect.Slice:
+			items := []any{}
+			if defaultValue != "" && !isNestedStruct(f.Type.Elem()) {
+				for _, item := range strings.Split(defaultValue, ",") {
+					items = append(items, typedDefault(f.Type.Elem(), strings.TrimSpace(item)))
+				}
+			}
+			result[f.Key] = items
+
+		case f.Type.Kind() == reflect.Map:
+			entries := map[string]any{}
+			pairs, _ := parseMapDefault(defaultValue)
+			for _, pair := range pairs {
+				entries[pair.key] = typedDefault(f.Type.Elem(), pair.value)
+			}
+			result[f.Key] = entries
+
+		default:
+			result[f.Key] = typedDefault(f.Type, defaultValue)
+		}
+		return false
+	})
+	return result
+}
+
+// Returns the default value of a field from the `default` tag, falling back to the `placeholder` tag.
+func fieldDefault(field reflect.StructField) string {
+	if defaultValue := field.Tag.Get("default"); defaultValue != "" {
+		return defaultValue
+	}
+	return field.Tag.Get("placeholder")
+}
+
+// Returns the value rendered for a field in templates, with the precedence default > example > placeholder,
+// and whether it comes from the `example` tag. Unlike defaults, examples are illustrative values that the
+// application does not apply when the key is absent, so templates mark them as examples.
+func fieldTemplateValue(field reflect.StructField) (string, bool) {
+	if defaultValue := field.Tag.Get("default"); defaultValue != "" {
+		return defaultValue, false
+	}
+	if example := field.Tag.Get("example"); example != "" {
+		return example, true
+	}
+	return field.Tag.Get("placeholder"), false
+}
+
+// Converts a default value to a Go value typed by the kind of t.
+// Empty values are converted to nil, and values that cannot be parsed are kept as strings.
+func typedDefault(t reflect.Type, text string) any {
+	if text == "" {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType || isTextScalar(t) {
+		return text
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value, err := parseIntDefault(text, t.Bits()); err == nil {
+			return int(value)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value, err := parseUintDefault(text, t.Bits()); err == nil {
+			return uint(value)
+		}
+	case reflect.Float32, reflect.Float64:
+		if value, err := strconv.ParseFloat(text, t.Bits()); err == nil {
+			return value
+		}
+	case reflect.Bool:
+		if value, err := parseBoolDefault(text); err == nil {
+			return value
+		}
+	}
+	return text
+}
+
+// Parses the default value of a bool field. Besides true and false, the spellings of YAML 1.1 and of
+// strconv.ParseBool are recognized case-insensitively: yes/no, y/n, on/off, t/f and 1/0.
+// YAML 1.2 decoders read most of them as strings, so templates render them as true or false.
+func parseBoolDefault(text string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "true", "yes", "y", "on", "t", "1":
+		return true, nil
+	case "false", "no", "n", "off", "f", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", text)
+}
+
+// Normalizes the default value of a bool or numeric field to its canonical form, so that YAML decoders
+// read it as a value of the field's kind: bools as true or false (see parseBoolDefault), and numbers
+// in base 10 without leading zeros. Numbers must fit the bit size of the field, and unsigned numbers must not
+// be negative. Durations, other kinds and empty values are returned as they are.
+func normalizeDefault(t reflect.Type, text string) (string, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if text == "" || t == durationType {
+		return text, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		value, err := parseBoolDefault(text)
+		if err != nil {
+			return text, err
+		}
+		return strconv.FormatBool(value), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := parseIntDefault(text, t.Bits())
+		if err != nil {
+			return text, numberError(t, text, err)
+		}
+		return strconv.FormatInt(value, 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := parseUintDefault(text, t.Bits())
+		if err != nil {
+			return text, numberError(t, text, err)
+		}
+		return strconv.FormatUint(value, 10), nil
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return text, numberError(t, text, err)
+		}
+		return strconv.FormatFloat(value, 'g', -1, t.Bits()), nil
+	}
+	return text, nil
+}
+
+// Parses the default value of an integer field in base 10, or in base 16 with a 0x prefix as YAML reads it.
+// Other prefixes are not recognized, so that e.g. 0644 is not read as an octal number.
+func parseIntDefault(text string, bits int) (int64, error) {
+	if isHexLiteral(text) {
+		return strconv.ParseInt(text, 0, bits)
+	}
+	return strconv.ParseInt(text, 10, bits)
+}
+
+// Parses the default value of an unsigned integer field like parseIntDefault.
+func parseUintDefault(text string, bits int) (uint64, error) {
+	if isHexLiteral(text) {
+		return strconv.ParseUint(text, 0, bits)
+	}
+	return strconv.ParseUint(text, 10, bits)
+}
+
+// Reports whether a number is written in hexadecimal with a 0x prefix, optionally signed.
+func isHexLiteral(text string) bool {
+	digits := strings.TrimLeft(text, "+-")
+	return strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X")
+}
+
+// Returns the error of a number that cannot be parsed into a value of type t, without the name of the parse function.
+func numberError(t reflect.Type, text string, err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+	return fmt.Errorf("invalid %s %q: %w", t.Kind(), text, err)
+}
+
+// keyValue is an entry of the default value of a map field.
+type keyValue struct {
+	key   string
+	value string
+}
+
+// Parses the default value of a map field, a comma-separated list of key=value pairs such as "env=prod,team=core".
+// Keys and values may be quoted with single or double quotes to contain commas, equal signs or surrounding spaces,
+// e.g. "greeting='hello, world'". Unquoted keys and values are trimmed. An empty default has no entries.
+func parseMapDefault(text string) ([]keyValue, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	entries, err := splitUnquoted(text, ',')
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]keyValue, 0, len(entries))
+	for _, entry := range entries {
+		parts, err := splitUnquoted(entry, '=')
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("missing \"=\" in map entry %q", strings.TrimSpace(entry))
+		}
+		key := unquoteDefault(parts[0])
+		if key == "" {
+			return nil, fmt.Errorf("empty key in map entry %q", strings.TrimSpace(entry))
+		}
+		// Only the first unquoted equal sign separates the key from the value
+		value := unquoteDefault(strings.Join(parts[1:], "="))
+		pairs = append(pairs, keyValue{key: key, value: value})
+	}
+	return pairs, nil
+}
+
+// Splits a text on the separators outside of single or double quotes, keeping the quotes.
+func splitUnquoted(text string, sep rune) ([]string, error) {
+	var parts []string
+	var quote rune
+	start := 0
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == sep:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in map default " + strconv.Quote(text))
+	}
+	return append(parts, text[start:]), nil
+}
+
+// Trims a key or value of a map default and removes its quotes, if any.
+func unquoteDefault(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
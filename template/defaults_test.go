@@ -0,0 +1,77 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test building a typed map of default values from a nested struct.
+func TestBuildDefaultMap(t *testing.T) {
+	type Item struct {
+		Name string `yaml:"name" default:"item"`
+	}
+	cfg := struct {
+		Host    string            `yaml:"host" default:"localhost"`
+		Port    int               `yaml:"port" default:"8080"`
+		Workers uint              `yaml:"workers" default:"4"`
+		Ratio   float64           `yaml:"ratio" default:"0.5"`
+		Enabled bool              `yaml:"enabled" default:"true"`
+		Timeout time.Duration     `yaml:"timeout" default:"5s"`
+		User    string            `yaml:"user" placeholder:"admin"`
+		Token   string            `yaml:"token"`
+		Ports   []int             `yaml:"ports" default:"80, 443"`
+		Items   []Item            `yaml:"items"`
+		Labels  map[string]string `yaml:"labels"`
+		Weights map[string]int    `yaml:"weights" default:"a=1, b=2"`
+		Meta    struct {
+			Version string `yaml:"version" default:"1.0"`
+			Build   int    `yaml:"build" default:"42"`
+		} `yaml:"meta"`
+		Hidden string `yaml:"-" default:"hidden"`
+	}{}
+	defaults := BuildDefaultMap(cfg)
+
+	expected := map[string]any{
+		"host":    "localhost",
+		"port":    8080,
+		"workers": uint(4),
+		"ratio":   0.5,
+		"enabled": true,
+		"timeout": "5s",
+		"user":    "admin",
+		"token":   nil,
+		"ports":   []any{80, 443},
+		"items":   []any{},
+		"labels":  map[string]any{},
+		"weights": map[string]any{"a": 1, "b": 2},
+		"meta": map[string]any{
+			"version": "1.0",
+			"build":   42,
+		},
+	}
+
+	assert.Equal(t, expected, defaults)
+}
+
+// Test parsing of map defaults with quoted keys and values.
+func TestParseMapDefault(t *testing.T) {
+	pairs, err := parseMapDefault(` env = prod ,greeting="hello, world",'a=b'=c=d,empty=`)
+	assert.NoError(t, err)
+	assert.Equal(t, []keyValue{
+		{key: "env", value: "prod"},
+		{key: "greeting", value: "hello, world"},
+		{key: "a=b", value: "c=d"},
+		{key: "empty", value: ""},
+	}, pairs)
+
+	pairs, err = parseMapDefault("")
+	assert.NoError(t, err)
+	assert.Empty(t, pairs)
+
+	_, err = parseMapDefault("a='b")
+	assert.ErrorContains(t, err, "unterminated quote")
+	_, err = parseMapDefault("=b")
+	assert.ErrorContains(t, err, "empty key")
+}
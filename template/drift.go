@@ -0,0 +1,298 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Report describes the differences between a configuration file and its configuration struct.
+type Report struct {
+	// Missing lists the fields of the struct that are not set in the file.
+	Missing []MissingKey
+	// Unknown lists the dotted paths of the keys of the file that do not match any field of the struct.
+	Unknown []string
+	// Mismatches lists the values of the file whose type does not match the type of their field.
+	Mismatches []TypeMismatch
+	// Deprecated lists the keys of the file whose field is tagged as deprecated.
+	Deprecated []DeprecatedKey
+	// SchemaVersion is set when the file records a schema version other than the one of WithSchemaVersion.
+	SchemaVersion *SchemaVersionError
+}
+
+// MissingKey is a field of the struct that is not set in the file.
+type MissingKey struct {
+	// Path is the dotted path of the field; elements of slices of structs are indexed, e.g. servers[0].name.
+	Path string
+	// Default is the default value the field falls back to, from the `default` tag.
+	Default string
+}
+
+// TypeMismatch is a value of the file whose type does not match the type of its field.
+type TypeMismatch struct {
+	// Path is the dotted path of the value.
+	Path string
+	// Expected is the Go type of the field.
+	Expected string
+	// Got is the YAML type of the value, e.g. "string", "int" or "map".
+	Got string
+}
+
+// DeprecatedKey is a key of the file whose field is tagged as deprecated.
+type DeprecatedKey struct {
+	// Path is the dotted path of the key.
+	Path string
+	// Message is the value of the `deprecated` tag, e.g. "use server.listen instead".
+	Message string
+}
+
+// HasDrift reports whether the report lists any difference.
+func (r Report) HasDrift() bool {
+	return len(r.Missing) > 0 || len(r.Unknown) > 0 || len(r.Mismatches) > 0 || len(r.Deprecated) > 0 ||
+		r.SchemaVersion != nil
+}
+
+// CompareYAMLWithStruct compares a YAML configuration file with its configuration struct and reports
+// the keys missing from the file, the keys of the file unknown to the struct, and the values whose type
+// does not match their field. Keys are resolved with the same tag rules as GenerateYAMLTemplate.
+// Every element of a slice of structs is compared with the struct, and the keys of maps are free-form.
+// Null values match any field. Deprecated keys set in the file are reported, while deprecated keys absent
+// from the file are not reported as missing. With WithSchemaVersion, a file recording another schema version,
+// or none, is reported as well; the `schema_version` key of WithSchemaVersionKey is never reported as unknown.
+// The options affecting the keys, such as WithNamingStrategy, apply as in GenerateYAMLTemplate.
+// An error is returned if the file is not a valid YAML mapping or if the struct is invalid, as reported by ParseConfigTree.
+func CompareYAMLWithStruct(yamlBytes []byte, cfg interface{}, opts ...TemplateOption) (Report, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(yamlBytes, &document); err != nil {
+		return Report{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	root, err := ParseConfigTree(cfg, opts...)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	if document == nil {
+		document = map[string]interface{}{}
+	}
+	values, ok := yamlMapping(document)
+	if !ok {
+		return Report{}, fmt.Errorf("YAML document is a %s, not a mapping", yamlTypeName(document))
+	}
+	if !isSchemaVersionField(root.Children) {
+		delete(values, schemaVersionKey)
+	}
+	compareMapping(&report, root.Children, values, "")
+	report.SchemaVersion = checkSchemaVersion(yamlBytes, applyTemplateOptions(opts))
+
+	sort.Strings(report.Unknown)
+	return report, nil
+}
+
+// Compares the values of a mapping with the fields of a struct.
+func compareMapping(report *Report, nodes []*Node, values map[string]interface{}, parent string) {
+	known := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		known[node.Name] = true
+		path := joinPath(parent, node.Name)
+
+		value, ok := values[node.Name]
+		if !ok {
+			if node.Deprecated == "" {
+				report.Missing = append(report.Missing, MissingKey{Path: path, Default: node.Default})
+			}
+			continue
+		}
+		if node.Deprecated != "" {
+			report.Deprecated = append(report.Deprecated, DeprecatedKey{Path: path, Message: node.Deprecated})
+		}
+		compareValue(report, node, value, path)
+	}
+
+	for key := range values {
+		if !known[key] {
+			report.Unknown = append(report.Unknown, joinPath(parent, key))
+		}
+	}
+}
+
+// Compares a value of the file with the field of a node.
+func compareValue(report *Report, node *Node, value interface{}, path string) {
+	if value == nil {
+		return
+	}
+	mismatch := func() {
+		report.Mismatches = append(report.Mismatches, TypeMismatch{
+			Path:     path,
+			Expected: node.field.Type.String(),
+			Got:      yamlTypeName(value),
+		})
+	}
+
+	switch node.Kind {
+	case KindStruct:
+		values, ok := yamlMapping(value)
+		if !ok {
+			mismatch()
+			return
+		}
+		compareMapping(report, node.Children, values, path)
+
+	case KindStructList:
+		items, ok := value.([]interface{})
+		if !ok {
+			mismatch()
+			return
+		}
+		for i, item := range items {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			values, ok := yamlMapping(item)
+			if !ok {
+				if item != nil {
+					report.Mismatches = append(report.Mismatches, TypeMismatch{
+						Path:     itemPath,
+						Expected: node.field.Type.Elem().String(),
+						Got:      yamlTypeName(item),
+					})
+				}
+				continue
+			}
+			compareMapping(report, node.Children, values, itemPath)
+		}
+
+	case KindList:
+		items, ok := value.([]interface{})
+		if !ok {
+			mismatch()
+			return
+		}
+		elem := node.field.Type.Elem()
+		for i, item := range items {
+			if !yamlScalarMatches(elem, item) {
+				report.Mismatches = append(report.Mismatches, TypeMismatch{
+					Path:     fmt.Sprintf("%s[%d]", path, i),
+					Expected: elem.String(),
+					Got:      yamlTypeName(item),
+				})
+			}
+		}
+
+	case KindMap:
+		if _, ok := yamlMapping(value); !ok {
+			mismatch()
+		}
+
+	default:
+		if !yamlScalarMatches(node.field.Type, value) {
+			mismatch()
+		}
+	}
+}
+
+// Reports whether a decoded YAML value can be decoded into a scalar field of the given type.
+func yamlScalarMatches(t reflect.Type, value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := lookupRenderer(t); ok || t.Kind() == reflect.Interface {
+		return true
+	}
+
+	_, isMap := yamlMapping(value)
+	_, isList := value.([]interface{})
+	isScalar := !isMap && !isList
+	if isTextScalar(t) || t.Kind() == reflect.String {
+		return isScalar
+	}
+	// Byte slices are written as strings, but the YAML decoder also reads them from lists of numbers
+	if isBytesType(t) {
+		return isScalar || isList
+	}
+	if t == durationType {
+		switch value.(type) {
+		case string, int:
+			return true
+		}
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, ok := value.(int)
+		return ok
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := value.(int)
+		return ok && number >= 0
+	case reflect.Float32, reflect.Float64:
+		switch value.(type) {
+		case int, float64:
+			return true
+		}
+		return false
+	case reflect.Bool:
+		_, ok := value.(bool)
+		return ok
+	case reflect.Slice, reflect.Array:
+		return isList
+	case reflect.Struct, reflect.Map:
+		return isMap
+	default:
+		return true
+	}
+}
+
+// Returns a decoded YAML mapping with its keys converted to strings.
+func yamlMapping(value interface{}) (map[string]interface{}, bool) {
+	switch mapping := value.(type) {
+	case map[string]interface{}:
+		return mapping, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(mapping))
+		for key, item := range mapping {
+			converted[fmt.Sprint(key)] = item
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
+
+// Returns the YAML type name of a decoded value.
+func yamlTypeName(value interface{}) string {
+	if _, ok := yamlMapping(value); ok {
+		return "map"
+	}
+	switch value.(type) {
+	case nil:
+		return "null"
+	case []interface{}:
+		return "list"
+	case string:
+		return "string"
+	case int, int64, uint64:
+		return "int"
+	case float64:
+		return "float"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// Joins a parent path and a key with a dot.
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,119 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type driftServer struct {
+	Name   string  `yaml:"name"`
+	Weight float64 `yaml:"weight" default:"1"`
+}
+
+// Test drift between a YAML file and its configuration struct.
+func TestCompareYAMLWithStruct(t *testing.T) {
+	cfg := struct {
+		Host     string        `yaml:"host" default:"localhost"`
+		Port     int           `yaml:"port" default:"8080"`
+		Timeout  time.Duration `yaml:"timeout" default:"5s"`
+		Debug    bool          `yaml:"debug"`
+		Database struct {
+			DSN     string `yaml:"dsn"`
+			MaxOpen int    `yaml:"max_open" default:"10"`
+		} `yaml:"database"`
+		Servers []driftServer     `yaml:"servers"`
+		Ports   []uint            `yaml:"ports"`
+		Labels  map[string]string `yaml:"labels"`
+	}{}
+
+	input := `
+host: example.com
+port: "eighty"
+timeout: 10s
+database:
+  dsn: postgres://localhost
+  pool: 5
+servers:
+  - name: a
+    weight: 2
+  - name: b
+    zone: eu
+  - plain
+ports: [80, -1]
+labels:
+  team: core
+  anything: goes
+legacy: true
+`
+	report, err := CompareYAMLWithStruct([]byte(input), cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, []MissingKey{
+		{Path: "debug"},
+		{Path: "database.max_open", Default: "10"},
+		{Path: "servers[1].weight", Default: "1"},
+	}, report.Missing)
+	assert.Equal(t, []string{"database.pool", "legacy", "servers[1].zone"}, report.Unknown)
+	assert.Equal(t, []TypeMismatch{
+		{Path: "port", Expected: "int", Got: "string"},
+		{Path: "servers[2]", Expected: "template.driftServer", Got: "string"},
+		{Path: "ports[1]", Expected: "uint", Got: "int"},
+	}, report.Mismatches)
+	assert.True(t, report.HasDrift())
+}
+
+// Test that a file matching the struct reports no drift, and that invalid files are rejected.
+func TestCompareYAMLWithStruct_NoDrift(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}{}
+
+	report, err := CompareYAMLWithStruct([]byte("host: localhost\nport: 80\n"), cfg)
+	require.NoError(t, err)
+	assert.False(t, report.HasDrift())
+
+	_, err = CompareYAMLWithStruct([]byte("- host\n"), cfg)
+	assert.ErrorContains(t, err, "not a mapping")
+
+	_, err = CompareYAMLWithStruct([]byte("host: [\n"), cfg)
+	assert.ErrorContains(t, err, "failed to parse YAML")
+}
+
+// Test that deprecated keys are reported when set, and never reported as missing.
+func TestCompareYAMLWithStruct_Deprecated(t *testing.T) {
+	cfg := struct {
+		Listen string `yaml:"listen"`
+		Port   int    `yaml:"port" deprecated:"use listen instead"`
+		Legacy string `yaml:"legacy" deprecated:"no longer used"`
+	}{}
+
+	report, err := CompareYAMLWithStruct([]byte("listen: \":80\"\nport: 80\n"), cfg)
+	require.NoError(t, err)
+	assert.Empty(t, report.Missing)
+	assert.Equal(t, []DeprecatedKey{{Path: "port", Message: "use listen instead"}}, report.Deprecated)
+	assert.True(t, report.HasDrift())
+}
+
+// Test reporting a file recording another schema version as drift.
+func TestCompareYAMLWithStruct_SchemaVersion(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host"`
+	}{}
+
+	report, err := CompareYAMLWithStruct([]byte("# kongkit-schema: v2\nhost: a\n"), cfg, WithSchemaVersion("v3"))
+	require.NoError(t, err)
+	assert.Equal(t, &SchemaVersionError{File: "v2", Current: "v3"}, report.SchemaVersion)
+	assert.True(t, report.HasDrift())
+
+	report, err = CompareYAMLWithStruct([]byte("schema_version: v3\nhost: a\n"), cfg, WithSchemaVersion("v3"))
+	require.NoError(t, err)
+	assert.False(t, report.HasDrift(), "The version key should not be reported as unknown")
+
+	report, err = CompareYAMLWithStruct([]byte("host: a\n"), cfg)
+	require.NoError(t, err)
+	assert.False(t, report.HasDrift(), "Versions should only be compared with WithSchemaVersion")
+}
@@ -0,0 +1,179 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GenerateEnvTemplate generates a .env template from a given configuration struct.
+// Every field with an `env` tag is rendered as a `NAME=default` line preceded by its help text
+// as a `# comment` line. The `envprefix` tags of enclosing structs are prepended to the names, as Kong does.
+// Required variables are marked in the comment, slice defaults are joined with commas, and fields tagged
+// with `secret:"true"` are rendered with an empty value so that no secret ends up in the template.
+// Fields without an `env` tag are skipped, unless WithEnvNamesFromPath is used.
+// Use WithInlineEnvComments to write the help text at the end of the lines instead.
+func GenerateEnvTemplate(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+
+	var builder strings.Builder
+	collectEnvVariables(reflect.TypeOf(cfg), "", "", options.envNamesFromPath, options.keyNaming(), func(variable envVariable) {
+		value := variable.value
+		if variable.secret {
+			value = ""
+		}
+		line := fmt.Sprintf("%s=%s", variable.name, envValue(value))
+		switch {
+		case variable.help == "":
+		case options.inlineEnvComments:
+			line += " # " + variable.help
+		default:
+			builder.WriteString("# " + variable.help + "\n")
+		}
+		builder.WriteString(line + "\n")
+	})
+	return builder.String()
+}
+
+// GenerateDockerComposeEnv generates the entries of the `environment:` section of a docker-compose.yml service
+// from a given configuration struct, as `  - NAME=default` lines preceded by their help text as `  # comment` lines.
+// Every field is rendered, with the name of its `env` tag or, without one, the name derived from its key path
+// as with WithEnvNamesFromPath, e.g. SERVER_HTTP_PORT. The prefix is prepended to all names, separated by an
+// underscore unless it already ends with one. Fields tagged with `secret:"true"` reference the variable of the
+// same name on the host, e.g. `  - APP_DB_PASSWORD=${APP_DB_PASSWORD}`, so that no secret ends up in the file.
+// Values and help texts are those of the other templates: defaults are normalized, e.g. 1e3 to 1000 for a float,
+// and the unit and range of a field follow its help text. Dollar signs of defaults are escaped from the
+// interpolation of Compose. The options affecting the keys, such as
+// WithNamingStrategy, apply to the derived names.
+func GenerateDockerComposeEnv(cfg interface{}, prefix string, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var builder strings.Builder
+	collectEnvVariables(reflect.TypeOf(cfg), prefix, prefix, true, options.keyNaming(), func(variable envVariable) {
+		value := strings.ReplaceAll(normalizeEnvValue(variable.field, variable.value), "$", "$$")
+		if variable.secret {
+			value = "${" + variable.name + "}"
+		}
+		help := joinComment(deprecationComment(variable.field), fieldComment(variable.field))
+		if isKongRequired(variable.field) {
+			help = joinComment(help, "(required)")
+		}
+		if help != "" {
+			builder.WriteString("  # " + help + "\n")
+		}
+		builder.WriteString("  - " + composeEntry(variable.name+"="+value) + "\n")
+	})
+	return builder.String()
+}
+
+// envVariable is an environment variable of a configuration field.
+type envVariable struct {
+	name string
+	// value is the default value, with the items of slices joined with commas.
+	value string
+	// help is the help text, marked for required variables.
+	help   string
+	secret bool
+	field  reflect.StructField
+}
+
+// Calls fn for the variables of the fields of a struct. The prefix applies to the names taken from `env` tags,
+// and the path prefix to the names derived from the key path, for fields without an `env` tag if namesFromPath is set.
+func collectEnvVariables(t reflect.Type, prefix, pathPrefix string, namesFromPath bool, naming keyNaming, fn func(variable envVariable)) {
+	walkStruct(t, "", keyTags, naming, func(f structField) bool {
+		if isNestedStruct(f.Type) {
+			nestedPathPrefix := pathPrefix + screamingSnakeCase(f.Key) + "_"
+			if envPrefix := f.Tag.Get("envprefix"); envPrefix != "" {
+				nestedPathPrefix = pathPrefix + envPrefix
+			}
+			collectEnvVariables(f.Type, prefix+f.Tag.Get("envprefix"), nestedPathPrefix, namesFromPath, naming, fn)
+			return false
+		}
+		// Slices of structs cannot be set from a single variable
+		if f.Type.Kind() == reflect.Slice && isNestedStruct(f.Type.Elem()) {
+			return false
+		}
+
+		name := envName(f.StructField)
+		switch {
+		case name != "":
+			name = prefix + name
+		case namesFromPath:
+			name = pathPrefix + screamingSnakeCase(f.Key)
+		default:
+			return false
+		}
+
+		value := fieldDefault(f.StructField)
+		if f.Type.Kind() == reflect.Slice && value != "" {
+			items := strings.Split(value, ",")
+			for i, item := range items {
+				items[i] = strings.TrimSpace(item)
+			}
+			value = strings.Join(items, ",")
+		}
+
+		help := f.Tag.Get("help")
+		if isKongRequired(f.StructField) {
+			help = joinComment(help, "(required)")
+		}
+		fn(envVariable{name: name, value: value, help: help, secret: f.Tag.Get("secret") == "true", field: f.StructField})
+		return false
+	})
+}
+
+// Returns the value of a variable in the canonical form of the templates, see normalizeDefault: bools and numbers
+// of the `default` tag are normalized, item by item for slices. Numbers stay decimal whatever their format, as
+// variables are not read by YAML decoders. Placeholders and values that cannot be parsed are returned as they are.
+func normalizeEnvValue(field reflect.StructField, value string) string {
+	if field.Tag.Get("default") == "" || value == "" {
+		return value
+	}
+	normalize := func(t reflect.Type, text string) string {
+		normalized, _ := normalizeDefault(t, text)
+		return normalized
+	}
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice || isTextScalar(t) || isBytesType(t) {
+		return normalize(t, value)
+	}
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = normalize(t.Elem(), item)
+	}
+	return strings.Join(items, ",")
+}
+
+// Returns the first variable name of the `env` tag of a field, which may list several names.
+func envName(field reflect.StructField) string {
+	return strings.TrimSpace(strings.Split(field.Tag.Get("env"), ",")[0])
+}
+
+// Converts a key to SCREAMING_SNAKE_CASE, e.g. http_port or httpPort to HTTP_PORT.
+func screamingSnakeCase(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(splitWords(key, "_"), "-", "_"))
+}
+
+// Returns a value as written in a .env file, quoting values that contain spaces, quotes or comment markers.
+func envValue(value string) string {
+	if strings.ContainsAny(value, " \t\"'#") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// Returns an entry of a Compose environment list, quoted when YAML would not read it as a plain string,
+// i.e. when it contains a mapping separator or a comment marker, or ends with a space or a colon.
+func composeEntry(entry string) string {
+	if strings.Contains(entry, ": ") || strings.Contains(entry, " #") || strings.ContainsAny(entry, "\t") ||
+		strings.HasSuffix(entry, " ") || strings.HasSuffix(entry, ":") {
+		return yamlLiteral(scalar{text: entry, quoted: true})
+	}
+	return entry
+}
@@ -0,0 +1,128 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type envConfig struct {
+	Port     int      `yaml:"port" env:"PORT" default:"8080" help:"The port number"`
+	Host     string   `yaml:"host" env:"HOST,HOSTNAME" default:"local host"`
+	Tags     []string `yaml:"tags" env:"TAGS" default:"a, b"`
+	LogLevel string   `yaml:"log_level" default:"info"`
+	Database struct {
+		DSN      string `yaml:"dsn" env:"DSN" required:"" help:"Connection string"`
+		Password string `yaml:"password" env:"PASSWORD" default:"changeme" secret:"true"`
+		PoolSize int    `yaml:"pool_size" default:"10"`
+	} `yaml:"database" envprefix:"DB_"`
+	Cache struct {
+		TTL string `yaml:"ttl" env:"CACHE_TTL" default:"1m"`
+	} `yaml:"cache"`
+}
+
+// Test .env generation from env tags.
+func TestGenerateEnvTemplate(t *testing.T) {
+	expected := `# The port number
+PORT=8080
+HOST="local host"
+TAGS=a,b
+# Connection string (required)
+DB_DSN=
+DB_PASSWORD=
+CACHE_TTL=1m
+`
+
+	assert.Equal(t, expected, GenerateEnvTemplate(envConfig{}))
+}
+
+// Test .env generation with names derived from the key path for fields without env tags.
+func TestGenerateEnvTemplate_NamesFromPath(t *testing.T) {
+	expected := `# The port number
+PORT=8080
+HOST="local host"
+TAGS=a,b
+LOG_LEVEL=info
+# Connection string (required)
+DB_DSN=
+DB_PASSWORD=
+DB_POOL_SIZE=10
+CACHE_TTL=1m
+`
+
+	assert.Equal(t, expected, GenerateEnvTemplate(envConfig{}, WithEnvNamesFromPath()))
+}
+
+// Test .env generation with the help text at the end of the variable lines.
+func TestGenerateEnvTemplate_InlineComments(t *testing.T) {
+	cfg := struct {
+		App struct {
+			Port   int `yaml:"port" env:"PORT" default:"8080" help:"The port number"`
+			Server struct {
+				Host string `yaml:"host" env:"HOST" default:"localhost" help:"The hostname"`
+				TLS  bool   `yaml:"tls" env:"TLS"`
+			} `yaml:"server" envprefix:"SERVER_"`
+		} `yaml:"app" envprefix:"APP_"`
+	}{}
+	expected := `APP_PORT=8080 # The port number
+APP_SERVER_HOST=localhost # The hostname
+APP_SERVER_TLS=
+`
+
+	assert.Equal(t, expected, GenerateEnvTemplate(cfg, WithInlineEnvComments()))
+}
+
+// Test generation of the environment section of a docker-compose.yml service.
+func TestGenerateDockerComposeEnv(t *testing.T) {
+	expected := `  # The port number
+  - APP_PORT=8080
+  - APP_HOST=local host
+  - APP_TAGS=a,b
+  - APP_LOG_LEVEL=info
+  # Connection string (required)
+  - APP_DB_DSN=
+  - APP_DB_PASSWORD=${APP_DB_PASSWORD}
+  - APP_DB_POOL_SIZE=10
+  - APP_CACHE_TTL=1m
+`
+	assert.Equal(t, expected, GenerateDockerComposeEnv(envConfig{}, "APP"))
+
+	cfg := struct {
+		Greeting string `yaml:"greeting" default:"hello: world"`
+		Price    string `yaml:"price" default:"$5"`
+	}{}
+	expected = `  - "GREETING=hello: world"
+  - PRICE=$$5
+`
+	assert.Equal(t, expected, GenerateDockerComposeEnv(cfg, ""))
+
+	var decoded []string
+	require.NoError(t, yaml.Unmarshal([]byte(expected), &decoded))
+	assert.Equal(t, []string{"GREETING=hello: world", "PRICE=$$5"}, decoded)
+}
+
+// Test that the Docker Compose environment has the normalized values and the help texts of the other templates.
+func TestGenerateDockerComposeEnv_Normalized(t *testing.T) {
+	cfg := struct {
+		Ratio   float64 `yaml:"ratio" default:"1e3" help:"Sampling ratio"`
+		Debug   bool    `yaml:"debug" default:"yes"`
+		Ports   []int   `yaml:"ports" default:"080, 0x1F90"`
+		Port    int     `yaml:"port" default:"8080" min:"1" max:"65535" help:"The port number"`
+		Timeout int     `yaml:"timeout" default:"30" unit:"seconds" deprecated:"use deadline instead"`
+		Host    string  `yaml:"host" placeholder:"1e3"`
+	}{}
+	expected := `  # Sampling ratio
+  - RATIO=1000
+  - DEBUG=true
+  - PORTS=80,8080
+  # The port number; range: 1-65535
+  - PORT=8080
+  # DEPRECATED: use deadline instead. (seconds)
+  - TIMEOUT=30
+  - HOST=1e3
+`
+	assert.Equal(t, expected, GenerateDockerComposeEnv(cfg, ""))
+	assert.Contains(t, GenerateYAMLTemplate(cfg), "ratio: 1000", "The values should match the YAML template")
+}
@@ -0,0 +1,78 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GenerateYAMLLines returns the lines of the YAML template generated by GenerateYAMLTemplate, before their
+// comments are aligned, for tools that inspect the template, e.g. ValidateFieldInfoAgainstValues.
+func GenerateYAMLLines(cfg interface{}, opts ...TemplateOption) []FieldInfo {
+	options := applyTemplateOptions(opts)
+	nodes, _ := buildConfigTree(cfg, options)
+
+	w := &yamlWriter{
+		options:   options,
+		maxLength: make(map[alignGroup]int),
+	}
+	w.writeNodes(wrapRootKey(nodes, options.rootKey), 0, "")
+	return w.lines
+}
+
+// ValidateFieldInfoAgainstValues checks the lines of a template, as returned by GenerateYAMLLines, against an
+// instance of the configuration struct, e.g. the one returned by its constructor, and returns a warning for:
+//   - a field whose `default` tag does not match its value in the instance, which indicates that the tag
+//     and the code setting the defaults are inconsistent;
+//   - a required field with a zero value in the instance, which may fail at runtime;
+//   - a field of the struct without a line in the template, e.g. hidden by WithSkipOmitempty.
+//
+// The fields of the elements of slices of structs are not checked against values.
+// It is a developer tool for keeping the defaults of a struct consistent with its documentation.
+func ValidateFieldInfoAgainstValues(lines []FieldInfo, instance interface{}) []string {
+	root, err := ParseConfigTree(instance)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid configuration struct: %v", err)}
+	}
+
+	documented := make(map[string]FieldInfo, len(lines))
+	for _, line := range lines {
+		if _, ok := documented[line.Path]; line.Path != "" && !ok {
+			documented[line.Path] = line
+		}
+	}
+
+	var warnings []string
+	checkFieldInfo(root.Children, documented, &warnings)
+	return warnings
+}
+
+// Checks the nodes of a struct against the documented lines and the values of their fields.
+func checkFieldInfo(nodes []*Node, documented map[string]FieldInfo, warnings *[]string) {
+	for _, node := range nodes {
+		line, ok := documented[node.Path]
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("field %s has no line in the template", node.Path))
+		}
+
+		v := node.fieldValue
+		if node.Kind == KindStruct {
+			checkFieldInfo(node.Children, documented, warnings)
+			continue
+		}
+		if !v.IsValid() {
+			continue
+		}
+
+		if node.Required && v.IsZero() {
+			*warnings = append(*warnings, fmt.Sprintf("field %s is required but has a zero value", node.Path))
+		}
+		if ok && line.Default != "" && node.Kind != KindStructList {
+			expected, err := parseDefault(v.Type(), line.Default)
+			if err != nil {
+				*warnings = append(*warnings, fmt.Sprintf("field %s has an invalid default %q: %v", node.Path, line.Default, err))
+			} else if !reflect.DeepEqual(v.Interface(), expected.Interface()) {
+				*warnings = append(*warnings, fmt.Sprintf("field %s has the default %q, but the value %v", node.Path, line.Default, describeValue(v)))
+			}
+		}
+	}
+}
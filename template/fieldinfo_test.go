@@ -0,0 +1,34 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldInfoConfig struct {
+	Name    string        `yaml:"name" required:"true"`
+	Port    int           `yaml:"port" default:"8080"`
+	Timeout time.Duration `yaml:"timeout" default:"5s"`
+	Tags    []string      `yaml:"tags,omitempty" default:"a,b"`
+	Log     struct {
+		Level string `yaml:"level" default:"info"`
+	} `yaml:"log"`
+}
+
+// Test checking the lines of a template against the values of a configuration instance.
+func TestValidateFieldInfoAgainstValues(t *testing.T) {
+	instance := fieldInfoConfig{Name: "app", Port: 8080, Timeout: 5 * time.Second, Tags: []string{"a", "b"}}
+	instance.Log.Level = "info"
+	assert.Empty(t, ValidateFieldInfoAgainstValues(GenerateYAMLLines(fieldInfoConfig{}), instance))
+
+	instance = fieldInfoConfig{Port: 9090, Timeout: 5 * time.Second}
+	instance.Log.Level = "info"
+	warnings := ValidateFieldInfoAgainstValues(GenerateYAMLLines(fieldInfoConfig{}, WithSkipOmitempty()), instance)
+	assert.Equal(t, []string{
+		"field name is required but has a zero value",
+		`field port has the default "8080", but the value 9090`,
+		"field tags has no line in the template",
+	}, warnings)
+}
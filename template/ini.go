@@ -0,0 +1,85 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateINITemplate generates an INI/properties template from a given configuration struct.
+// Top-level fields are rendered as `key=value` lines, nested structs become `[section]` headers,
+// and help text is rendered as `; comment` lines above each key. Embedded and inlined structs are merged
+// into their parent, and the fields of a group follow each other under a `; --- group ---` header.
+// Defaults, ordering and deprecated fields follow the same rules as GenerateYAMLTemplate; values are written
+// unquoted, and keys without a value are left empty. INI has no lists or maps, so those are only described
+// in a comment.
+// Key names are taken from the `ini` tag, falling back to `yaml`, `json`, `kong` and the field name.
+func GenerateINITemplate(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+	nodes, _ := buildConfigTreeWithKeys(cfg, options, append([]string{"ini"}, keyTags...))
+
+	var builder strings.Builder
+	writeINISection(&builder, nodes, "", options)
+	return builder.String()
+}
+
+// Writes the keys of a section followed by its nested sections.
+// Nested sections are written after all plain keys, so that no key ends up in the wrong section.
+func writeINISection(builder *strings.Builder, nodes []*Node, section string, options *Options) {
+	previousGroup := ""
+	for _, node := range nodes {
+		if node.Kind == KindStruct {
+			continue
+		}
+
+		// The fields of a group follow each other, under a header separated by a blank line
+		if node.Group != previousGroup {
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			if node.Group != "" {
+				writeINIComment(builder, fmt.Sprintf("--- %s ---", node.Group))
+			}
+			previousGroup = node.Group
+		}
+		writeINIComment(builder, node.comment)
+
+		// Values from the example tag and deprecated fields are commented out if configured
+		prefix := ""
+		if (options.commentedExamples && node.fromExample) || (options.commentedDeprecated && node.Deprecated != "") {
+			prefix = "; "
+		}
+		switch node.Kind {
+		case KindScalar:
+			builder.WriteString(fmt.Sprintf("%s%s=%s\n", prefix, node.Name, node.value.text))
+
+		case KindList, KindStructList:
+			writeINIComment(builder, fmt.Sprintf("%s (list not supported in INI format)", node.Name))
+
+		case KindMap:
+			writeINIComment(builder, fmt.Sprintf("%s (map not supported in INI format)", node.Name))
+		}
+	}
+
+	for _, node := range nodes {
+		if node.Kind != KindStruct {
+			continue
+		}
+		name := node.Name
+		if section != "" {
+			name = section + "." + node.Name
+		}
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		writeINIComment(builder, node.comment)
+		builder.WriteString(fmt.Sprintf("[%s]\n", name))
+		writeINISection(builder, node.Children, name, options)
+	}
+}
+
+// Writes a comment line if the text is not empty.
+func writeINIComment(builder *strings.Builder, text string) {
+	if text != "" {
+		builder.WriteString("; " + text + "\n")
+	}
+}
@@ -0,0 +1,107 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test INI generation with top-level fields.
+func TestGenerateINITemplate_TopLevel(t *testing.T) {
+	cfg := struct {
+		Host    string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port    int    `yaml:"port" default:"8080" help:"The port number"`
+		Enabled bool   `ini:"is_enabled" yaml:"enabled" default:"true"`
+		Secret  string `ini:"-" yaml:"secret"`
+	}{}
+	iniTemplate := GenerateINITemplate(cfg)
+
+	expected := `; The hostname
+host=localhost
+; The port number
+port=8080
+is_enabled=true
+`
+
+	assert.Equal(t, expected, iniTemplate)
+}
+
+// Test INI generation with nested structs rendered as sections.
+func TestGenerateINITemplate_Sections(t *testing.T) {
+	cfg := struct {
+		Database struct {
+			DSN  string `yaml:"dsn" placeholder:"postgres://localhost/db" help:"Connection string"`
+			Pool struct {
+				Size int `yaml:"size" default:"10"`
+			} `yaml:"pool"`
+		} `yaml:"database" help:"Database settings"`
+		Name string `yaml:"name" default:"app"`
+	}{}
+	iniTemplate := GenerateINITemplate(cfg)
+
+	expected := `name=app
+
+; Database settings
+[database]
+; Connection string
+dsn=postgres://localhost/db
+
+[database.pool]
+size=10
+`
+
+	assert.Equal(t, expected, iniTemplate)
+}
+
+// Test INI generation skips slices with a comment.
+func TestGenerateINITemplate_IgnoredSlices(t *testing.T) {
+	cfg := struct {
+		Options []string `yaml:"options" default:"1,2" help:"List of options"`
+		Level   string   `yaml:"level" default:"info"`
+	}{}
+	iniTemplate := GenerateINITemplate(cfg)
+
+	expected := `; List of options
+; options (list not supported in INI format)
+level=info
+`
+
+	assert.Equal(t, expected, iniTemplate)
+}
+
+// Test INI generation from the configuration tree: embedded and inlined structs are merged into their parent,
+// and the order, groups and deprecated fields follow the rules of the YAML template.
+func TestGenerateINITemplate_Tree(t *testing.T) {
+	type Common struct {
+		LogLevel string `yaml:"log_level" default:"info"`
+	}
+	type Limits struct {
+		Rate int `yaml:"rate" default:"100"`
+	}
+	cfg := struct {
+		*Common
+		Limits Limits `yaml:",inline"`
+		Port   int    `yaml:"port" default:"8080" group:"Network" help:"The port"`
+		Host   string `yaml:"host" default:"localhost" group:"Network"`
+		Old    string `yaml:"old" default:"x" deprecated:"use host instead"`
+		Cache  struct {
+			Size int `yaml:"size" default:"64"`
+		} `yaml:"cache"`
+	}{}
+
+	expected := `log_level=info
+rate=100
+; DEPRECATED: use host instead.
+; old=x
+
+; --- Network ---
+host=localhost
+; The port
+port=8080
+
+[cache]
+size=64
+`
+	assert.Equal(t, expected, GenerateINITemplate(&cfg, WithSort(SortAlpha), WithCommentedDeprecated()))
+	assert.NotContains(t, GenerateINITemplate(cfg, WithOmitDeprecated()), "old=")
+}
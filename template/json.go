@@ -0,0 +1,136 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateJSONTemplate generates a pretty-printed JSON template from a given configuration struct.
+// Defaults, quoting and slices follow the same rules as GenerateYAMLTemplate.
+// Slices without a default are rendered as empty arrays and maps as empty objects, and durations are written
+// in nanoseconds as encoding/json expects them, so that the template always unmarshals into the struct.
+// JSON has no comments, so help text is only rendered with WithJSONComments.
+// Key names are taken from the `json` tag, falling back to `yaml`, `kong` and the field name.
+func GenerateJSONTemplate(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+	nodes, _ := buildConfigTreeWithKeys(cfg, options, append([]string{"json"}, keyTags...))
+
+	var builder strings.Builder
+	writeJSONObject(&builder, nodes, 0, options)
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// Writes the nodes as a JSON object, indented by the given depth.
+func writeJSONObject(builder *strings.Builder, nodes []*Node, depth int, options *Options) {
+	if len(nodes) == 0 {
+		builder.WriteString("{}")
+		return
+	}
+
+	indentation := strings.Repeat("  ", depth+1)
+	builder.WriteString("{\n")
+	for i, node := range nodes {
+		if options.jsonComments && node.comment != "" {
+			builder.WriteString(indentation + "// " + node.comment + "\n")
+		}
+		builder.WriteString(indentation + jsonString(node.Name) + ": ")
+
+		switch node.Kind {
+		case KindScalar:
+			builder.WriteString(jsonLiteral(node.value, node.field.Type))
+
+		case KindStruct:
+			writeJSONObject(builder, node.Children, depth+1, options)
+
+		case KindStructList:
+			if node.examples == 0 {
+				builder.WriteString("[]")
+				break
+			}
+			builder.WriteString("[\n")
+			for j := 0; j < node.examples; j++ {
+				builder.WriteString(indentation + "  ")
+				writeJSONObject(builder, node.Children, depth+2, options)
+				if j < node.examples-1 {
+					builder.WriteString(",")
+				}
+				builder.WriteString("\n")
+			}
+			builder.WriteString(indentation + "]")
+
+		case KindList:
+			if len(node.items) == 0 {
+				builder.WriteString("[]")
+				break
+			}
+			builder.WriteString("[\n")
+			for j, item := range node.items {
+				builder.WriteString(indentation + "  " + jsonLiteral(item, node.field.Type.Elem()))
+				if j < len(node.items)-1 {
+					builder.WriteString(",")
+				}
+				builder.WriteString("\n")
+			}
+			builder.WriteString(indentation + "]")
+
+		case KindMap:
+			if len(node.entries) == 0 {
+				builder.WriteString("{}")
+				break
+			}
+			builder.WriteString("{\n")
+			for j, entry := range node.entries {
+				builder.WriteString(indentation + "  " + jsonString(entry.key) + ": " + jsonLiteral(entry.value, node.field.Type.Elem()))
+				if j < len(node.entries)-1 {
+					builder.WriteString(",")
+				}
+				builder.WriteString("\n")
+			}
+			builder.WriteString(indentation + "}")
+		}
+
+		if i < len(nodes)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(strings.Repeat("  ", depth) + "}")
+}
+
+// Returns the JSON literal of a scalar of the given type. Strings are quoted, and so are bare values that are
+// not valid JSON; durations are written in nanoseconds, hexadecimal integers in decimal, and unknown values as null.
+func jsonLiteral(value scalar, t reflect.Type) string {
+	if value.null {
+		return "null"
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		if duration, err := time.ParseDuration(value.text); err == nil {
+			return strconv.FormatInt(int64(duration), 10)
+		}
+	}
+	// JSON has no hexadecimal literals, so integers of `format:"hex"` are written in decimal
+	if decimal, ok := hexDecimal(value.text); ok && !value.quoted {
+		return decimal
+	}
+	if !value.quoted && json.Valid([]byte(value.text)) {
+		return value.text
+	}
+	return jsonString(value.text)
+}
+
+// Returns the quoted JSON string of a text, without escaping HTML characters.
+func jsonString(text string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(text)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
@@ -0,0 +1,168 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonConfig struct {
+	Host    string        `yaml:"host" default:"localhost" help:"The hostname"`
+	Port    int           `yaml:"port" default:"8080" help:"The port number"`
+	Enabled bool          `yaml:"enabled" default:"true"`
+	Timeout time.Duration `yaml:"timeout" default:"30s" help:"Request timeout"`
+	Ratio   float64       `yaml:"ratio"`
+	Tags    []string      `yaml:"tags" default:"a,b"`
+	Ports   []int         `yaml:"ports"`
+	Meta    struct {
+		Version string `yaml:"version" default:"1.0" help:"App version"`
+	} `yaml:"meta"`
+	Servers []struct {
+		Name string `yaml:"name" default:"main"`
+	} `yaml:"servers"`
+	Labels map[string]string `yaml:"labels" help:"Extra labels"`
+}
+
+// Test JSON generation with the YAML rules for keys, defaults and quoting.
+func TestGenerateJSONTemplate(t *testing.T) {
+	expected := `{
+  "host": "localhost",
+  "port": 8080,
+  "enabled": true,
+  "timeout": 30000000000,
+  "ratio": null,
+  "tags": [
+    "a",
+    "b"
+  ],
+  "ports": [],
+  "meta": {
+    "version": "1.0"
+  },
+  "servers": [
+    {
+      "name": "main"
+    }
+  ],
+  "labels": {}
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(jsonConfig{}))
+}
+
+// Test JSONC generation with help text rendered as comments.
+func TestGenerateJSONTemplate_WithJSONComments(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Meta struct {
+			Version string `yaml:"version" default:"1.0" help:"App version"`
+		} `yaml:"meta" help:"Metadata"`
+	}{}
+
+	expected := `{
+  // The hostname
+  "host": "localhost",
+  // Metadata
+  "meta": {
+    // App version
+    "version": "1.0"
+  }
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, WithJSONComments()))
+}
+
+// Test that the generated JSON is valid and unmarshals into the struct.
+func TestGenerateJSONTemplate_RoundTrip(t *testing.T) {
+	type Config struct {
+		Host  string   `yaml:"host" json:"host" default:"<localhost>"`
+		Port  int      `yaml:"port" json:"port" default:"8080"`
+		Ratio float64  `yaml:"ratio" json:"ratio" default:"0.5"`
+		Tags  []string `yaml:"tags" json:"tags" default:"a,b"`
+		Meta  struct {
+			Enabled bool `yaml:"enabled" json:"enabled" default:"true"`
+		} `yaml:"meta" json:"meta"`
+		Labels  map[string]string `yaml:"labels" json:"labels"`
+		Timeout time.Duration     `yaml:"timeout" json:"timeout" default:"1m30s"`
+		Retries []time.Duration   `yaml:"retries" json:"retries" default:"1s,2s"`
+		Workers int               `yaml:"workers" json:"worker_count" default:"4"`
+	}
+
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(GenerateJSONTemplate(Config{})), &cfg))
+
+	assert.Equal(t, "<localhost>", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.True(t, cfg.Meta.Enabled)
+	assert.Equal(t, 90*time.Second, cfg.Timeout)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, cfg.Retries)
+	assert.Equal(t, 4, cfg.Workers, "Keys should be taken from the json tag first")
+}
+
+// Test that map defaults are written as objects.
+func TestGenerateJSONTemplate_MapDefault(t *testing.T) {
+	cfg := struct {
+		Labels  map[string]string `yaml:"labels" default:"env=prod,team=core"`
+		Weights map[string]int    `yaml:"weights" default:"a=1"`
+	}{}
+
+	expected := `{
+  "labels": {
+    "env": "prod",
+    "team": "core"
+  },
+  "weights": {
+    "a": 1
+  }
+}
+`
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg))
+}
+
+// Test that slices of structs are written with the configured number of example entries.
+func TestGenerateJSONTemplate_SliceExamples(t *testing.T) {
+	cfg := struct {
+		Servers []struct {
+			Name string `yaml:"name" default:"main"`
+		} `yaml:"servers" examples:"2"`
+		Mirrors []struct {
+			Name string `yaml:"name"`
+		} `yaml:"mirrors" examples:"0"`
+	}{}
+
+	expected := `{
+  "servers": [
+    {
+      "name": "main"
+    },
+    {
+      "name": "main"
+    }
+  ],
+  "mirrors": []
+}
+`
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg))
+}
+
+// Test that hexadecimal integers are written in decimal, so that the template decodes into the struct.
+func TestGenerateJSONTemplate_Hex(t *testing.T) {
+	type Config struct {
+		Mask   int    `json:"mask" default:"255" format:"hex"`
+		Offset int    `json:"offset" default:"-16" format:"hex"`
+		Flags  []uint `json:"flags" default:"16,0x20" format:"hex"`
+	}
+	jsonTemplate := GenerateJSONTemplate(Config{})
+	assert.Equal(t, "{\n  \"mask\": 255,\n  \"offset\": -16,\n  \"flags\": [\n    16,\n    32\n  ]\n}\n", jsonTemplate)
+
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(jsonTemplate), &cfg))
+	assert.Equal(t, Config{Mask: 255, Offset: -16, Flags: []uint{16, 32}}, cfg)
+}
@@ -0,0 +1,179 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// KongFieldInfo describes the Kong CLI metadata of a configuration field.
+type KongFieldInfo struct {
+	// Path is the dot-separated path of YAML keys from the root struct to the field.
+	Path string
+	// Name is the flag name, from the `name` tag or the kong tag, defaulting to the kebab-cased field name.
+	// The `prefix` tags of enclosing structs are prepended.
+	Name string
+	// Short is the short flag name from the `short` tag.
+	Short string
+	// Type is the Go type of the field.
+	Type string
+	// Default is the value of the `default` tag.
+	Default string
+	// Help is the value of the `help` tag.
+	Help string
+	// Enum lists the allowed values from the `enum` tag.
+	Enum []string
+	// Required reports whether the field is tagged as required.
+	Required bool
+	// Sep is the separator of slice and map flags, "," unless set by the `sep` tag.
+	Sep string
+}
+
+// ParseKongTagsFromStruct extracts the Kong CLI metadata of all leaf fields of a configuration struct.
+// Every Kong setting is read from its own tag (e.g. `short:"p"`) or from the kong tag (e.g. `kong:"short='p'"`),
+// so tools can generate completion scripts, man pages or stub tests without depending on Kong itself.
+func ParseKongTagsFromStruct(cfg interface{}) []KongFieldInfo {
+	var fields []KongFieldInfo
+	collectKongFields(reflect.TypeOf(cfg), "", "", &fields)
+	return fields
+}
+
+func collectKongFields(t reflect.Type, parent, prefix string, fields *[]KongFieldInfo) {
+	walkStruct(t, parent, keyTags, keyNaming{}, func(f structField) bool {
+		if isNestedStruct(f.Type) {
+			collectKongFields(f.Type, f.Path, prefix+kongTagValue(f.StructField, "prefix"), fields)
+			return false
+		}
+
+		info := KongFieldInfo{
+			Path:     f.Path,
+			Name:     prefix + kongFlagName(f.StructField),
+			Short:    kongTagValue(f.StructField, "short"),
+			Type:     f.Type.String(),
+			Default:  kongTagValue(f.StructField, "default"),
+			Help:     kongTagValue(f.StructField, "help"),
+			Required: isKongRequired(f.StructField),
+		}
+		if enum := kongTagValue(f.StructField, "enum"); enum != "" {
+			for _, value := range strings.Split(enum, ",") {
+				info.Enum = append(info.Enum, strings.TrimSpace(value))
+			}
+		}
+		if kind := f.Type.Kind(); kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map {
+			info.Sep = kongTagValue(f.StructField, "sep")
+			if info.Sep == "" {
+				info.Sep = ","
+			}
+		}
+		*fields = append(*fields, info)
+		return false
+	})
+}
+
+// Returns the flag name of a field, defaulting to the kebab-cased field name as Kong does.
+func kongFlagName(field reflect.StructField) string {
+	if name := kongTagValue(field, "name"); name != "" {
+		return name
+	}
+	return kebabCase(field.Name)
+}
+
+// Reports whether a field is required, either by a `required` tag or by a required option in the kong tag.
+func isKongRequired(field reflect.StructField) bool {
+	if value, ok := field.Tag.Lookup("required"); ok {
+		return value != "false"
+	}
+	value, ok := parseKongTag(field.Tag.Get("kong"))["required"]
+	return ok && value != "false"
+}
+
+// Returns the name given by a kong tag: the value of its name setting, or the whole tag
+// if it is a bare name without any settings. Tags carrying only other settings provide no name.
+func kongTagName(tag string) string {
+	if !strings.ContainsAny(tag, "=,") {
+		return tag
+	}
+	return parseKongTag(tag)["name"]
+}
+
+// Returns the name Kong gives a field: the value of its `name` tag, or the name given by its kong tag.
+func kongName(field reflect.StructField) string {
+	if name := field.Tag.Get("name"); name != "" && name != "-" {
+		return name
+	}
+	if tag := field.Tag.Get("kong"); tag != "-" {
+		return kongTagName(tag)
+	}
+	return ""
+}
+
+// Returns a Kong setting from its own tag, falling back to the kong tag.
+func kongTagValue(field reflect.StructField, key string) string {
+	if value := field.Tag.Get(key); value != "" {
+		return value
+	}
+	return parseKongTag(field.Tag.Get("kong"))[key]
+}
+
+// Parses a kong tag of the form `required,help='Port to listen on',short='p'` into its settings.
+// Settings without a value are mapped to an empty string; values may be quoted with single quotes.
+func parseKongTag(tag string) map[string]string {
+	settings := make(map[string]string)
+	var key, value strings.Builder
+	inValue, quoted := false, false
+
+	flush := func() {
+		if name := strings.TrimSpace(key.String()); name != "" {
+			settings[name] = value.String()
+		}
+		key.Reset()
+		value.Reset()
+		inValue = false
+	}
+
+	for _, r := range tag {
+		switch {
+		case quoted:
+			if r == '\'' {
+				quoted = false
+			} else {
+				value.WriteRune(r)
+			}
+		case r == '\'' && inValue:
+			quoted = true
+		case r == ',':
+			flush()
+		case r == '=' && !inValue:
+			inValue = true
+		case inValue:
+			value.WriteRune(r)
+		default:
+			key.WriteRune(r)
+		}
+	}
+	flush()
+
+	return settings
+}
+
+// Converts a Go identifier to kebab-case, keeping initialisms together (e.g. HTTPPort to http-port).
+func kebabCase(name string) string {
+	return splitWords(name, "-")
+}
+
+// Splits a Go identifier into lowercase words joined by the separator, keeping initialisms together.
+func splitWords(name, sep string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				builder.WriteString(sep)
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
@@ -0,0 +1,61 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test extraction of Kong CLI metadata from a configuration struct.
+func TestParseKongTagsFromStruct(t *testing.T) {
+	cfg := struct {
+		Host     string   `yaml:"host" short:"H" default:"localhost" help:"The hostname"`
+		Port     int      `yaml:"port" kong:"required,short='p',help='Port to listen on'"`
+		LogLevel string   `yaml:"log_level" enum:"debug, info, warn" default:"info"`
+		Tags     []string `yaml:"tags" sep:";"`
+		HTTPPort int      `name:"http" yaml:"http_port"`
+		Database struct {
+			DSN     string `yaml:"dsn" required:"" help:"Connection string"`
+			Verbose bool   `yaml:"verbose" required:"false"`
+		} `yaml:"database" prefix:"db-"`
+		Ignored string `kong:"-"`
+	}{}
+	fields := ParseKongTagsFromStruct(cfg)
+
+	expected := []KongFieldInfo{
+		{Path: "host", Name: "host", Short: "H", Type: "string", Default: "localhost", Help: "The hostname"},
+		{Path: "port", Name: "port", Short: "p", Type: "int", Help: "Port to listen on", Required: true},
+		{Path: "log_level", Name: "log-level", Type: "string", Default: "info", Enum: []string{"debug", "info", "warn"}},
+		{Path: "tags", Name: "tags", Type: "[]string", Sep: ";"},
+		{Path: "http_port", Name: "http", Type: "int"},
+		{Path: "database.dsn", Name: "db-dsn", Type: "string", Help: "Connection string", Required: true},
+		{Path: "database.verbose", Name: "db-verbose", Type: "bool"},
+	}
+
+	assert.Equal(t, expected, fields)
+}
+
+// Test parsing of kong tags with options and quoted values.
+func TestParseKongTag(t *testing.T) {
+	settings := parseKongTag("required,help='Port, to listen on',short='p', name=port")
+
+	assert.Equal(t, map[string]string{
+		"required": "",
+		"help":     "Port, to listen on",
+		"short":    "p",
+		"name":     "port",
+	}, settings)
+}
+
+// Test conversion of Go identifiers to kebab-case.
+func TestKebabCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Host":           "host",
+		"MaxConnections": "max-connections",
+		"HTTPPort":       "http-port",
+		"ServerHTTP":     "server-http",
+		"TLS2Cert":       "tls2-cert",
+	} {
+		assert.Equal(t, expected, kebabCase(name), name)
+	}
+}
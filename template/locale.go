@@ -0,0 +1,29 @@
+package template
+
+import (
+	"maps"
+	"sync"
+)
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]map[string]string{}
+)
+
+// RegisterLocale registers the translations of the help texts for a locale, e.g. "es", used with WithHelpLocale.
+// Translations are keyed by the dot-separated path of the field, e.g. "server.host", rather than by the English
+// help text, so that fields sharing a help text can be translated differently. The table is copied, and
+// registering a locale again replaces its translations. It is safe to call RegisterLocale concurrently.
+func RegisterLocale(locale string, translations map[string]string) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[locale] = maps.Clone(translations)
+}
+
+// lookupTranslation returns the translation of the help text of the field at the given path, if any.
+func lookupTranslation(locale, path string) (string, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	translation, ok := locales[locale][path]
+	return translation, ok
+}
@@ -0,0 +1,39 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test YAML generation with the help texts translated to Spanish, keyed by field path.
+func TestGenerateYAMLTemplate_HelpLocale(t *testing.T) {
+	cfg := struct {
+		Server struct {
+			Host string `yaml:"host" default:"localhost" help:"The hostname"`
+			Port int    `yaml:"port" default:"8080" help:"The port number" min:"1"`
+		} `yaml:"server"`
+		Debug bool `yaml:"debug" default:"false" help:"Enable debug logging"`
+	}{}
+
+	RegisterLocale("es", map[string]string{
+		"server.host": "Nombre del host",
+		"server.port": "Número de puerto",
+	})
+	defer RegisterLocale("es", nil)
+
+	expected := `server:
+  host: "localhost" # Nombre del host
+  port: 8080        # Número de puerto; min: 1
+debug: false # Enable debug logging
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithHelpLocale("es")))
+
+	expected = `server:
+  host: "localhost" # The hostname
+  port: 8080        # The port number; min: 1
+debug: false # Enable debug logging
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg), "Help texts should stay in English without a locale")
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithHelpLocale("fr")), "Unknown locales should fall back to the help tag")
+}
@@ -0,0 +1,153 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// nonAnchor matches the characters replaced in the anchors of Markdown sections.
+var nonAnchor = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// markdownSection is a table of the Markdown documentation, describing the fields of a struct.
+type markdownSection struct {
+	path string
+	rows []string
+}
+
+// GenerateMarkdownDocs generates a Markdown configuration reference from a given configuration struct.
+// The fields of every struct are described in a table with the columns Key, Type, Default, Required,
+// Env var and Description. Keys are dotted paths resolved from the same tags as GenerateYAMLTemplate,
+// with `[]` marking the elements of slices of structs, and nested structs link to their own table.
+// The tables of nested structs are preceded by a heading with the path of the struct, at the level
+// set by WithHeadingLevel. With WithFlatDocs all fields are described in a single table instead.
+func GenerateMarkdownDocs(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+
+	var sections []*markdownSection
+	root := &markdownSection{}
+	sections = append(sections, root)
+	collectMarkdownRows(reflect.TypeOf(cfg), "", "", root, &sections, options)
+
+	var builder strings.Builder
+	for i, section := range sections {
+		if options.flatDocs && i > 0 {
+			break
+		}
+		if i > 0 {
+			builder.WriteString(fmt.Sprintf("\n<a id=\"%s\"></a>\n\n", markdownAnchor(section.path)))
+			builder.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", options.headingLevel), section.path))
+		}
+		builder.WriteString("| Key | Type | Default | Required | Env var | Description |\n")
+		builder.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, row := range section.rows {
+			builder.WriteString(row + "\n")
+		}
+	}
+	return builder.String()
+}
+
+// Collects the rows of the fields of a struct into a section, and the sections of its nested structs.
+// In flat mode the rows of nested structs are added to the same section instead.
+func collectMarkdownRows(t reflect.Type, parent, envPrefix string, section *markdownSection, sections *[]*markdownSection, options *Options) {
+	walkStruct(t, parent, keyTags, options.keyNaming(), func(f structField) bool {
+		elem := f.Type
+		nestedPath := f.Path
+		isStruct := isNestedStruct(elem)
+		if !isStruct && elem.Kind() == reflect.Slice && isNestedStruct(elem.Elem()) {
+			isStruct = true
+			elem = elem.Elem()
+			nestedPath += "[]"
+		}
+
+		if isStruct {
+			nestedEnvPrefix := envPrefix + f.Tag.Get("envprefix")
+			if options.flatDocs {
+				collectMarkdownRows(elem, nestedPath, nestedEnvPrefix, section, sections, options)
+				return false
+			}
+			nested := &markdownSection{path: nestedPath}
+			*sections = append(*sections, nested)
+			section.rows = append(section.rows, markdownRow(
+				fmt.Sprintf("[`%s`](#%s)", f.Path, markdownAnchor(nestedPath)),
+				markdownTypeName(f.Type), "", f.StructField, ""))
+			collectMarkdownRows(elem, nestedPath, nestedEnvPrefix, nested, sections, options)
+			return false
+		}
+
+		env := ""
+		if name := envName(f.StructField); name != "" {
+			env = envPrefix + name
+		}
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		defaultValue := f.Tag.Get("default")
+		if isBytesType(elem) && defaultValue != "" {
+			defaultValue = bytesText(f.StructField, []byte(defaultValue))
+		}
+		section.rows = append(section.rows, markdownRow(
+			"`"+f.Path+"`", markdownTypeName(f.Type), defaultValue, f.StructField, env))
+		return false
+	})
+}
+
+// Formats a table row. The key is given already formatted, the other cells are written as code when not empty.
+func markdownRow(key, typeName, defaultValue string, field reflect.StructField, env string) string {
+	required := ""
+	if isKongRequired(field) {
+		required = "yes"
+	}
+	description := joinComment(deprecationComment(field), fieldComment(field))
+	if enum := kongTagValue(field, "enum"); enum != "" {
+		values := strings.Split(enum, ",")
+		for i, value := range values {
+			values[i] = strings.TrimSpace(value)
+		}
+		description = joinComment(description, "One of: "+strings.Join(values, ", ")+".")
+	}
+	cells := []string{key, markdownCode(typeName), markdownCode(defaultValue), required, markdownCode(env), markdownEscape(description)}
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+// Returns the type name shown in the documentation: element types are shown for slices and maps,
+// and anonymous structs are shown as objects.
+func markdownTypeName(t reflect.Type) string {
+	switch {
+	case t.Kind() == reflect.Ptr:
+		return markdownTypeName(t.Elem())
+	case isTextScalar(t):
+		return t.String()
+	case isBytesType(t):
+		return "[]byte"
+	case t.Kind() == reflect.Slice:
+		return "[]" + markdownTypeName(t.Elem())
+	case t.Kind() == reflect.Map:
+		return "map[" + markdownTypeName(t.Key()) + "]" + markdownTypeName(t.Elem())
+	case t.Kind() == reflect.Struct && t.Name() == "":
+		return "object"
+	case t.Kind() == reflect.Struct:
+		return t.Name()
+	default:
+		return t.String()
+	}
+}
+
+// Returns the anchor of the section of a path, e.g. servers[].tls becomes servers-tls.
+func markdownAnchor(path string) string {
+	return strings.Trim(nonAnchor.ReplaceAllString(strings.ToLower(path), "-"), "-")
+}
+
+// Formats a cell as inline code, leaving empty cells empty.
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + markdownEscape(text) + "`"
+}
+
+// Escapes the pipes of a cell, which would otherwise end the cell.
+func markdownEscape(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
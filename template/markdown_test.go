@@ -0,0 +1,94 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type docsEndpoint struct {
+	URL string `yaml:"url" help:"Endpoint URL"`
+}
+
+type docsConfig struct {
+	Host     string            `yaml:"host" env:"HOST" default:"localhost" help:"The hostname"`
+	Port     int               `yaml:"port" default:"8080" min:"1" max:"65535" required:""`
+	LogLevel string            `yaml:"log_level" default:"info" enum:"debug,info" help:"Log level"`
+	Timeout  time.Duration     `yaml:"timeout" default:"30s" help:"Timeout | per request"`
+	Labels   map[string]string `yaml:"labels"`
+	Database struct {
+		DSN string `yaml:"dsn" env:"DSN" help:"Connection string"`
+	} `yaml:"database" envprefix:"DB_" help:"Database settings"`
+	Upstreams []docsEndpoint `yaml:"upstreams"`
+}
+
+// Test Markdown documentation with a table per struct.
+func TestGenerateMarkdownDocs(t *testing.T) {
+	expected := "| Key | Type | Default | Required | Env var | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `host` | `string` | `localhost` |  | `HOST` | The hostname |\n" +
+		"| `port` | `int` | `8080` | yes |  | range: 1-65535 |\n" +
+		"| `log_level` | `string` | `info` |  |  | Log level One of: debug, info. |\n" +
+		"| `timeout` | `time.Duration` | `30s` |  |  | Timeout \\| per request |\n" +
+		"| `labels` | `map[string]string` |  |  |  |  |\n" +
+		"| [`database`](#database) | `object` |  |  |  | Database settings |\n" +
+		"| [`upstreams`](#upstreams) | `[]docsEndpoint` |  |  |  |  |\n" +
+		"\n<a id=\"database\"></a>\n\n" +
+		"### database\n\n" +
+		"| Key | Type | Default | Required | Env var | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `database.dsn` | `string` |  |  | `DB_DSN` | Connection string |\n" +
+		"\n<a id=\"upstreams\"></a>\n\n" +
+		"### upstreams[]\n\n" +
+		"| Key | Type | Default | Required | Env var | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `upstreams[].url` | `string` |  |  |  | Endpoint URL |\n"
+
+	assert.Equal(t, expected, GenerateMarkdownDocs(docsConfig{}, WithHeadingLevel(3)))
+}
+
+// Test Markdown documentation flattened into a single table.
+func TestGenerateMarkdownDocs_Flat(t *testing.T) {
+	cfg := struct {
+		Host     string `yaml:"host" default:"localhost"`
+		Database struct {
+			DSN string `yaml:"dsn" help:"Connection string"`
+		} `yaml:"database"`
+		Upstreams []docsEndpoint `yaml:"upstreams"`
+	}{}
+
+	expected := "| Key | Type | Default | Required | Env var | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `host` | `string` | `localhost` |  |  |  |\n" +
+		"| `database.dsn` | `string` |  |  |  | Connection string |\n" +
+		"| `upstreams[].url` | `string` |  |  |  | Endpoint URL |\n"
+
+	assert.Equal(t, expected, GenerateMarkdownDocs(cfg, WithFlatDocs()))
+}
+
+// Test that deprecated fields are flagged in the description.
+func TestGenerateMarkdownDocs_Deprecated(t *testing.T) {
+	cfg := struct {
+		Port int `yaml:"port" default:"8080" help:"Port" deprecated:"use listen instead"`
+	}{}
+
+	expected := "| Key | Type | Default | Required | Env var | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `port` | `int` | `8080` |  |  | DEPRECATED: use listen instead. Port |\n"
+	assert.Equal(t, expected, GenerateMarkdownDocs(cfg))
+}
+
+// Test Markdown documentation of byte slices.
+func TestGenerateMarkdownDocs_Bytes(t *testing.T) {
+	cfg := struct {
+		Token []byte `yaml:"token" default:"abc123"`
+		Key   []byte `yaml:"key" default:"secret" encoding:"base64"`
+	}{}
+	expected := "| Key | Type | Default | Required | Env var | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `token` | `[]byte` | `abc123` |  |  |  |\n" +
+		"| `key` | `[]byte` | `c2VjcmV0` |  |  |  |\n"
+
+	assert.Equal(t, expected, GenerateMarkdownDocs(cfg))
+}
@@ -0,0 +1,220 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeTemplate upgrades an existing YAML configuration file to the current configuration struct.
+// All values and hand-written comments of the file are kept, and the keys introduced in the struct since
+// the file was written are appended to their mapping with their defaults and help comments, as rendered
+// by GenerateYAMLTemplate with the same options. New keys are also added to every element of a slice of
+// structs, while the keys of maps are free-form and left untouched.
+//
+// With WithCommentOutRemoved, keys of the file that no longer exist in the struct are commented out at the
+// end of their mapping; they are never deleted. Merging is stable: merging the output again yields the same output.
+// Note that the file is re-encoded, so its formatting (e.g. comment alignment) may be normalized.
+//
+// With WithSchemaVersion, the merged file records the current schema version. If the existing file recorded
+// another version, or none, the result reports it like the drift report does, so that migrations the merge
+// cannot perform, such as renamed keys, can be applied; the merge itself still succeeds.
+func MergeTemplate(existingYAML []byte, cfg interface{}, opts ...TemplateOption) (MergeResult, error) {
+	options := applyTemplateOptions(opts)
+	nodes, err := buildConfigTree(cfg, options)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	w := &yamlWriter{
+		options:   options,
+		maxLength: make(map[alignGroup]int),
+	}
+	w.writeNodes(nodes, 0, "")
+
+	// The YAML decoder rejects the %YAML 1.2 directive, which the re-encoded file does not keep anyway
+	parseOptions := *options
+	parseOptions.yamlDirective = false
+	var template yaml.Node
+	if err := yaml.Unmarshal([]byte(generateYAMLWithAlignment(w.lines, w.maxLength, &parseOptions)), &template); err != nil {
+		return MergeResult{}, fmt.Errorf("failed to parse generated template: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(existingYAML, &document); err != nil {
+		return MergeResult{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	empty := document.Kind == 0 || document.Content[0].Tag == "!!null"
+	switch {
+	case empty:
+		// An empty file is replaced by the template
+		document = template
+	case document.Content[0].Kind != yaml.MappingNode:
+		return MergeResult{}, fmt.Errorf("YAML document is not a mapping")
+	}
+	if options.schemaVersion != "" && !isSchemaVersionField(nodes) && len(document.Content) > 0 {
+		removeMappingKey(document.Content[0], schemaVersionKey)
+	}
+	if !empty && len(template.Content) > 0 {
+		if err := mergeMapping(document.Content[0], template.Content[0], nodes, options); err != nil {
+			return MergeResult{}, err
+		}
+	}
+	if options.schemaVersion == "" {
+		data, err := encodeYAMLNode(&document)
+		return MergeResult{Data: data}, err
+	}
+
+	// The version is recorded again in the form of the options
+	if options.schemaVersionKey && len(document.Content) > 0 && document.Content[0].Kind == yaml.MappingNode {
+		root := document.Content[0]
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: schemaVersionKey},
+			{Kind: yaml.ScalarNode, Value: options.schemaVersion, Style: yaml.DoubleQuotedStyle},
+		}, root.Content...)
+	}
+	data, err := encodeYAMLNode(&document)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	data = schemaVersionCommentLine.ReplaceAll(data, nil)
+	if !options.schemaVersionKey {
+		data = append([]byte(schemaVersionLine(options)), data...)
+	}
+	result := MergeResult{Data: data}
+	if !empty {
+		result.SchemaVersion = checkSchemaVersion(existingYAML, options)
+	}
+	return result, nil
+}
+
+// MergeResult is the outcome of MergeTemplate.
+type MergeResult struct {
+	// Data is the merged configuration file.
+	Data []byte
+	// SchemaVersion is set when the existing file records a schema version other than the one of WithSchemaVersion.
+	SchemaVersion *SchemaVersionError
+}
+
+// Removes a key and its value from a mapping.
+func removeMappingKey(mapping *yaml.Node, name string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// Merges the keys of a template mapping missing from an existing mapping, following the configuration tree.
+func mergeMapping(existing, template *yaml.Node, nodes []*Node, options *Options) error {
+	known := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		known[node.Name] = true
+	}
+
+	var removed []string
+	if options.commentOutRemoved {
+		kept := make([]*yaml.Node, 0, len(existing.Content))
+		for i := 0; i+1 < len(existing.Content); i += 2 {
+			key, value := existing.Content[i], existing.Content[i+1]
+			if known[key.Value] {
+				kept = append(kept, key, value)
+				continue
+			}
+			lines, err := commentOutPair(key, value)
+			if err != nil {
+				return err
+			}
+			removed = append(removed, lines)
+		}
+		existing.Content = kept
+	}
+
+	for _, node := range nodes {
+		templateValue := mappingValue(template, node.Name)
+		if templateValue == nil {
+			continue
+		}
+		value := mappingValue(existing, node.Name)
+		if value == nil {
+			existing.Content = append(existing.Content, mappingKey(template, node.Name), templateValue)
+			continue
+		}
+
+		switch {
+		case node.Kind == KindStruct && value.Kind == yaml.MappingNode && templateValue.Kind == yaml.MappingNode:
+			if err := mergeMapping(value, templateValue, node.Children, options); err != nil {
+				return err
+			}
+		case node.Kind == KindStructList && value.Kind == yaml.SequenceNode &&
+			templateValue.Kind == yaml.SequenceNode && len(templateValue.Content) > 0:
+			for _, item := range value.Content {
+				if item.Kind != yaml.MappingNode {
+					continue
+				}
+				if err := mergeMapping(item, templateValue.Content[0], node.Children, options); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if len(removed) > 0 && len(existing.Content) > 0 {
+		last := existing.Content[len(existing.Content)-1]
+		last.FootComment = strings.TrimPrefix(last.FootComment+"\n"+strings.Join(removed, "\n"), "\n")
+	} else if len(removed) > 0 {
+		existing.FootComment = strings.TrimPrefix(existing.FootComment+"\n"+strings.Join(removed, "\n"), "\n")
+	}
+	return nil
+}
+
+// Renders a key-value pair as commented-out YAML lines.
+func commentOutPair(key, value *yaml.Node) (string, error) {
+	pair := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}
+	data, err := encodeYAMLNode(pair)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix("# "+line, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Returns the key node of a mapping with the given name, or nil.
+func mappingKey(mapping *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return mapping.Content[i]
+		}
+	}
+	return nil
+}
+
+// Returns the value node of a mapping with the given key, or nil.
+func mappingValue(mapping *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Encodes a YAML node with the indentation of the generated templates.
+func encodeYAMLNode(node *yaml.Node) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
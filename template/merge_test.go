@@ -0,0 +1,164 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeServer struct {
+	Name string `yaml:"name" help:"Server name"`
+	Port int    `yaml:"port" default:"80" help:"Server port"`
+}
+
+type mergeConfig struct {
+	Host     string `yaml:"host" default:"localhost" help:"The hostname"`
+	Port     int    `yaml:"port" default:"8080" help:"The port number"`
+	Database struct {
+		DSN     string `yaml:"dsn" help:"Connection string"`
+		MaxOpen int    `yaml:"max_open" default:"10" help:"Maximum open connections"`
+	} `yaml:"database" help:"Database settings"`
+	Servers []mergeServer     `yaml:"servers"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// Test merging new fields into an existing file, keeping its values and comments.
+func TestMergeTemplate(t *testing.T) {
+	existing := `# Production configuration
+host: example.com # our public host
+database:
+  # keep in sync with the vault
+  dsn: postgres://db
+servers:
+  - name: a
+  - name: b
+    port: 81
+labels:
+  team: core
+legacy: true
+`
+	merged, err := MergeTemplate([]byte(existing), mergeConfig{})
+	require.NoError(t, err)
+
+	expected := `# Production configuration
+host: example.com # our public host
+database:
+  # keep in sync with the vault
+  dsn: postgres://db
+  max_open: 10 # Maximum open connections
+servers:
+  - name: a
+    port: 80 # Server port
+  - name: b
+    port: 81
+labels:
+  team: core
+legacy: true
+port: 8080 # The port number
+`
+	assert.Equal(t, expected, string(merged.Data))
+
+	again, err := MergeTemplate(merged.Data, mergeConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, string(merged.Data), string(again.Data), "Merging twice should not change the output")
+}
+
+// Test commenting out keys that no longer exist in the struct.
+func TestMergeTemplate_CommentOutRemoved(t *testing.T) {
+	existing := `host: example.com
+port: 9090
+database:
+  dsn: postgres://db
+  max_open: 5
+  pool:
+    size: 3
+legacy: true
+`
+	merged, err := MergeTemplate([]byte(existing), mergeConfig{}, WithCommentOutRemoved())
+	require.NoError(t, err)
+
+	expected := `host: example.com
+port: 9090
+database:
+  dsn: postgres://db
+  max_open: 5
+  # pool:
+  #   size: 3
+servers:
+  - name: "null" # Server name
+    port: 80 # Server port
+labels:
+  key: value # Map example
+
+# legacy: true
+`
+	assert.Equal(t, expected, string(merged.Data))
+
+	again, err := MergeTemplate(merged.Data, mergeConfig{}, WithCommentOutRemoved())
+	require.NoError(t, err)
+	assert.Equal(t, string(merged.Data), string(again.Data), "Merging twice should not change the output")
+}
+
+// Test merging into an empty file, which yields the template.
+func TestMergeTemplate_Empty(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+	}{}
+	merged, err := MergeTemplate(nil, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "host: \"localhost\" # The hostname\n", string(merged.Data))
+
+	merged, err = MergeTemplate([]byte("---\n"), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "host: \"localhost\" # The hostname\n", string(merged.Data))
+
+	_, err = MergeTemplate([]byte("- a\n"), cfg)
+	assert.ErrorContains(t, err, "not a mapping")
+}
+
+// Test recording the current schema version in merged files, reporting files of another version.
+func TestMergeTemplate_SchemaVersion(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost"`
+		Port int    `yaml:"port" default:"8080"`
+	}{}
+	existing := `# Production configuration
+# kongkit-schema: v2
+host: example.com
+`
+	merged, err := MergeTemplate([]byte(existing), cfg, WithSchemaVersion("v3"))
+	require.NoError(t, err, "A file of another version should still be merged")
+	assert.Equal(t, &SchemaVersionError{File: "v2", Current: "v3"}, merged.SchemaVersion, "A file of another version should be reported")
+	expected := `# kongkit-schema: v3
+# Production configuration
+host: example.com
+port: 8080
+`
+	assert.Equal(t, expected, string(merged.Data), "The merged file should record the current version")
+
+	remerged, err := MergeTemplate(merged.Data, cfg, WithSchemaVersion("v3"))
+	require.NoError(t, err)
+	assert.Nil(t, remerged.SchemaVersion, "A file of the current version should not be reported")
+	assert.Equal(t, expected, string(remerged.Data))
+
+	merged, err = MergeTemplate([]byte("host: example.com\nlegacy: true\n"), cfg,
+		WithSchemaVersion("v3"), WithSchemaVersionKey(), WithCommentOutRemoved())
+	require.NoError(t, err)
+	assert.Equal(t, &SchemaVersionError{Current: "v3"}, merged.SchemaVersion, "A file without a version should be reported")
+	expected = `schema_version: "v3"
+host: example.com
+port: 8080
+# legacy: true
+`
+	assert.Equal(t, expected, string(merged.Data))
+
+	remerged, err = MergeTemplate(merged.Data, cfg, WithSchemaVersion("v3"), WithSchemaVersionKey(), WithCommentOutRemoved())
+	require.NoError(t, err)
+	assert.Nil(t, remerged.SchemaVersion)
+	assert.Equal(t, expected, string(remerged.Data), "The version key should not be commented out as removed")
+
+	merged, err = MergeTemplate(nil, cfg, WithSchemaVersion("v3"))
+	require.NoError(t, err)
+	assert.Nil(t, merged.SchemaVersion, "An empty file should not be reported")
+}
@@ -0,0 +1,100 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffField is a leaf field of a configuration tree, identified by its dotted path.
+type diffField struct {
+	path string
+	node *Node
+}
+
+// GenerateMigrationGuide generates a Markdown guide for migrating a configuration file between two versions
+// of a configuration struct. Fields are compared by their dotted paths, resolved from the same tags as
+// GenerateYAMLTemplate, with `[]` marking the elements of slices of structs. The guide lists the added fields
+// with their defaults, the removed fields, and the fields whose default changed. A removed field is shown
+// with its replacement when a field of the new struct names its path in a `replaces` tag.
+func GenerateMigrationGuide(oldCfg, newCfg interface{}, oldVersion, newVersion string) string {
+	oldNodes, _ := buildConfigTree(oldCfg, applyTemplateOptions(nil))
+	newNodes, _ := buildConfigTree(newCfg, applyTemplateOptions(nil))
+	oldFields := flattenConfigTree(oldNodes, "")
+	newFields := flattenConfigTree(newNodes, "")
+
+	oldByPath := make(map[string]*Node, len(oldFields))
+	for _, field := range oldFields {
+		oldByPath[field.path] = field.node
+	}
+	newByPath := make(map[string]*Node, len(newFields))
+	replacements := make(map[string]string)
+	for _, field := range newFields {
+		newByPath[field.path] = field.node
+		if replaces := field.node.field.Tag.Get("replaces"); replaces != "" {
+			replacements[replaces] = field.path
+		}
+	}
+
+	var added, removed, changed []string
+	for _, field := range newFields {
+		old, ok := oldByPath[field.path]
+		if !ok {
+			cells := []string{markdownCode(field.path), markdownCode(markdownTypeName(field.node.field.Type)),
+				markdownCode(field.node.Default), markdownEscape(field.node.Help)}
+			added = append(added, "| "+strings.Join(cells, " | ")+" |")
+			continue
+		}
+		if old.Default != field.node.Default {
+			cells := []string{markdownCode(field.path), markdownCode(old.Default), markdownCode(field.node.Default)}
+			changed = append(changed, "| "+strings.Join(cells, " | ")+" |")
+		}
+	}
+	for _, field := range oldFields {
+		if _, ok := newByPath[field.path]; ok {
+			continue
+		}
+		cells := []string{markdownCode(field.path), markdownCode(replacements[field.path])}
+		removed = append(removed, "| "+strings.Join(cells, " | ")+" |")
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Migrating from %s to %s\n", oldVersion, newVersion))
+	if len(added) == 0 && len(removed) == 0 && len(changed) == 0 {
+		builder.WriteString("\nNo configuration changes.\n")
+		return builder.String()
+	}
+	writeMigrationSection(&builder, "Added Fields", "| Key | Type | Default | Description |", added)
+	writeMigrationSection(&builder, "Removed Fields", "| Key | Replaced by |", removed)
+	writeMigrationSection(&builder, "Changed Defaults", "| Key | Old default | New default |", changed)
+	return builder.String()
+}
+
+// Writes a section of the migration guide with its table, unless it has no rows.
+func writeMigrationSection(builder *strings.Builder, title, header string, rows []string) {
+	if len(rows) == 0 {
+		return
+	}
+	builder.WriteString("\n### " + title + "\n\n")
+	builder.WriteString(header + "\n")
+	builder.WriteString("|" + strings.Repeat(" --- |", strings.Count(header, "|")-1) + "\n")
+	for _, row := range rows {
+		builder.WriteString(row + "\n")
+	}
+}
+
+// Lists the leaf fields of a configuration tree in order, with their dotted paths.
+func flattenConfigTree(nodes []*Node, parent string) []diffField {
+	var fields []diffField
+	for _, node := range nodes {
+		path := joinPath(parent, node.Name)
+		switch node.Kind {
+		case KindStruct:
+			fields = append(fields, flattenConfigTree(node.Children, path)...)
+		case KindStructList:
+			fields = append(fields, flattenConfigTree(node.Children, path+"[]")...)
+		default:
+			fields = append(fields, diffField{path: path, node: node})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,46 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test generation of a migration guide between two versions of a configuration struct.
+func TestGenerateMigrationGuide(t *testing.T) {
+	oldCfg := struct {
+		Host    string `yaml:"host" default:"localhost"`
+		Port    int    `yaml:"port" default:"8080"`
+		Timeout string `yaml:"timeout" default:"5s"`
+		Servers []struct {
+			Addr string `yaml:"addr"`
+		} `yaml:"servers"`
+	}{}
+	newCfg := struct {
+		Host     string `yaml:"host" default:"localhost"`
+		Port     int    `yaml:"port" default:"9090"`
+		Deadline string `yaml:"deadline" default:"5s" replaces:"timeout" help:"Request deadline"`
+		Servers  []struct {
+			Addr   string `yaml:"addr"`
+			Weight int    `yaml:"weight" default:"1"`
+		} `yaml:"servers"`
+	}{}
+
+	expected := "## Migrating from v1 to v2\n" +
+		"\n### Added Fields\n\n" +
+		"| Key | Type | Default | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `deadline` | `string` | `5s` | Request deadline |\n" +
+		"| `servers[].weight` | `int` | `1` |  |\n" +
+		"\n### Removed Fields\n\n" +
+		"| Key | Replaced by |\n" +
+		"| --- | --- |\n" +
+		"| `timeout` | `deadline` |\n" +
+		"\n### Changed Defaults\n\n" +
+		"| Key | Old default | New default |\n" +
+		"| --- | --- | --- |\n" +
+		"| `port` | `8080` | `9090` |\n"
+
+	assert.Equal(t, expected, GenerateMigrationGuide(oldCfg, newCfg, "v1", "v2"))
+	assert.Equal(t, "## Migrating from v1 to v1\n\nNo configuration changes.\n", GenerateMigrationGuide(oldCfg, oldCfg, "v1", "v1"))
+}
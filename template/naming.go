@@ -0,0 +1,57 @@
+package template
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy defines how the keys of fields without a key name in their tags are derived from their Go names.
+type NamingStrategy int
+
+const (
+	// NamingLower lowercases the field name, e.g. MaxConnections becomes maxconnections, as gopkg.in/yaml.v3 does.
+	NamingLower NamingStrategy = iota
+	// NamingSnakeCase splits the field name into lowercase words joined by underscores, keeping initialisms
+	// together, e.g. HTTPPort becomes http_port.
+	NamingSnakeCase
+	// NamingLowerCamel lowercases the first word of the field name and capitalizes the others,
+	// e.g. HTTPPort becomes httpPort and UserID becomes userId.
+	NamingLowerCamel
+	// NamingAsIs keeps the field name unchanged.
+	NamingAsIs
+)
+
+// Returns the key of a field name.
+func (n NamingStrategy) key(name string) string {
+	switch n {
+	case NamingSnakeCase:
+		return splitWords(name, "_")
+	case NamingLowerCamel:
+		words := strings.Split(splitWords(name, "_"), "_")
+		for i, word := range words[1:] {
+			runes := []rune(word)
+			if len(runes) > 0 {
+				runes[0] = unicode.ToUpper(runes[0])
+			}
+			words[i+1] = string(runes)
+		}
+		return strings.Join(words, "")
+	case NamingAsIs:
+		return name
+	default:
+		return strings.ToLower(name)
+	}
+}
+
+// keyNaming holds the settings deriving the keys of fields, see fieldKey.
+type keyNaming struct {
+	// strategy derives the keys of fields without a key name in their tags.
+	strategy NamingStrategy
+	// kongUnderscores translates the dashes of Kong names to underscores, see WithKongNameUnderscores.
+	kongUnderscores bool
+}
+
+// Returns the settings deriving the keys of fields.
+func (o *Options) keyNaming() keyNaming {
+	return keyNaming{strategy: o.namingStrategy, kongUnderscores: o.kongNameUnderscores}
+}
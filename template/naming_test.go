@@ -0,0 +1,111 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test the keys derived from field names by the naming strategies, with initialisms kept together.
+func TestNamingStrategy_Key(t *testing.T) {
+	tests := []struct {
+		name                           string
+		lower, snake, lowerCamel, asIs string
+	}{
+		{"MaxConnections", "maxconnections", "max_connections", "maxConnections", "MaxConnections"},
+		{"HTTPPort", "httpport", "http_port", "httpPort", "HTTPPort"},
+		{"UserID", "userid", "user_id", "userId", "UserID"},
+		{"TLS", "tls", "tls", "tls", "TLS"},
+		{"Retry2Delay", "retry2delay", "retry2_delay", "retry2Delay", "Retry2Delay"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.lower, NamingLower.key(tt.name))
+		assert.Equal(t, tt.snake, NamingSnakeCase.key(tt.name))
+		assert.Equal(t, tt.lowerCamel, NamingLowerCamel.key(tt.name))
+		assert.Equal(t, tt.asIs, NamingAsIs.key(tt.name))
+	}
+}
+
+// Test that the naming strategy drives the keys of untagged fields in all outputs, while tagged fields keep their keys.
+func TestWithNamingStrategy(t *testing.T) {
+	type Config struct {
+		MaxConnections int `default:"10" help:"Connection limit"`
+		HTTPServer     struct {
+			ReadTimeout string `default:"5s"`
+		}
+		LogLevel string `yaml:"log" default:"info"`
+	}
+	snakeCase := WithNamingStrategy(NamingSnakeCase)
+
+	expected := `max_connections: 10 # Connection limit
+http_server:
+  read_timeout: "5s"
+log: "info"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}, snakeCase))
+	assert.Contains(t, GenerateYAMLTemplate(Config{}), "maxconnections: 10", "Untagged fields should be lowercased by default")
+
+	env := GenerateEnvTemplate(Config{}, snakeCase, WithEnvNamesFromPath())
+	assert.Contains(t, env, "MAX_CONNECTIONS=10\n")
+	assert.Contains(t, env, "HTTP_SERVER_READ_TIMEOUT=5s\n")
+
+	compose := GenerateDockerComposeEnv(Config{}, "APP", snakeCase)
+	assert.Contains(t, compose, "  - APP_MAX_CONNECTIONS=10\n")
+	assert.Contains(t, compose, "  - APP_HTTP_SERVER_READ_TIMEOUT=5s\n")
+
+	defaults := BuildDefaultMap(Config{}, snakeCase)
+	assert.Equal(t, 10, defaults["max_connections"])
+	assert.Equal(t, map[string]any{"read_timeout": "5s"}, defaults["http_server"])
+
+	markdown := GenerateMarkdownDocs(Config{}, snakeCase)
+	assert.Contains(t, markdown, "| `max_connections` |")
+	assert.Contains(t, markdown, "## http_server")
+
+	data, err := GenerateJSONSchema(Config{}, snakeCase)
+	require.NoError(t, err)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Contains(t, schema["properties"], "max_connections")
+	assert.Contains(t, schema["properties"], "log")
+}
+
+// Test that the name given by Kong is used as key after the yaml and json tags, with the `name` tag taking
+// precedence over the kong tag, and that its dashes are translated with WithKongNameUnderscores.
+func TestKongNameKeys(t *testing.T) {
+	type Config struct {
+		ListenAddr string `name:"listen-addr" default:":8080" help:"Listen address"`
+		Timeout    string `yaml:"timeout" name:"request-timeout" default:"5s"`
+		Retries    int    `json:"retries" name:"max-retries" default:"3"`
+		LogLevel   string `name:"log-level" kong:"name='level'" default:"info"`
+		Workers    int    `kong:"name='worker-count'" default:"4"`
+	}
+
+	expected := `listen-addr: ":8080" # Listen address
+timeout: "5s"
+retries: 3
+log-level: "info"
+worker-count: 4
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}))
+
+	underscores := WithKongNameUnderscores()
+	expected = `listen_addr: ":8080" # Listen address
+timeout: "5s"
+retries: 3
+log_level: "info"
+worker_count: 4
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}, underscores))
+
+	for _, opts := range [][]TemplateOption{nil, {underscores}} {
+		env := GenerateEnvTemplate(Config{}, append(opts, WithEnvNamesFromPath())...)
+		assert.Contains(t, env, "LISTEN_ADDR=:8080\n")
+		assert.Contains(t, env, "LOG_LEVEL=info\n")
+		assert.Contains(t, env, "WORKER_COUNT=4\n")
+	}
+
+	assert.Contains(t, GenerateMarkdownDocs(Config{}), "| `listen-addr` |")
+	assert.Contains(t, GenerateMarkdownDocs(Config{}, underscores), "| `listen_addr` |")
+}
@@ -0,0 +1,414 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Options holds the settings shared by the template generators.
+type Options struct {
+	// fromValue makes the generator prefer the actual field values of the configuration over tag defaults.
+	fromValue bool
+
+	skipOmitempty  bool
+	omitEmpty      bool
+	flowStyleBelow int
+	commentColumn  int
+	compact        bool
+	commentPrefix  string
+	unitDisplay    UnitDisplayPolicy
+
+	fieldPathComment bool
+	sliceExamples    int
+	mapExamples      int
+
+	commentedExamples   bool
+	commentedDeprecated bool
+	ungroupedLast       bool
+	omitDeprecated      bool
+	sort                SortOrder
+	namingStrategy      NamingStrategy
+	kongNameUnderscores bool
+	jsonComments        bool
+	schemaURL           string
+	schemaVersion       string
+	schemaVersionKey    bool
+	leadingSeparator    bool
+	yamlDirective       bool
+	trailingNewlines    int
+	rootKey             string
+	yamlAnchors         bool
+
+	envNamesFromPath  bool
+	inlineEnvComments bool
+	headingLevel      int
+	flatDocs          bool
+
+	mapExampleProvider func(field reflect.StructField) string
+	defaultProvider    func(t reflect.Type) (string, bool)
+	helpLocale         string
+
+	commentOutRemoved bool
+}
+
+func defaultTemplateOptions() *Options {
+	return &Options{
+		headingLevel:  2,
+		sliceExamples: 1,
+		mapExamples:   1,
+		commentPrefix: "# ",
+
+		trailingNewlines: 1,
+	}
+}
+
+// TemplateOption defines a function signature for setting template Options.
+type TemplateOption func(*Options)
+
+func applyTemplateOptions(opts []TemplateOption) *Options {
+	options := defaultTemplateOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithSkipOmitempty
+// This option leaves fields tagged with `yaml:",omitempty"` out of the generated template,
+// which is useful for producing a minimal template.
+// By default such fields are rendered with an "(optional)" comment suffix,
+// or "(optional, omitted when empty)" when they have no default value.
+func WithSkipOmitempty() TemplateOption {
+	return func(o *Options) {
+		o.skipOmitempty = true
+	}
+}
+
+// WithOmitEmpty
+// This option leaves fields tagged with `yaml:",omitempty"` out of the generated template when their default
+// is empty or the zero value of their type, as the YAML encoder would omit them. Maps are always empty.
+// Unlike WithSkipOmitempty, optional fields with a non-zero default are still rendered.
+func WithOmitEmpty() TemplateOption {
+	return func(o *Options) {
+		o.omitEmpty = true
+	}
+}
+
+// WithFlowStyleBelow
+// This option renders slices of scalars with fewer than n default items in flow style, e.g. `tags: ["a", "b"]`,
+// which keeps templates compact. Fields tagged with `yaml:",flow"` always use flow style.
+// By default slices are rendered in block style.
+func WithFlowStyleBelow(n int) TemplateOption {
+	return func(o *Options) {
+		o.flowStyleBelow = n
+	}
+}
+
+// WithFixedCommentColumn
+// This option places the comment marker of every line at the given column, counted from zero,
+// instead of aligning comments to the longest line of each block. Lines reaching the column are
+// followed by a single space. This removes the dependency of the output on the other lines of a block.
+func WithFixedCommentColumn(col int) TemplateOption {
+	return func(o *Options) {
+		o.commentColumn = col
+	}
+}
+
+// WithCompact
+// This option places a single space before the comment marker of every line instead of aligning comments.
+// The output of a line then never depends on the other lines, which keeps diffs minimal when keys change.
+func WithCompact() TemplateOption {
+	return func(o *Options) {
+		o.compact = true
+	}
+}
+
+// WithCommentPrefix
+// This option sets the marker of the documentation comments of YAML templates: help comments, group headers,
+// the hint of mutually exclusive fields and the map example comment, e.g. "## " to tell them apart from
+// the "# " of commented-out values, which are not affected. Prefixes that do not start with "#" are ignored,
+// as they would not be YAML comments. The default prefix is "# ".
+func WithCommentPrefix(prefix string) TemplateOption {
+	return func(o *Options) {
+		if strings.HasPrefix(prefix, "#") {
+			o.commentPrefix = prefix
+		}
+	}
+}
+
+// WithFieldPathComment
+// This option appends the dot-separated path of every field, made of the YAML keys, to its comment,
+// e.g. `port: 8080 # The port number [server.http.port]`, so that fields of deep configurations are easy to locate.
+func WithFieldPathComment() TemplateOption {
+	return func(o *Options) {
+		o.fieldPathComment = true
+	}
+}
+
+// WithSliceExamples
+// This option sets the number of example entries rendered for slices of structs, one by default.
+// Several entries make the shape of the list obvious, while zero keeps the template short: the key is then
+// rendered as an empty list. The `examples` tag of a field overrides the option for that field.
+func WithSliceExamples(n int) TemplateOption {
+	return func(o *Options) {
+		o.sliceExamples = n
+	}
+}
+
+// WithUnitDisplay
+// This option selects which units of measurement are appended to the help comments of fields, e.g.
+// `timeout: 30 # Request timeout (seconds)`. By default the units set by the `unit` tag are shown;
+// UnitDisplayAlways also shows the units implied by types, such as durations, and UnitDisplayNever shows none.
+func WithUnitDisplay(policy UnitDisplayPolicy) TemplateOption {
+	return func(o *Options) {
+		o.unitDisplay = policy
+	}
+}
+
+// WithMapExampleCount
+// This option sets the number of example entries rendered for maps without a default or example, one by default.
+// A single entry is rendered as the `key: value` placeholder, several as numbered entries, e.g. `example1: value1`
+// and `example2: value2`, with a block of the fields of the struct for maps of structs. The values of other
+// maps are typed by their element, e.g. `key: 0` for a map[string]int, so that the template decodes.
+// Zero renders the key as an empty map. The `map_example` tag and WithMapExampleProvider take precedence over the option.
+func WithMapExampleCount(n int) TemplateOption {
+	return func(o *Options) {
+		o.mapExamples = n
+	}
+}
+
+// WithCommentedExamples
+// This option renders the fields whose value comes from the `example` tag commented out, e.g. `# cidr: "10.0.0.0/8"`,
+// instead of as values marked with "(example)", so that examples are never applied by accident.
+func WithCommentedExamples() TemplateOption {
+	return func(o *Options) {
+		o.commentedExamples = true
+	}
+}
+
+// WithCommentedDeprecated
+// This option renders the fields tagged with `deprecated` commented out in YAML templates, so that fresh
+// configurations do not use them while the keys stay documented. By default they are rendered as values
+// with a "DEPRECATED: ..." comment prefix, so that existing configurations still validate.
+func WithCommentedDeprecated() TemplateOption {
+	return func(o *Options) {
+		o.commentedDeprecated = true
+	}
+}
+
+// WithOmitDeprecated
+// This option leaves the fields tagged with `deprecated` out of the generated template entirely,
+// which is useful for producing fresh templates that only use the current keys.
+func WithOmitDeprecated() TemplateOption {
+	return func(o *Options) {
+		o.omitDeprecated = true
+	}
+}
+
+// WithUngroupedLast
+// This option renders the fields without a `group` tag after the groups of their struct instead of before them.
+func WithUngroupedLast() TemplateOption {
+	return func(o *Options) {
+		o.ungroupedLast = true
+	}
+}
+
+// WithHelpLocale
+// This option replaces the help texts in the comments with their translations registered for the locale
+// with RegisterLocale, looked up by the dot-separated path of the field. Fields without a translation keep
+// the text of their `help` tag.
+func WithHelpLocale(locale string) TemplateOption {
+	return func(o *Options) {
+		o.helpLocale = locale
+	}
+}
+
+// WithRootKey
+// This option nests the whole generated YAML document under a root key, e.g. "myservice" for a service
+// whose configuration is embedded in a shared values file. Dot-separated keys such as "services.myservice"
+// nest the document several levels deep. Comments are aligned as in the document without the root key.
+func WithRootKey(key string) TemplateOption {
+	return func(o *Options) {
+		o.rootKey = key
+	}
+}
+
+// WithYAMLAnchors
+// This option renders the first occurrence of a named struct type used by several fields with an anchor,
+// e.g. `primary: &dbconfig`, and the following ones as a merge of it, e.g. `<<: *dbconfig` under `replica:`,
+// so that users learn they can share settings. Merge keys are decoded by yaml.v3 as if the keys were repeated.
+func WithYAMLAnchors() TemplateOption {
+	return func(o *Options) {
+		o.yamlAnchors = true
+	}
+}
+
+// WithSort
+// This option sets the order in which the fields of each struct are rendered.
+// By default (SortNone) fields keep their declaration order; SortAlpha sorts them alphabetically by key.
+// In both modes fields with an integer `order` tag are rendered first, lower values first.
+func WithSort(order SortOrder) TemplateOption {
+	return func(o *Options) {
+		o.sort = order
+	}
+}
+
+// WithNamingStrategy
+// This option sets how the keys of fields without a key name in their `yaml`, `json` or `kong` tags are derived
+// from their Go names, e.g. NamingSnakeCase renders MaxConnections as max_connections. The strategy applies to
+// the YAML, JSON, TOML and INI templates, the JSON Schema, the Markdown docs, BuildDefaultMap and the environment
+// variable names derived from key paths, including those of GenerateDockerComposeEnv, so that all outputs use the
+// same keys. Note that gopkg.in/yaml.v3 decodes untagged
+// fields from lowercased keys only. By default the field name is lowercased, see NamingLower.
+func WithNamingStrategy(strategy NamingStrategy) TemplateOption {
+	return func(o *Options) {
+		o.namingStrategy = strategy
+	}
+}
+
+// WithKongNameUnderscores
+// This option translates the dashes of the names given by Kong, with the `name` tag or the kong tag, to underscores
+// in keys, e.g. `name:"listen-addr"` becomes listen_addr. Environment variable names derived from key paths
+// always use underscores. By default such names are used as keys unchanged.
+func WithKongNameUnderscores() TemplateOption {
+	return func(o *Options) {
+		o.kongNameUnderscores = true
+	}
+}
+
+// WithSchemaURL
+// This option starts the generated YAML with a `# yaml-language-server: $schema=<url>` directive,
+// so that editors using the YAML language server, such as VS Code, validate and complete the file
+// against the given JSON Schema.
+func WithSchemaURL(url string) TemplateOption {
+	return func(o *Options) {
+		o.schemaURL = url
+	}
+}
+
+// WithSchemaVersion
+// This option records the version of the configuration struct in the generated YAML, as a
+// `# kongkit-schema: <version>` comment at the top, so that files generated for an older version can be detected.
+// The version is read back with ExtractSchemaVersion, and compared by CompareYAMLWithStruct and MergeTemplate.
+func WithSchemaVersion(version string) TemplateOption {
+	return func(o *Options) {
+		o.schemaVersion = version
+	}
+}
+
+// WithSchemaVersionKey
+// This option records the version of WithSchemaVersion as an explicit `schema_version: "<version>"` key at the top
+// of the generated YAML instead of a comment, e.g. for tools that do not keep comments. Note that strict decoders
+// reject the key unless the configuration struct has a matching field.
+func WithSchemaVersionKey() TemplateOption {
+	return func(o *Options) {
+		o.schemaVersionKey = true
+	}
+}
+
+// WithLeadingSeparator
+// This option starts the generated YAML with a `---` document separator, so that several templates can be
+// concatenated into a multi-document stream.
+func WithLeadingSeparator() TemplateOption {
+	return func(o *Options) {
+		o.leadingSeparator = true
+	}
+}
+
+// WithYAMLDirective
+// This option starts the generated YAML with a `%YAML 1.2` directive for strict parsers.
+// The directive is followed by the `---` separator it requires, as with WithLeadingSeparator.
+// Note that gopkg.in/yaml.v3 only accepts `%YAML 1.1` directives and fails to decode such templates.
+func WithYAMLDirective() TemplateOption {
+	return func(o *Options) {
+		o.yamlDirective = true
+	}
+}
+
+// WithTrailingNewlines
+// This option sets the number of newlines ending the generated YAML, e.g. 0 for consumers that require
+// the file to end without one. By default it ends with exactly one newline.
+func WithTrailingNewlines(n int) TemplateOption {
+	return func(o *Options) {
+		o.trailingNewlines = max(n, 0)
+	}
+}
+
+// WithJSONComments
+// This option renders the help text of each key as a `// comment` line above it in JSON templates,
+// producing JSONC for editors and parsers that accept comments.
+// By default JSON templates carry no help text, since JSON has no comments.
+func WithJSONComments() TemplateOption {
+	return func(o *Options) {
+		o.jsonComments = true
+	}
+}
+
+// WithEnvNamesFromPath
+// This option makes GenerateEnvTemplate derive the variable names of fields without an `env` tag
+// from their key path in SCREAMING_SNAKE_CASE, e.g. `server.http_port` becomes SERVER_HTTP_PORT.
+// By default such fields are left out of the template.
+func WithEnvNamesFromPath() TemplateOption {
+	return func(o *Options) {
+		o.envNamesFromPath = true
+	}
+}
+
+// WithInlineEnvComments
+// This option makes GenerateEnvTemplate write the help text at the end of the variable line,
+// e.g. `APP_PORT=8080 # The port number`, instead of on a comment line above it.
+// Shells and most .env loaders ignore such comments after unquoted values.
+func WithInlineEnvComments() TemplateOption {
+	return func(o *Options) {
+		o.inlineEnvComments = true
+	}
+}
+
+// WithHeadingLevel
+// This option sets the level of the headings of nested struct sections in Markdown documentation,
+// so that the reference fits into the heading structure of the surrounding page. The default level is 2.
+func WithHeadingLevel(level int) TemplateOption {
+	return func(o *Options) {
+		o.headingLevel = level
+	}
+}
+
+// WithFlatDocs
+// This option describes all fields in a single Markdown table with dotted key paths,
+// instead of one table per struct.
+func WithFlatDocs() TemplateOption {
+	return func(o *Options) {
+		o.flatDocs = true
+	}
+}
+
+// WithMapExampleProvider
+// This option provides the YAML example of the entries of map fields without a `map_example` tag,
+// e.g. to share examples between structs. The example is embedded verbatim, indented under the key of the field.
+// An empty example falls back to the default `key: value` placeholder.
+func WithMapExampleProvider(fn func(field reflect.StructField) string) TemplateOption {
+	return func(o *Options) {
+		o.mapExampleProvider = fn
+	}
+}
+
+// WithDefaultProvider
+// This option provides the default of fields without a `default`, `example` or `placeholder` tag from their type,
+// e.g. "info" for an application-specific LogLevel type whose zero value is not meaningful. The function is called
+// with the type of the field, with pointers dereferenced, and its result is used like a `default` tag when it
+// reports true. Otherwise the field falls back to its zero value, an example value or null as usual.
+// Actual values of GenerateYAMLFromValue take precedence over provided defaults.
+func WithDefaultProvider(fn func(t reflect.Type) (string, bool)) TemplateOption {
+	return func(o *Options) {
+		o.defaultProvider = fn
+	}
+}
+
+// WithCommentOutRemoved
+// This option makes MergeTemplate comment out the keys of the existing file that no longer exist
+// in the configuration struct, at the end of their mapping. By default such keys are kept as they are.
+func WithCommentOutRemoved() TemplateOption {
+	return func(o *Options) {
+		o.commentOutRemoved = true
+	}
+}
@@ -0,0 +1,96 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// SortOrder defines the order in which the fields of a struct are rendered.
+type SortOrder int
+
+const (
+	// SortNone renders fields in struct declaration order.
+	SortNone SortOrder = iota
+	// SortAlpha renders fields in alphabetical order of their keys.
+	SortAlpha
+)
+
+// Returns the indices of the fields of a struct in rendering order.
+// Fields with an `order` tag come first, lower values first; the remaining fields follow
+// in declaration order, or in alphabetical order of their keys with SortAlpha.
+// Fields with a `group` tag are then gathered by group, in the order the groups first appear,
+// after the fields without a group (or before them, with WithUngroupedLast).
+// Sorting applies to a single struct level, so nested structs are ordered independently.
+func (b *treeBuilder) fieldOrder(t reflect.Type, parent string) []int {
+	type orderedField struct {
+		index   int
+		key     string
+		order   int
+		ordered bool
+		group   string
+	}
+
+	fields := make([]orderedField, t.NumField())
+	for i := range fields {
+		field := t.Field(i)
+		fields[i] = orderedField{index: i, key: fieldKey(field, b.options.keyNaming(), b.keyTags...), group: field.Tag.Get("group")}
+
+		if tagValue := field.Tag.Get("order"); tagValue != "" {
+			order, err := strconv.Atoi(tagValue)
+			if err != nil {
+				b.errs = append(b.errs, fmt.Errorf("invalid order tag %q on field %s of %s", tagValue, field.Name, pathOrRoot(parent)))
+				continue
+			}
+			fields[i].order, fields[i].ordered = order, true
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		fa, fb := fields[i], fields[j]
+		if fa.ordered != fb.ordered {
+			return fa.ordered
+		}
+		if fa.ordered && fa.order != fb.order {
+			return fa.order < fb.order
+		}
+		if b.options.sort == SortAlpha {
+			return fa.key < fb.key
+		}
+		return false
+	})
+
+	// Groups are ranked by their first appearance, keeping the order of the fields within each group
+	groupRank := make(map[string]int)
+	for _, field := range fields {
+		if _, ok := groupRank[field.group]; field.group != "" && !ok {
+			groupRank[field.group] = len(groupRank) + 1
+		}
+	}
+	if len(groupRank) > 0 {
+		rank := func(field orderedField) int {
+			if field.group == "" && b.options.ungroupedLast {
+				return len(groupRank) + 1
+			}
+			return groupRank[field.group]
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return rank(fields[i]) < rank(fields[j])
+		})
+	}
+
+	indices := make([]int, len(fields))
+	for i, field := range fields {
+		indices[i] = field.index
+	}
+	return indices
+}
+
+// Returns a printable path, naming the root struct for an empty path.
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return path
+}
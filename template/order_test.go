@@ -0,0 +1,64 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderConfig struct {
+	Zeta  string `yaml:"zeta" default:"z"`
+	Alpha string `yaml:"alpha" default:"a"`
+	Name  string `yaml:"name" default:"app" order:"1"`
+	Meta  struct {
+		Build   int    `yaml:"build" default:"42"`
+		Version string `yaml:"version" default:"1.0" order:"1"`
+		Author  string `yaml:"author" default:"me"`
+	} `yaml:"meta"`
+	Beta string `yaml:"beta" default:"b"`
+	ID   string `yaml:"id" default:"main" order:"0"`
+}
+
+// Test YAML generation in declaration order with order tags.
+func TestGenerateYAMLTemplate_SortNone(t *testing.T) {
+	expected := `id: "main"
+name: "app"
+zeta: "z"
+alpha: "a"
+meta:
+  version: "1.0"
+  build: 42
+  author: "me"
+beta: "b"
+`
+
+	assert.Equal(t, expected, GenerateYAMLTemplate(orderConfig{}))
+}
+
+// Test YAML generation in alphabetical order with order tags.
+func TestGenerateYAMLTemplate_SortAlpha(t *testing.T) {
+	expected := `id: "main"
+name: "app"
+alpha: "a"
+beta: "b"
+meta:
+  version: "1.0"
+  author: "me"
+  build: 42
+zeta: "z"
+`
+
+	assert.Equal(t, expected, GenerateYAMLTemplate(orderConfig{}, WithSort(SortAlpha)))
+}
+
+// Test that invalid order tags are reported.
+func TestGenerateYAMLTemplateE_InvalidOrder(t *testing.T) {
+	cfg := struct {
+		Meta struct {
+			Name string `yaml:"name" order:"first"`
+		} `yaml:"meta"`
+	}{}
+	_, err := GenerateYAMLTemplateE(cfg)
+
+	assert.EqualError(t, err, `invalid order tag "first" on field Name of meta`)
+}
@@ -0,0 +1,138 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// GenerateYAMLDiffTemplate generates a minimal YAML override file from a populated configuration struct:
+// only the fields whose value differs from their `default` tag, or from the zero value of their type when
+// they have no default, are rendered with their actual value. Nested structs are rendered when one of their
+// fields differs, and non-empty slices of structs are rendered with all their elements in full.
+// This is the inverse of GenerateYAMLTemplate: applying the override on top of the defaults yields the struct.
+func GenerateYAMLDiffTemplate(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+	options.fromValue = true
+	nodes, _ := buildConfigTree(cfg, options)
+
+	w := &yamlWriter{
+		options:   options,
+		maxLength: make(map[alignGroup]int),
+	}
+	w.writeNodes(wrapRootKey(overrideNodes(nodes, options), options.rootKey), 0, "")
+	return generateYAMLWithAlignment(w.lines, w.maxLength, options)
+}
+
+// Returns copies of the nodes whose value differs from their default, rendered with their actual value.
+func overrideNodes(nodes []*Node, options *Options) []*Node {
+	var result []*Node
+	for _, node := range nodes {
+		v := node.fieldValue
+		if !v.IsValid() {
+			continue
+		}
+		override := *node
+
+		switch node.Kind {
+		case KindStruct:
+			override.Children = overrideNodes(node.Children, options)
+			if len(override.Children) == 0 {
+				continue
+			}
+
+		case KindStructList:
+			if v.Len() == 0 {
+				continue
+			}
+			// Lists replace the default entirely, so every element is rendered in full
+			override.elements = make([][]*Node, v.Len())
+			for i := range override.elements {
+				override.elements[i], _ = buildConfigTree(v.Index(i).Interface(), options)
+			}
+
+		default:
+			if matchesDefault(node) {
+				continue
+			}
+			setActualValue(&override)
+		}
+		result = append(result, &override)
+	}
+	return result
+}
+
+// Reports whether the value of a field equals its default, or the zero value of its type without a default.
+// Empty slices and maps are equal to each other whether they are nil or not.
+func matchesDefault(node *Node) bool {
+	v := node.fieldValue
+	expected := reflect.Zero(v.Type())
+	if node.Default != "" {
+		parsed, err := parseDefault(v.Type(), node.Default)
+		if err != nil {
+			return false
+		}
+		expected = parsed
+	}
+
+	if kind := v.Kind(); (kind == reflect.Slice || kind == reflect.Map) && v.Len() == 0 && expected.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(v.Interface(), expected.Interface())
+}
+
+// Replaces the rendered value of a node with the actual value of its field, including zero values,
+// which templates generated from a value otherwise replace with the default.
+func setActualValue(node *Node) {
+	v := node.fieldValue
+	node.fromExample = false
+
+	switch node.Kind {
+	case KindList:
+		node.items = nil
+		for i := 0; i < v.Len(); i++ {
+			node.items = append(node.items, actualScalar(v.Index(i), true))
+		}
+		// An empty list is written as [] in flow style
+		node.fromValue, node.flow = true, node.flow || len(node.items) == 0
+
+	case KindMap:
+		node.entries = nil
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			node.entries = append(node.entries, mapEntry{
+				key:   fmt.Sprint(key.Interface()),
+				value: actualScalar(v.MapIndex(key), true),
+			})
+		}
+		if len(node.entries) == 0 {
+			node.mapExample = "{}"
+		}
+
+	default:
+		if isBytesType(v.Type()) {
+			node.value = scalar{text: bytesText(node.field, v.Bytes()), quoted: true}
+			break
+		}
+		node.value = actualScalar(v, false)
+	}
+}
+
+// Returns the scalar of an actual value. Strings and text scalars are quoted, as well as durations
+// in lists and maps, like the items rendered from defaults.
+func actualScalar(v reflect.Value, quoteDurations bool) scalar {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return scalar{null: true}
+		}
+		v = v.Elem()
+	}
+	if text, ok := marshalText(v); ok {
+		return scalar{text: text, quoted: true}
+	}
+	quoted := isStringType(v.Type()) || (quoteDurations && v.Type() == durationType)
+	return scalar{text: fmt.Sprint(v.Interface()), quoted: quoted}
+}
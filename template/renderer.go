@@ -0,0 +1,50 @@
+package template
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Renderer renders a scalar value for a field of a registered type.
+// It receives the struct field and its default (or placeholder) tag value and returns
+// the literal YAML value text, including any quoting, and an optional comment that is
+// appended after the field's help text.
+type Renderer func(field reflect.StructField, defaultVal string) (value string, comment string)
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[reflect.Type]Renderer{}
+)
+
+// RegisterRenderer registers a custom renderer for the given type.
+// Registered renderers take precedence over the built-in handling of the type's kind,
+// so the generator never recurses into the internals of a registered struct type.
+// Registering a renderer for a type that already has one replaces it, and passing a nil fn removes it.
+// Fields of a pointer to the type use its renderer as well, unless the pointer type has its own. Slices of
+// the type are lists whose items are rendered one by one from the comma-separated default, each call receiving
+// the slice field, or once with an empty value without a default; the comment is written once for the whole list.
+// It is safe to call RegisterRenderer concurrently, e.g. from init functions of several packages.
+func RegisterRenderer(t reflect.Type, fn Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	if fn == nil {
+		delete(renderers, t)
+		return
+	}
+	renderers[t] = fn
+}
+
+// lookupRenderer returns the renderer registered for the given type, if any.
+// Pointers fall back to the renderer of their element type.
+func lookupRenderer(t reflect.Type) (Renderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	if fn, ok := renderers[t]; ok {
+		return fn, true
+	}
+	if t.Kind() == reflect.Ptr {
+		fn, ok := renderers[t.Elem()]
+		return fn, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,94 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type byteSize struct {
+	Bytes uint64
+}
+
+type logLevel int
+
+// Test that registered renderers take precedence over the built-in kind handling.
+func TestGenerateYAMLTemplate_RegisteredRenderer(t *testing.T) {
+	RegisterRenderer(reflect.TypeOf(byteSize{}), func(field reflect.StructField, defaultVal string) (string, string) {
+		if defaultVal == "" {
+			defaultVal = "0B"
+		}
+		return fmt.Sprintf(`"%s"`, defaultVal), "(e.g. 512KB, 10MB)"
+	})
+	RegisterRenderer(reflect.TypeOf(logLevel(0)), func(field reflect.StructField, defaultVal string) (string, string) {
+		return strings.ToLower(defaultVal), ""
+	})
+	defer RegisterRenderer(reflect.TypeOf(byteSize{}), nil)
+	defer RegisterRenderer(reflect.TypeOf(logLevel(0)), nil)
+
+	cfg := struct {
+		MaxBody  byteSize `yaml:"max_body" default:"10MB" help:"Maximum request body"`
+		Buffer   byteSize `yaml:"buffer"`
+		LogLevel logLevel `yaml:"log_level" default:"INFO" help:"Log level"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `max_body: "10MB" # Maximum request body (e.g. 512KB, 10MB)
+buffer: "0B"     # (e.g. 512KB, 10MB)
+log_level: info  # Log level
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test that pointers and slices of a registered type are rendered by its renderer.
+func TestGenerateYAMLTemplate_RegisteredRendererElements(t *testing.T) {
+	RegisterRenderer(reflect.TypeOf(byteSize{}), func(field reflect.StructField, defaultVal string) (string, string) {
+		if defaultVal == "" {
+			defaultVal = "0B"
+		}
+		return fmt.Sprintf(`"%s"`, defaultVal), "(e.g. 512KB, 10MB)"
+	})
+	defer RegisterRenderer(reflect.TypeOf(byteSize{}), nil)
+
+	cfg := struct {
+		MaxBody *byteSize  `yaml:"max_body" default:"10MB"`
+		Limits  []byteSize `yaml:"limits" default:"1MB, 2MB"`
+		Buffers []byteSize `yaml:"buffers"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `max_body: "10MB" # (e.g. 512KB, 10MB)
+limits:          # (e.g. 512KB, 10MB)
+  - "1MB"
+  - "2MB"
+buffers:         # (e.g. 512KB, 10MB)
+  - "0B"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test that renderers can be registered concurrently.
+func TestRegisterRenderer_Concurrent(t *testing.T) {
+	type custom struct{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RegisterRenderer(reflect.TypeOf(custom{}), func(reflect.StructField, string) (string, string) {
+				return "custom", ""
+			})
+			_ = GenerateYAMLTemplate(struct{ Field custom }{})
+		}()
+	}
+	wg.Wait()
+	defer RegisterRenderer(reflect.TypeOf(custom{}), nil)
+
+	assert.Equal(t, "field: custom\n", GenerateYAMLTemplate(struct{ Field custom }{}))
+}
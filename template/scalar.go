@@ -0,0 +1,208 @@
+package template
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	urlType             = reflect.TypeOf(url.URL{})
+)
+
+// scalarExamples holds the example values of common standard library scalar types,
+// rendered as examples for the fields of these types without a value.
+var scalarExamples = map[reflect.Type]string{
+	urlType:                          "https://example.com",
+	reflect.TypeOf(net.IP{}):         "10.0.0.1",
+	reflect.TypeOf(netip.Addr{}):     "10.0.0.1",
+	reflect.TypeOf(netip.AddrPort{}): "10.0.0.1:8080",
+	reflect.TypeOf(netip.Prefix{}):   "10.0.0.0/8",
+}
+
+// Reports whether a type is represented by a plain text value, i.e. whether the type
+// or a pointer to it implements encoding.TextUnmarshaler or encoding.TextMarshaler.
+// url.URL, which only implements the binary interfaces, is a text scalar as well.
+func isTextScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(textMarshalerType) || t == urlType
+}
+
+// Returns the example value of a standard library scalar type, see scalarExamples.
+func scalarExample(t reflect.Type) (string, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	example, ok := scalarExamples[t]
+	return example, ok
+}
+
+// Returns the text form of a field value via encoding.TextMarshaler, used when no default is given.
+// Nil pointers are replaced by the zero value of their element type; empty text is reported as absent.
+func zeroValueText(v reflect.Value) (string, bool) {
+	if !v.IsValid() {
+		return "", false
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+	text, ok := marshalText(v)
+	return text, ok && text != ""
+}
+
+// Returns the text representation of a field value when generating from a value.
+// It reports false for zero values and when the generator works from tags only.
+func valueText(v reflect.Value, options *Options) (string, bool) {
+	if !options.fromValue || !v.IsValid() || v.IsZero() {
+		return "", false
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if text, ok := marshalText(v); ok {
+		return text, true
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+// Returns the items of a non-empty slice of scalars when generating from a value.
+func sliceValueItems(v reflect.Value, options *Options) ([]scalar, bool) {
+	if !options.fromValue || !v.IsValid() || v.Len() == 0 {
+		return nil, false
+	}
+
+	items := make([]scalar, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if text, ok := marshalText(item); ok {
+			items = append(items, scalar{text: text, quoted: true})
+		} else if item.Kind() == reflect.String {
+			items = append(items, scalar{text: item.String(), quoted: true})
+		} else if item.Type() == durationType {
+			items = append(items, scalar{text: fmt.Sprint(item.Interface()), quoted: true})
+		} else {
+			items = append(items, scalar{text: fmt.Sprint(item.Interface())})
+		}
+	}
+	return items, true
+}
+
+// Calls MarshalText on a value if its type implements encoding.TextMarshaler.
+// Values whose pointer implements the interface are copied to an addressable value first.
+func marshalText(v reflect.Value) (string, bool) {
+	if !v.IsValid() {
+		return "", false
+	}
+
+	var marshaler encoding.TextMarshaler
+	switch {
+	case v.Type() == urlType:
+		u := v.Interface().(url.URL)
+		return u.String(), true
+	case v.Type().Implements(textMarshalerType):
+		marshaler = v.Interface().(encoding.TextMarshaler)
+	case reflect.PointerTo(v.Type()).Implements(textMarshalerType):
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		marshaler = ptr.Interface().(encoding.TextMarshaler)
+	default:
+		return "", false
+	}
+
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return "", false
+	}
+	return string(text), true
+}
+
+// Reports whether a type is a byte slice. Byte slices are rendered as strings rather than as lists of numbers,
+// see bytesText.
+func isBytesType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !isTextScalar(t)
+}
+
+// Returns the text of the value of a byte slice field: the bytes as they are, or encoded with standard base64
+// if the field is tagged with `encoding:"base64"` or `format:"base64"`.
+func bytesText(field reflect.StructField, data []byte) string {
+	if isBase64Field(field) {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// Reports whether the value of a byte slice field is encoded with base64.
+func isBase64Field(field reflect.StructField) bool {
+	return field.Tag.Get("encoding") == "base64" || field.Tag.Get("format") == "base64"
+}
+
+// Returns whether a scalar is quoted, overridden by the `format` tag of its field:
+// "quoted" always quotes the value, and "plain" never does.
+func formatQuoted(field reflect.StructField, quoted bool) bool {
+	switch field.Tag.Get("format") {
+	case "quoted":
+		return true
+	case "plain":
+		return false
+	}
+	return quoted
+}
+
+// Returns the text of an integer in hexadecimal, e.g. 0xff, for fields tagged with `format:"hex"`.
+// Texts that are not decimal integers, e.g. placeholders, are returned as they are.
+func formatHex(t reflect.Type, text string) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value, err := strconv.ParseInt(text, 10, 64); err == nil && value < 0 {
+			return "-0x" + strconv.FormatUint(uint64(-value), 16)
+		} else if err == nil {
+			return "0x" + strconv.FormatInt(value, 16)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return "0x" + strconv.FormatUint(value, 16)
+		}
+	}
+	return text
+}
+
+// Returns the decimal text of an integer written in hexadecimal by formatHex, e.g. 255 for 0xff,
+// for formats that have no hexadecimal literals. Other texts are reported as not hexadecimal.
+func hexDecimal(text string) (string, bool) {
+	if !strings.HasPrefix(strings.TrimPrefix(text, "-"), "0x") {
+		return "", false
+	}
+	if strings.HasPrefix(text, "-") {
+		value, err := strconv.ParseInt(text, 0, 64)
+		return strconv.FormatInt(value, 10), err == nil
+	}
+	value, err := strconv.ParseUint(text, 0, 64)
+	return strconv.FormatUint(value, 10), err == nil
+}
+
+// Reports whether values of a type are strings: string kinds and text scalars.
+func isStringType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String || isTextScalar(t)
+}
@@ -0,0 +1,161 @@
+package template
+
+import (
+	"encoding/json"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Test that types implementing encoding.TextUnmarshaler are rendered as quoted scalars.
+func TestGenerateYAMLTemplate_TextUnmarshaler(t *testing.T) {
+	cfg := struct {
+		Listen  netip.Addr       `yaml:"listen" default:"127.0.0.1" help:"Listen address"`
+		Gateway *netip.Addr      `yaml:"gateway" placeholder:"10.0.0.1"`
+		Subnet  netip.Prefix     `yaml:"subnet"`
+		Since   time.Time        `yaml:"since" default:"2024-01-01T00:00:00Z"`
+		Peers   []netip.AddrPort `yaml:"peers" default:"10.0.0.2:80"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `listen: "127.0.0.1"           # Listen address
+gateway: "10.0.0.1"
+subnet: "10.0.0.0/8"          # (example)
+since: "2024-01-01T00:00:00Z"
+peers:
+  - "10.0.0.2:80"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test that URLs and IP addresses of the standard library are rendered as quoted scalars,
+// with example values when they have no default.
+func TestGenerateYAMLTemplate_StandardScalars(t *testing.T) {
+	type Config struct {
+		Endpoint url.URL      `yaml:"endpoint" default:"https://api.example.org/v1"`
+		Proxy    *url.URL     `yaml:"proxy"`
+		Address  net.IP       `yaml:"address"`
+		Listen   netip.Addr   `yaml:"listen" placeholder:"0.0.0.0"`
+		Subnet   netip.Prefix `yaml:"subnet"`
+		Backend  netip.AddrPort
+	}
+	expected := `endpoint: "https://api.example.org/v1"
+proxy: "https://example.com"           # (example)
+address: "10.0.0.1"                    # (example)
+listen: "0.0.0.0"
+subnet: "10.0.0.0/8"                   # (example)
+backend: "10.0.0.1:8080"               # (example)
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}))
+
+	proxy, err := url.Parse("http://proxy.local:3128")
+	require.NoError(t, err)
+	yamlTemplate := GenerateYAMLFromValue(Config{Proxy: proxy, Address: net.ParseIP("192.168.0.1")})
+	assert.Contains(t, yamlTemplate, `proxy: "http://proxy.local:3128"`)
+	assert.Contains(t, yamlTemplate, `address: "192.168.0.1"`)
+
+	data, err := GenerateJSONSchema(Config{})
+	require.NoError(t, err)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "format": "uri", "default": "https://api.example.org/v1"}, properties["endpoint"])
+	assert.Equal(t, map[string]any{"type": "string"}, properties["subnet"])
+}
+
+// Test that values implementing encoding.TextMarshaler are marshaled when generating from a value.
+func TestGenerateYAMLFromValue(t *testing.T) {
+	type Config struct {
+		Host   string     `yaml:"host" default:"localhost" help:"The hostname"`
+		Port   int        `yaml:"port" default:"8080" help:"The port number"`
+		Listen netip.Addr `yaml:"listen" default:"127.0.0.1" help:"Listen address"`
+		Tags   []string   `yaml:"tags" default:"a,b"`
+	}
+	cfg := Config{
+		Port:   9090,
+		Listen: netip.MustParseAddr("192.168.0.1"),
+		Tags:   []string{"x", "y"},
+	}
+	yamlTemplate := GenerateYAMLFromValue(cfg)
+
+	expected := `host: "localhost"     # The hostname
+port: 9090            # The port number
+listen: "192.168.0.1" # Listen address
+tags:
+  - "x"
+  - "y"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+type marshalOnlyLevel int
+
+func (l marshalOnlyLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"info", "warn", "error"}[l]), nil
+}
+
+// Test that types implementing encoding.TextMarshaler are rendered via MarshalText.
+func TestGenerateYAMLTemplate_TextMarshaler(t *testing.T) {
+	cfg := struct {
+		Address net.IP            `yaml:"address" default:"10.0.0.1" help:"Bind address"`
+		Mask    net.IP            `yaml:"mask"`
+		Level   marshalOnlyLevel  `yaml:"level" help:"Log level"`
+		Backup  *marshalOnlyLevel `yaml:"backup"`
+	}{
+		Mask: net.IPv4(255, 255, 255, 0),
+	}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `address: "10.0.0.1"   # Bind address
+mask: "255.255.255.0"
+level: "info"         # Log level
+backup: "info"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test that the format tag changes the presentation of scalars.
+func TestGenerateYAMLTemplate_Format(t *testing.T) {
+	type Config struct {
+		Mask    int      `yaml:"mask" default:"255" format:"hex"`
+		Flags   []uint   `yaml:"flags" default:"16,0x20" format:"hex"`
+		Key     []byte   `yaml:"key" default:"secret" format:"base64"`
+		Version string   `yaml:"version" default:"1.20" format:"plain"`
+		Port    int      `yaml:"port" default:"8080" format:"quoted"`
+		Hosts   []string `yaml:"hosts" default:"a,b" format:"plain"`
+	}
+	expected := `mask: 0xff
+flags:
+  - 0x10
+  - 0x20
+key: "c2VjcmV0"
+version: 1.20
+port: "8080"
+hosts:
+  - a
+  - b
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}))
+
+	yamlTemplate := GenerateYAMLFromValue(Config{Mask: 4096, Key: []byte{0xde, 0xad}})
+	assert.Contains(t, yamlTemplate, "mask: 0x1000")
+	assert.Contains(t, yamlTemplate, `key: "3q0="`)
+
+	var decoded struct {
+		Mask  int    `yaml:"mask"`
+		Flags []uint `yaml:"flags"`
+		Port  string `yaml:"port"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(expected), &decoded))
+	assert.Equal(t, 255, decoded.Mask)
+	assert.Equal(t, []uint{16, 32}, decoded.Flags)
+}
@@ -0,0 +1,295 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// jsonSchemaDraft is the dialect of the generated JSON Schemas.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the time.Duration format accepted by time.ParseDuration, e.g. "1h30m".
+const durationPattern = `^[-+]?(0|([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+
+// GenerateJSONSchema generates a JSON Schema (draft 2020-12) describing the configuration files of a struct,
+// for editor completion and CI validation of the templates generated by this package.
+// Property names are resolved from the `yaml` and `kong` tags like in GenerateYAMLTemplate. Descriptions
+// come from the `help` tag followed by the unit of the `unit` tag, defaults from the `default` tag typed by the field kind, allowed values from
+// the `enum` tag and bounds from the `min` and `max` tags; required fields are listed in `required`.
+// Fields with a `deprecated` tag are annotated with `deprecated` and their description is prefixed with the message.
+// Named struct types used more than once are described once in `$defs` and referenced.
+// Pointer fields and fields without a default also accept null, which the templates render for unknown values.
+// An error is reported for defaults and enum values that do not match the type of their field.
+func GenerateJSONSchema(cfg interface{}, opts ...TemplateOption) ([]byte, error) {
+	options := applyTemplateOptions(opts)
+
+	t, _, err := configStruct(cfg)
+	if err != nil {
+		return nil, err
+	}
+	g := &schemaGenerator{
+		naming: options.keyNaming(),
+		uses:   make(map[reflect.Type]int),
+		names:  make(map[reflect.Type]string),
+		defs:   make(map[string]any),
+	}
+	g.countUses(t, make(map[reflect.Type]bool))
+
+	schema := g.objectSchema(t)
+	schema["$schema"] = jsonSchemaDraft
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return data, errors.Join(g.errs...)
+}
+
+// schemaGenerator builds the schemas of the types of a configuration struct.
+type schemaGenerator struct {
+	// naming derives the keys of the fields without a key name in their tags, see WithNamingStrategy.
+	naming keyNaming
+	// uses counts the fields of each named struct type, to decide which types are described in $defs.
+	uses map[reflect.Type]int
+	// names holds the $defs names of the types described in $defs.
+	names map[reflect.Type]string
+	defs  map[string]any
+	errs  []error
+}
+
+// Counts the fields of each named struct type reachable from a type. Types are descended into only once.
+func (g *schemaGenerator) countUses(t reflect.Type, visited map[reflect.Type]bool) {
+	t = schemaElem(t)
+	if !isNestedStruct(t) || visited[t] {
+		return
+	}
+	visited[t] = true
+	walkStruct(t, "", keyTags, g.naming, func(f structField) bool {
+		elem := schemaElem(f.Type)
+		if isNestedStruct(elem) && elem.Name() != "" {
+			g.uses[elem]++
+		}
+		g.countUses(f.Type, visited)
+		return false
+	})
+}
+
+// Returns the type described by the schema of a field: pointers are dereferenced,
+// and slices and maps are described by their element type.
+func schemaElem(t reflect.Type) reflect.Type {
+	for {
+		if t.Kind() == reflect.Ptr && isNestedStruct(t.Elem()) {
+			return t
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			if t.Kind() == reflect.Ptr || (!isTextScalar(t) && !isBytesType(t)) {
+				t = t.Elem()
+				continue
+			}
+		}
+		return t
+	}
+}
+
+// Returns the schema of a struct type, or a reference to its definition if the type is used more than once.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	if g.uses[t] < 2 {
+		return g.objectSchema(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; g.defs[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		g.names[t] = name
+		// Reserve the name before describing the type, so that recursive types refer to it
+		g.defs[name] = map[string]any{}
+		g.defs[name] = g.objectSchema(t)
+	}
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+// Returns the object schema describing the fields of a struct type.
+func (g *schemaGenerator) objectSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	walkStruct(t, "", keyTags, g.naming, func(f structField) bool {
+		properties[f.Key] = g.fieldSchema(f)
+		if isKongRequired(f.StructField) {
+			required = append(required, f.Key)
+		}
+		return false
+	})
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Returns the schema of a field, annotated with its help text, default, enum and bounds.
+func (g *schemaGenerator) fieldSchema(f structField) map[string]any {
+	schema := g.typeSchema(f.Type)
+	if isNullableField(f.StructField) {
+		schema = nullableSchema(schema)
+	}
+	help := joinComment(joinComment(deprecationComment(f.StructField), f.Tag.Get("help")), unitComment(f.StructField, UnitDisplayWhenTagged))
+	if help != "" {
+		schema["description"] = help
+	}
+	if f.Tag.Get("deprecated") != "" {
+		schema["deprecated"] = true
+	}
+
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isBytesType(t) && isBase64Field(f.StructField) {
+		schema["contentEncoding"] = "base64"
+	}
+
+	if defaultValue := f.Tag.Get("default"); defaultValue != "" {
+		switch {
+		case isBytesType(t):
+			schema["default"] = bytesText(f.StructField, []byte(defaultValue))
+		case t.Kind() == reflect.Slice && !isTextScalar(t):
+			if !isNestedStruct(t.Elem()) {
+				var items []any
+				for _, item := range strings.Split(defaultValue, ",") {
+					items = append(items, g.typedValue(f, t.Elem(), strings.TrimSpace(item)))
+				}
+				schema["default"] = items
+			}
+		case t.Kind() == reflect.Map:
+			pairs, err := parseMapDefault(defaultValue)
+			if err != nil {
+				g.errs = append(g.errs, fmt.Errorf("invalid default of %s: %w", f.Path, err))
+			}
+			entries := map[string]any{}
+			for _, pair := range pairs {
+				entries[pair.key] = g.typedValue(f, t.Elem(), pair.value)
+			}
+			schema["default"] = entries
+		case isNestedStruct(t):
+		default:
+			schema["default"] = g.typedValue(f, t, defaultValue)
+		}
+	}
+
+	if enum := kongTagValue(f.StructField, "enum"); enum != "" {
+		var values []any
+		for _, value := range strings.Split(enum, ",") {
+			values = append(values, g.typedValue(f, t, strings.TrimSpace(value)))
+		}
+		schema["enum"] = values
+	}
+
+	if minValue := f.Tag.Get("min"); minValue != "" {
+		schema["minimum"] = g.typedValue(f, t, minValue)
+	}
+	if maxValue := f.Tag.Get("max"); maxValue != "" {
+		schema["maximum"] = g.typedValue(f, t, maxValue)
+	}
+	return schema
+}
+
+// Reports whether the templates may render a field as null: pointers, and fields without a default
+// other than structs, slices and maps, which are rendered as objects, lists of examples or empty values,
+// and text scalars with an example value.
+func isNullableField(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		return true
+	}
+	if field.Tag.Get("default") != "" {
+		return false
+	}
+	if isTextScalar(t) {
+		// Text scalars without a default are rendered with an example of their type when there is one
+		_, ok := scalarExample(t)
+		return !ok
+	}
+	if isBytesType(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return false
+	}
+	return true
+}
+
+// Returns a schema that also accepts null: null is added to the type, and references are combined with it.
+func nullableSchema(schema map[string]any) map[string]any {
+	if ref, ok := schema["$ref"]; ok {
+		return map[string]any{"anyOf": []any{map[string]any{"$ref": ref}, map[string]any{"type": "null"}}}
+	}
+	if schemaType, ok := schema["type"].(string); ok {
+		schema["type"] = []any{schemaType, "null"}
+	}
+	return schema
+}
+
+// Converts a tag value to the Go value of the given type, reporting values that do not match the type.
+func (g *schemaGenerator) typedValue(f structField, t reflect.Type, text string) any {
+	value := typedDefault(t, text)
+	if _, isString := value.(string); isString && !isStringType(t) && t != durationType {
+		g.errs = append(g.errs, fmt.Errorf("invalid value %q for %s of type %s", text, f.Path, f.Type))
+	}
+	return value
+}
+
+// Returns the schema of a Go type.
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Ptr {
+		// Pointers to structs are single optional values, whose fields are not described, see isNestedStruct
+		if isNestedStruct(t.Elem()) {
+			return map[string]any{"type": "object"}
+		}
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	case t == urlType:
+		return map[string]any{"type": "string", "format": "uri"}
+	case isTextScalar(t), isBytesType(t):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
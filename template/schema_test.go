@@ -0,0 +1,140 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type schemaEndpoint struct {
+	URL     string        `yaml:"url" default:"http://localhost" help:"Endpoint URL"`
+	Timeout time.Duration `yaml:"timeout" default:"5s"`
+}
+
+type schemaConfig struct {
+	Host     string            `yaml:"host" default:"localhost" help:"The hostname"`
+	Port     int               `yaml:"port" default:"8080" min:"1" max:"65535" required:""`
+	LogLevel string            `yaml:"log_level" default:"info" enum:"debug,info,warn"`
+	Ratio    float64           `yaml:"ratio" default:"0.5"`
+	Tags     []string          `yaml:"tags" default:"a,b"`
+	Labels   map[string]string `yaml:"labels"`
+	Weights  map[string]int    `yaml:"weights" default:"a=1"`
+	Primary  schemaEndpoint    `yaml:"primary" help:"Primary endpoint"`
+	Backups  []schemaEndpoint  `yaml:"backups"`
+	Ignored  string            `yaml:"-"`
+}
+
+// Test JSON Schema generation of types, annotations and definitions.
+func TestGenerateJSONSchema(t *testing.T) {
+	data, err := GenerateJSONSchema(schemaConfig{})
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]any)
+
+	assert.Equal(t, jsonSchemaDraft, schema["$schema"])
+	assert.Equal(t, []any{"port"}, schema["required"])
+	assert.NotContains(t, properties, "ignored")
+	assert.Equal(t, map[string]any{"type": "string", "default": "localhost", "description": "The hostname"}, properties["host"])
+	assert.Equal(t, map[string]any{"type": "integer", "default": 8080.0, "minimum": 1.0, "maximum": 65535.0}, properties["port"])
+	assert.Equal(t, []any{"debug", "info", "warn"}, properties["log_level"].(map[string]any)["enum"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "default": []any{"a", "b"}}, properties["tags"])
+	assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}, properties["labels"])
+	assert.Equal(t, map[string]any{"a": 1.0}, properties["weights"].(map[string]any)["default"])
+	assert.Equal(t, map[string]any{"$ref": "#/$defs/schemaEndpoint", "description": "Primary endpoint"}, properties["primary"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/schemaEndpoint"}}, properties["backups"])
+
+	endpoint := schema["$defs"].(map[string]any)["schemaEndpoint"].(map[string]any)
+	timeout := endpoint["properties"].(map[string]any)["timeout"].(map[string]any)
+	assert.Equal(t, "string", timeout["type"])
+	assert.Equal(t, durationPattern, timeout["pattern"])
+}
+
+// Test that the unit of a field is appended to its description.
+func TestGenerateJSONSchema_Unit(t *testing.T) {
+	cfg := struct {
+		Timeout int `yaml:"timeout" units:"seconds" help:"Request timeout"`
+		Size    int `yaml:"size" unit:"MB"`
+	}{}
+	data, err := GenerateJSONSchema(cfg)
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, "Request timeout (seconds)", properties["timeout"].(map[string]any)["description"])
+	assert.Equal(t, "(MB)", properties["size"].(map[string]any)["description"])
+}
+
+// Test JSON Schema generation of byte slices as strings.
+func TestGenerateJSONSchema_Bytes(t *testing.T) {
+	cfg := struct {
+		Token []byte `yaml:"token" default:"abc123"`
+		Key   []byte `yaml:"key" default:"secret" encoding:"base64"`
+	}{}
+	data, err := GenerateJSONSchema(cfg)
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "default": "abc123"}, properties["token"])
+	assert.Equal(t, map[string]any{"type": "string", "contentEncoding": "base64", "default": "c2VjcmV0"}, properties["key"])
+}
+
+// Test that defaults which do not match the type of their field are reported.
+func TestGenerateJSONSchema_InvalidDefault(t *testing.T) {
+	cfg := struct {
+		Port int `yaml:"port" default:"http"`
+	}{}
+	_, err := GenerateJSONSchema(cfg)
+
+	assert.EqualError(t, err, `invalid value "http" for port of type int`)
+}
+
+// Test that the generated YAML template is valid against the generated schema, including the fields
+// rendered as null: fields without a default and pointer fields.
+func TestGenerateJSONSchema_ValidatesTemplate(t *testing.T) {
+	type nullableConfig struct {
+		Retries  int             `yaml:"retries"`
+		Timeout  time.Duration   `yaml:"timeout"`
+		Enabled  bool            `yaml:"enabled"`
+		Name     *string         `yaml:"name"`
+		Weight   *int            `yaml:"weight" default:"3"`
+		Key      []byte          `yaml:"key"`
+		Fallback *schemaEndpoint `yaml:"fallback"`
+		Mirror   *schemaEndpoint `yaml:"mirror"`
+		Proxy    *struct {
+			URL string `yaml:"url"`
+		} `yaml:"proxy"`
+	}
+
+	for _, cfg := range []any{schemaConfig{}, nullableConfig{}} {
+		data, err := GenerateJSONSchema(cfg)
+		require.NoError(t, err)
+		schemaDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		compiler := jsonschema.NewCompiler()
+		require.NoError(t, compiler.AddResource("config.schema.json", schemaDoc))
+		schema, err := compiler.Compile("config.schema.json")
+		require.NoError(t, err)
+
+		// Convert the YAML template to the JSON data model expected by the validator
+		var document any
+		require.NoError(t, yaml.Unmarshal([]byte(GenerateYAMLTemplate(cfg)), &document))
+		documentJSON, err := json.Marshal(document)
+		require.NoError(t, err)
+		instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(documentJSON))
+		require.NoError(t, err)
+
+		assert.NoError(t, schema.Validate(instance), "%T", cfg)
+	}
+}
@@ -3,6 +3,7 @@ package template
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -10,145 +11,531 @@ import (
 type FieldInfo struct {
 	Line string
 	Help string
+	// Path is the dot-separated path of the field whose key is on the line, empty for other lines.
+	Path string
+	// Default is the value of the `default` tag of the field whose key is on the line.
+	Default string
+
+	// group identifies the block the line belongs to; comments are aligned within a group only.
+	group alignGroup
+}
+
+// alignGroup keys an alignment block by its parent path, indentation depth and field group,
+// so sibling fields align their comments to each other but not to their parent's fields or to other groups.
+type alignGroup struct {
+	parent  string
+	depth   int
+	section string
 }
 
 // GenerateYAMLTemplate generates a YAML template from a given configuration struct.
-func GenerateYAMLTemplate(cfg interface{}) string {
-	var lines []FieldInfo
+func GenerateYAMLTemplate(cfg interface{}, opts ...TemplateOption) string {
+	template, _ := generateYAML(cfg, applyTemplateOptions(opts))
+	return template
+}
+
+// GenerateYAMLTemplateE generates a YAML template like GenerateYAMLTemplate,
+// but also reports the problems found in the configuration struct, such as conflicting keys.
+// The template is returned even when an error is reported.
+func GenerateYAMLTemplateE(cfg interface{}, opts ...TemplateOption) (string, error) {
+	return generateYAML(cfg, applyTemplateOptions(opts))
+}
+
+// GenerateYAMLFromValue generates a YAML template from a populated configuration struct.
+// Non-zero scalar fields and non-empty slices of scalars are rendered with their actual values
+// instead of the `default` tag; all other fields are rendered as in GenerateYAMLTemplate.
+func GenerateYAMLFromValue(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+	options.fromValue = true
+	template, _ := generateYAML(cfg, options)
+	return template
+}
 
+func generateYAML(cfg interface{}, options *Options) (string, error) {
 	// First pass: Parse the structure
-	parseStructure(reflect.TypeOf(cfg), reflect.ValueOf(cfg), 0, &lines)
+	nodes, err := buildConfigTree(cfg, options)
 
 	// Second pass: Generate aligned YAML
-	return generateYAMLWithAlignment(lines)
+	w := &yamlWriter{
+		options:   options,
+		maxLength: make(map[alignGroup]int),
+	}
+	w.writeNodes(wrapRootKey(nodes, options.rootKey), 0, "")
+	return generateYAMLWithAlignment(w.lines, w.maxLength, options), err
 }
 
-// Recursively parses a structure to build YAML template lines.
-func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]FieldInfo) {
-	indentation := strings.Repeat("  ", indent)
+// Nests the nodes of a document under the keys of a dot-separated root key, see WithRootKey.
+func wrapRootKey(nodes []*Node, rootKey string) []*Node {
+	if rootKey == "" {
+		return nodes
+	}
+	keys := strings.Split(rootKey, ".")
+	for i := len(keys) - 1; i >= 0; i-- {
+		nodes = []*Node{{
+			Name:     keys[i],
+			Path:     strings.Join(keys[:i+1], "."),
+			Kind:     KindStruct,
+			Children: nodes,
+		}}
+	}
+	return nodes
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+// yamlWriter collects the YAML template lines of a configuration tree.
+type yamlWriter struct {
+	options *Options
+	lines   []FieldInfo
 
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
-		}
+	// maxLength holds the running maximum line length of each block, used to align comments.
+	maxLength map[alignGroup]int
+	// commented reports that the nodes being written belong to a commented-out struct.
+	commented bool
 
-		tag := field.Tag
+	// anchors holds the anchors of the struct types already rendered, see WithYAMLAnchors.
+	anchors     map[reflect.Type]string
+	anchorNames map[string]bool
+}
 
-		// Handle ignored fields
-		if tag.Get("kong") == "-" || tag.Get("yaml") == "-" {
-			continue
-		}
+// Appends a template line and updates the maximum line length of its block.
+func (w *yamlWriter) addLine(line FieldInfo) {
+	w.lines = append(w.lines, line)
+	if len(line.Line) > w.maxLength[line.group] {
+		w.maxLength[line.group] = len(line.Line)
+	}
+}
+
+// Returns the comment of the line of a node's key, with the path of the node if configured.
+func (w *yamlWriter) comment(node *Node) string {
+	if w.options.fieldPathComment {
+		return joinComment(node.comment, "["+node.Path+"]")
+	}
+	return node.comment
+}
+
+// Recursively builds the YAML template lines of a list of sibling nodes.
+func (w *yamlWriter) writeNodes(nodes []*Node, indent int, parent string) {
+	indentation := strings.Repeat("  ", indent)
+	previousGroup := ""
+	// alternatives counts the mutually exclusive fields of the current group rendered so far
+	alternatives := 0
+	for i, node := range nodes {
+		group := alignGroup{parent: parent, depth: indent, section: node.Group}
+		childGroup := alignGroup{parent: node.Path, depth: indent + 1}
 
-		// Determine the YAML key name
-		fieldName := field.Name
-		if tagName := tag.Get("yaml"); tagName != "" && tagName != "-" {
-			fieldName = strings.Split(tagName, ",")[0]
-		} else if tagName := tag.Get("kong"); tagName != "" && tagName != "-" {
-			fieldName = tagName
+		// The fields of a group follow each other, under a header separated by a blank line
+		if node.Group != previousGroup {
+			if i > 0 {
+				w.lines = append(w.lines, FieldInfo{})
+			}
+			if node.Group != "" {
+				w.lines = append(w.lines, FieldInfo{Line: fmt.Sprintf("%s%s--- %s ---", indentation, w.options.commentPrefix, node.Group)})
+				if node.OneOf {
+					w.lines = append(w.lines, FieldInfo{Line: indentation + w.options.commentPrefix + "choose one of the following"})
+				}
+			}
+			previousGroup = node.Group
+			alternatives = 0
+		}
+		if node.OneOf && node.Group != "" {
+			alternatives++
 		}
-		fieldName = strings.ToLower(fieldName)
 
-		defaultValue := tag.Get("default")
-		if defaultValue == "" {
-			defaultValue = tag.Get("placeholder")
+		// Values from the example tag and deprecated fields are commented out if configured,
+		// so that they are never applied; the fields of a commented-out struct are commented out as well
+		lineIndent := indentation
+		// Only the first of mutually exclusive fields is active
+		commented := w.commented ||
+			(w.options.commentedExamples && node.fromExample) ||
+			(w.options.commentedDeprecated && node.Deprecated != "") ||
+			alternatives > 1
+		if commented {
+			lineIndent = indentation + "# "
 		}
-		helpText := tag.Get("help")
 
-		switch field.Type.Kind() {
-		case reflect.Struct:
-			*lines = append(*lines, FieldInfo{
-				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
-				Help: helpText,
+		switch node.Kind {
+		case KindScalar:
+			w.addLine(FieldInfo{
+				Line:    fmt.Sprintf("%s%s: %s", lineIndent, node.Name, yamlLiteral(node.value)),
+				Path:    node.Path,
+				Default: node.Default,
+				Help:    w.comment(node),
+				group:   group,
 			})
-			parseStructure(field.Type, v.Field(i), indent+1, lines)
 
-		case reflect.Slice:
-			*lines = append(*lines, FieldInfo{
-				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
-				Help: helpText,
+		case KindStruct:
+			anchor, repeated := w.anchor(node, commented)
+			if repeated {
+				// Repeated struct types merge the settings of their first occurrence
+				w.addLine(FieldInfo{
+					Line:    fmt.Sprintf("%s%s:", lineIndent, node.Name),
+					Path:    node.Path,
+					Default: node.Default,
+					Help:    w.comment(node),
+					group:   group,
+				})
+				w.addLine(FieldInfo{
+					Line:  fmt.Sprintf("%s  <<: *%s", lineIndent, anchor),
+					Help:  "Shares the settings above; add keys below to override them",
+					group: childGroup,
+				})
+				break
+			}
+			line := fmt.Sprintf("%s%s:", lineIndent, node.Name)
+			if anchor != "" {
+				line += " &" + anchor
+			}
+			w.addLine(FieldInfo{
+				Line:    line,
+				Path:    node.Path,
+				Default: node.Default,
+				Help:    w.comment(node),
+				group:   group,
 			})
+			w.writeChildren(node.Children, indent+1, node.Path, commented)
 
-			// Handle array of structs
-			if field.Type.Elem().Kind() == reflect.Struct {
-				*lines = append(*lines, FieldInfo{
-					Line: fmt.Sprintf("%s  -", indentation),
-					Help: "",
+		case KindStructList:
+			if node.examples == 0 && node.elements == nil {
+				comment := w.comment(node)
+				if comment == "" {
+					comment = "Array of items"
+				}
+				w.addLine(FieldInfo{
+					Line:    fmt.Sprintf("%s%s: []", lineIndent, node.Name),
+					Path:    node.Path,
+					Default: node.Default,
+					Help:    comment,
+					group:   group,
 				})
-				// For anonymous structs or uninitialized fields, using v.Field(i) might result in invalid or zero values,
-				// especially if the struct field hasn't been initialized yet. Instead, we use reflect.Zero(field.Type)
-				// to create a zero value of the field's type. This ensures safe traversal and correct YAML generation
-				// even when the struct is empty or contains anonymous sub-structs.
-				parseStructure(field.Type.Elem(), reflect.Zero(field.Type.Elem()), indent+2, lines)
-			} else {
-				// Handle array of primitives
-				if defaultValue != "" {
-					defaultItems := strings.Split(defaultValue, ",")
-					for _, item := range defaultItems {
-						*lines = append(*lines, FieldInfo{
-							Line: fmt.Sprintf("%s  - %s", indentation, strings.TrimSpace(item)),
-							Help: "",
-						})
-					}
-				} else {
-					*lines = append(*lines, FieldInfo{
-						Line: fmt.Sprintf("%s  - example", indentation),
-						Help: "",
+				break
+			}
+			w.addLine(FieldInfo{
+				Line:    fmt.Sprintf("%s%s:", lineIndent, node.Name),
+				Path:    node.Path,
+				Default: node.Default,
+				Help:    w.comment(node),
+				group:   group,
+			})
+			if node.elements != nil {
+				for _, element := range node.elements {
+					w.addLine(FieldInfo{
+						Line:  fmt.Sprintf("%s  -", lineIndent),
+						Help:  "",
+						group: childGroup,
 					})
+					w.writeChildren(element, indent+2, node.Path, commented)
 				}
+				break
+			}
+			for j := 0; j < node.examples; j++ {
+				w.addLine(FieldInfo{
+					Line:  fmt.Sprintf("%s  -", lineIndent),
+					Help:  "",
+					group: childGroup,
+				})
+				w.writeChildren(node.Children, indent+2, node.Path, commented)
 			}
 
-		case reflect.Map:
-			*lines = append(*lines, FieldInfo{
-				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
-				Help: helpText,
-			})
-			*lines = append(*lines, FieldInfo{
-				Line: fmt.Sprintf("%s  key: value", indentation),
-				Help: "Map example",
-			})
-
-		default:
-			value := defaultValue
-			if value == "" {
-				value = "null"
+		case KindList:
+			if node.flow || (len(node.items) > 0 && len(node.items) < w.options.flowStyleBelow) {
+				flowItems := make([]string, len(node.items))
+				for j, item := range node.items {
+					flowItems[j] = yamlLiteral(item)
+				}
+				w.addLine(FieldInfo{
+					Line:    fmt.Sprintf("%s%s: [%s]", lineIndent, node.Name, strings.Join(flowItems, ", ")),
+					Path:    node.Path,
+					Default: node.Default,
+					Help:    w.comment(node),
+					group:   group,
+				})
+				break
 			}
 
-			if field.Type.Kind() == reflect.String {
-				value = fmt.Sprintf(`"%s"`, value)
+			w.addLine(FieldInfo{
+				Line:    fmt.Sprintf("%s%s:", lineIndent, node.Name),
+				Path:    node.Path,
+				Default: node.Default,
+				Help:    w.comment(node),
+				group:   group,
+			})
+			if len(node.items) == 0 {
+				w.addLine(FieldInfo{
+					Line:  fmt.Sprintf("%s  - example", lineIndent),
+					Help:  "",
+					group: childGroup,
+				})
+			}
+			for _, item := range node.items {
+				// Block items from the default tag are written bare, except for text scalars and durations
+				if elem := node.field.Type.Elem(); !node.fromValue && !isTextScalar(elem) && elem != durationType {
+					item.quoted = false
+				}
+				w.addLine(FieldInfo{
+					Line:  fmt.Sprintf("%s  - %s", lineIndent, yamlLiteral(item)),
+					Help:  "",
+					group: childGroup,
+				})
 			}
 
-			*lines = append(*lines, FieldInfo{
-				Line: fmt.Sprintf("%s%s: %s", indentation, fieldName, value),
-				Help: helpText,
+		case KindMap:
+			if len(node.entries) == 0 && node.mapExample == "" && node.examples == 0 {
+				w.addLine(FieldInfo{
+					Line:    fmt.Sprintf("%s%s: {}", lineIndent, node.Name),
+					Path:    node.Path,
+					Default: node.Default,
+					Help:    w.comment(node),
+					group:   group,
+				})
+				break
+			}
+			w.addLine(FieldInfo{
+				Line:    fmt.Sprintf("%s%s:", lineIndent, node.Name),
+				Path:    node.Path,
+				Default: node.Default,
+				Help:    w.comment(node),
+				group:   group,
+			})
+			if len(node.entries) > 0 {
+				for _, entry := range node.entries {
+					w.addLine(FieldInfo{
+						Line:  fmt.Sprintf("%s  %s: %s", lineIndent, yamlKey(entry.key), yamlLiteral(entry.value)),
+						Help:  "",
+						group: childGroup,
+					})
+				}
+				break
+			}
+			if node.mapExample != "" {
+				// The example is embedded verbatim, indented under the key
+				for _, line := range strings.Split(strings.TrimRight(node.mapExample, "\n"), "\n") {
+					w.addLine(FieldInfo{
+						Line:  fmt.Sprintf("%s  %s", lineIndent, line),
+						Help:  "",
+						group: childGroup,
+					})
+				}
+				break
+			}
+			if node.examples > 1 {
+				for j := 1; j <= node.examples; j++ {
+					if node.mapElement != nil {
+						w.addLine(FieldInfo{
+							Line:  fmt.Sprintf("%s  example%d:", lineIndent, j),
+							Help:  "",
+							group: childGroup,
+						})
+						w.writeChildren(node.mapElement, indent+2, node.Path, commented)
+						continue
+					}
+					w.addLine(FieldInfo{
+						Line:  fmt.Sprintf("%s  example%d: %s", lineIndent, j, mapExampleValue(node.field.Type.Elem(), j)),
+						Help:  "",
+						group: childGroup,
+					})
+				}
+				break
+			}
+			if node.mapElement != nil {
+				w.addLine(FieldInfo{
+					Line:  fmt.Sprintf("%s  key:", lineIndent),
+					Help:  "Map example",
+					group: childGroup,
+				})
+				w.writeChildren(node.mapElement, indent+2, node.Path, commented)
+				break
+			}
+			w.addLine(FieldInfo{
+				Line:  fmt.Sprintf("%s  key: %s", lineIndent, mapExampleValue(node.field.Type.Elem(), 0)),
+				Help:  "Map example",
+				group: childGroup,
 			})
 		}
 	}
 }
 
-// Aligns YAML lines with proper spacing for comments.
-func generateYAMLWithAlignment(lines []FieldInfo) string {
-	var builder strings.Builder
-	maxLength := 0
+// Returns the value of a placeholder entry of a map with elements of type t, typed so that the template decodes:
+// "value" for strings, numbered from n if it is positive, and the zero value of other scalars, e.g. 0 or false.
+func mapExampleValue(t reflect.Type, n int) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isStringType(t) {
+		if n > 0 {
+			return fmt.Sprintf("value%d", n)
+		}
+		return "value"
+	}
+	if t == durationType {
+		return "0s"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "0"
+	case reflect.Float32, reflect.Float64:
+		return "0.0"
+	case reflect.Slice, reflect.Array:
+		return "[]"
+	case reflect.Map, reflect.Struct:
+		return "{}"
+	case reflect.Interface:
+		return "value"
+	}
+	return "null"
+}
 
-	// Determine max line length (excluding comments)
-	for _, line := range lines {
-		if len(line.Line) > maxLength {
-			maxLength = len(line.Line)
+// Returns the anchor of a struct node with WithYAMLAnchors, and whether its type was already rendered under it.
+// The first occurrence of a named struct type defines the anchor, named after the type; commented-out nodes
+// never define nor reference anchors.
+func (w *yamlWriter) anchor(node *Node, commented bool) (string, bool) {
+	t := node.field.Type
+	if !w.options.yamlAnchors || commented || t == nil || t.Name() == "" {
+		return "", false
+	}
+	if anchor, ok := w.anchors[t]; ok {
+		return anchor, true
+	}
+	if w.anchors == nil {
+		w.anchors = make(map[reflect.Type]string)
+		w.anchorNames = make(map[string]bool)
+	}
+
+	// Types of different packages may share a name
+	anchor := strings.ToLower(t.Name())
+	for i := 2; w.anchorNames[anchor]; i++ {
+		anchor = fmt.Sprintf("%s%d", strings.ToLower(t.Name()), i)
+	}
+	w.anchors[t] = anchor
+	w.anchorNames[anchor] = true
+	return anchor, false
+}
+
+// Writes the children of a node, commented out if the node is.
+func (w *yamlWriter) writeChildren(nodes []*Node, indent int, parent string, commented bool) {
+	outer := w.commented
+	w.commented = commented
+	w.writeNodes(nodes, indent, parent)
+	w.commented = outer
+}
+
+// plainYAMLKey matches the map keys that are written without quotes.
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// yamlQuoteEscaper escapes the characters that would end or break a double-quoted YAML scalar.
+var yamlQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Returns the YAML literal of a scalar: strings are quoted, other values are written bare,
+// and unknown values are written as null.
+func yamlLiteral(value scalar) string {
+	text := value.text
+	if value.null {
+		text = "null"
+	}
+	if value.quoted {
+		return `"` + yamlQuoteEscaper.Replace(text) + `"`
+	}
+	return text
+}
+
+// Returns the YAML key of a map entry, quoted unless it is a plain word that YAML reads as a string.
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "true", "false", "null", "yes", "no", "on", "off":
+	default:
+		if plainYAMLKey.MatchString(key) {
+			return key
+		}
+	}
+	return yamlLiteral(scalar{text: key, quoted: true})
+}
+
+// Determines the key name of a field from the first non-empty tag in tagNames, which is lowercased,
+// falling back to the field name converted with the naming strategy. The "kong" entry stands for the name
+// given by Kong, see kongName.
+func fieldKey(field reflect.StructField, naming keyNaming, tagNames ...string) string {
+	for _, tagName := range tagNames {
+		var name string
+		if tagName == "kong" {
+			name = kongName(field)
+			if naming.kongUnderscores {
+				name = strings.ReplaceAll(name, "-", "_")
+			}
+		} else if tagValue := field.Tag.Get(tagName); tagValue != "-" {
+			name = strings.Split(tagValue, ",")[0]
+		}
+		if name != "" {
+			return strings.ToLower(name)
 		}
 	}
+	return naming.strategy.key(field.Name)
+}
+
+// Reports whether the given tag of a field lists the option after its name, e.g. `yaml:",inline"`.
+func hasTagOption(field reflect.StructField, tagName, option string) bool {
+	parts := strings.Split(field.Tag.Get(tagName), ",")
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(part) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Joins the help text and an additional comment into a single comment.
+func joinComment(help, comment string) string {
+	switch {
+	case help == "":
+		return comment
+	case comment == "":
+		return help
+	default:
+		return help + " " + comment
+	}
+}
+
+// Aligns YAML lines with proper spacing for comments.
+// Comments are aligned independently within each block of sibling lines, using the maximum line length
+// of each block computed while parsing, at a fixed column if one is configured, or not at all in compact mode.
+func generateYAMLWithAlignment(lines []FieldInfo, maxLength map[alignGroup]int, options *Options) string {
+	var builder strings.Builder
 
 	// Generate aligned lines
 	for _, line := range lines {
 		builder.WriteString(line.Line)
 		if line.Help != "" {
-			spaces := strings.Repeat(" ", maxLength-len(line.Line)+1)
-			builder.WriteString(spaces + "# " + line.Help)
+			padding := maxLength[line.group] - len(line.Line) + 1
+			if options.compact {
+				padding = 1
+			} else if options.commentColumn > 0 {
+				padding = max(options.commentColumn-len(line.Line), 1)
+			}
+			builder.WriteString(strings.Repeat(" ", padding) + options.commentPrefix + line.Help)
 		}
 		builder.WriteString("\n")
 	}
 
+	return frameYAML(builder.String(), options)
+}
+
+// Frames the lines of a YAML document: prepends the directive, the document separator and the schema
+// modeline if configured, and ends the lines with the configured number of newlines.
+func frameYAML(body string, options *Options) string {
+	var builder strings.Builder
+	if options.yamlDirective {
+		builder.WriteString("%YAML 1.2\n")
+	}
+	if options.yamlDirective || options.leadingSeparator {
+		builder.WriteString("---\n")
+	}
+	if options.schemaURL != "" {
+		builder.WriteString("# yaml-language-server: $schema=" + options.schemaURL + "\n")
+	}
+	builder.WriteString(schemaVersionLine(options))
+	if body != "" {
+		builder.WriteString(strings.TrimRight(body, "\n"))
+		builder.WriteString(strings.Repeat("\n", options.trailingNewlines))
+	}
 	return builder.String()
 }
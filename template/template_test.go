@@ -1,9 +1,15 @@
 package template
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestGenerateYAMLTemplate(t *testing.T) {
@@ -28,9 +34,9 @@ options:          # List of options
   - 2
   - 3
 meta:
-  version: "1.0"  # App version
+  version: "1.0" # App version
 map_field:        # Example map field
-  key: value      # Map example
+  key: value # Map example
 `
 
 	assert.Equal(t, expected, yamlTemplate)
@@ -63,7 +69,7 @@ func TestGenerateYAMLTemplate_ArrayOfPrimitives(t *testing.T) {
 			cfg: struct {
 				OptionsWithDefault []string `yaml:"options" default:"value1" help:"Array of options"`
 			}{},
-			expected: `options:   # Array of options
+			expected: `options: # Array of options
   - value1
 `,
 		},
@@ -83,7 +89,7 @@ func TestGenerateYAMLTemplate_ArrayOfPrimitives(t *testing.T) {
 			cfg: struct {
 				OptionsWithoutDefaults []string `yaml:"options" help:"Array of options"`
 			}{},
-			expected: `options:    # Array of options
+			expected: `options: # Array of options
   - example
 `,
 		},
@@ -108,7 +114,7 @@ func TestGenerateYAMLTemplate_ArrayOfStructs(t *testing.T) {
 	}{}
 	yamlTemplate := GenerateYAMLTemplate(cfg)
 
-	expected := `items:            # Array of items
+	expected := `items: # Array of items
   -
     name: "item1" # Item name
     value: null
@@ -124,7 +130,7 @@ func TestGenerateYAMLTemplate_Map(t *testing.T) {
 	}{}
 	yamlTemplate := GenerateYAMLTemplate(cfg)
 
-	expected := `settings:    # Map of settings
+	expected := `settings: # Map of settings
   key: value # Map example
 `
 
@@ -147,6 +153,73 @@ func TestGenerateYAMLTemplate_AnonymousStruct(t *testing.T) {
 	assert.Equal(t, expected, yamlTemplate)
 }
 
+type CommonOpts struct {
+	Verbose bool   `yaml:"verbose" default:"false" help:"Verbose output"`
+	Region  string `yaml:"region" default:"eu"`
+}
+
+// Test YAML generation with an anonymously embedded pointer to a struct, whose fields are inlined even when nil.
+func TestGenerateYAMLTemplate_EmbeddedPointer(t *testing.T) {
+	cfg := struct {
+		*CommonOpts
+		Host string `yaml:"host" default:"localhost"`
+	}{}
+
+	expected := `verbose: false    # Verbose output
+region: "eu"
+host: "localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+
+	// Set values are used with GenerateYAMLFromValue
+	cfg.CommonOpts = &CommonOpts{Region: "us"}
+	assert.Contains(t, GenerateYAMLFromValue(cfg), `region: "us"`)
+}
+
+type ServerOpts struct {
+	CommonOpts
+	Port int `yaml:"port" default:"8080"`
+}
+
+// Test that embedded structs without a key name are promoted, also through several levels of embedding,
+// while embedded structs with a key name are nested under it.
+func TestGenerateYAMLTemplate_EmbeddedPromotion(t *testing.T) {
+	promoted := struct {
+		ServerOpts
+		Host string `yaml:"host" default:"localhost"`
+	}{}
+	expected := `verbose: false    # Verbose output
+region: "eu"
+port: 8080
+host: "localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(promoted))
+
+	nested := struct {
+		CommonOpts `yaml:"common"`
+		Host       string `yaml:"host" default:"localhost"`
+	}{}
+	expected = `common:
+  verbose: false # Verbose output
+  region: "eu"
+host: "localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(nested))
+
+	inlined := struct {
+		CommonOpts `yaml:",inline"`
+		Host       string `yaml:"host" default:"localhost"`
+	}{}
+	expected = `verbose: false    # Verbose output
+region: "eu"
+host: "localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(inlined))
+
+	promoted.Region = "us"
+	assert.Contains(t, GenerateYAMLFromValue(promoted), `region: "us"`)
+}
+
 // Test YAML generation with ignored fields.
 func TestGenerateYAMLTemplate_IgnoredFields(t *testing.T) {
 	cfg := struct {
@@ -188,3 +261,1084 @@ nickname: "your_username"    # User nickname
 
 	assert.Equal(t, expected, yamlTemplate)
 }
+
+// Test YAML generation with examples, with the precedence default > example > placeholder.
+func TestGenerateYAMLTemplate_Example(t *testing.T) {
+	cfg := struct {
+		Network string   `yaml:"network" example:"10.0.0.0/8" help:"Allowed network"`
+		Port    int      `yaml:"port" default:"8080" example:"9090" placeholder:"80"`
+		User    string   `yaml:"user" example:"admin" placeholder:"your_username"`
+		Name    string   `yaml:"name" placeholder:"your_name"`
+		Peers   []string `yaml:"peers" example:"a,b"`
+	}{}
+
+	expected := `network: "10.0.0.0/8" # Allowed network (example)
+port: 8080
+user: "admin"         # (example)
+name: "your_name"
+peers:                # (example)
+  - a
+  - b
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+
+	expected = `# network: "10.0.0.0/8" # Allowed network (example)
+port: 8080
+# user: "admin"         # (example)
+name: "your_name"
+# peers:                # (example)
+#   - a
+#   - b
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithCommentedExamples()))
+}
+
+// Test YAML generation of deprecated fields, rendered by default, commented out or omitted.
+func TestGenerateYAMLTemplate_Deprecated(t *testing.T) {
+	cfg := struct {
+		Listen string `yaml:"listen" default:":8080" help:"Listen address"`
+		Port   int    `yaml:"port" default:"8080" help:"Port" deprecated:"use listen instead"`
+		Legacy struct {
+			Mode string `yaml:"mode" default:"compat"`
+		} `yaml:"legacy" deprecated:"no longer used"`
+	}{}
+
+	expected := `listen: ":8080" # Listen address
+port: 8080      # DEPRECATED: use listen instead. Port
+legacy:         # DEPRECATED: no longer used.
+  mode: "compat"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+
+	expected = `listen: ":8080" # Listen address
+# port: 8080    # DEPRECATED: use listen instead. Port
+# legacy:       # DEPRECATED: no longer used.
+  # mode: "compat"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithCommentedDeprecated()))
+
+	expected = `listen: ":8080" # Listen address
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithOmitDeprecated()))
+}
+
+// Test YAML generation of grouped fields under header comments, reordered within their struct only.
+func TestGenerateYAMLTemplate_Groups(t *testing.T) {
+	cfg := struct {
+		Host    string `yaml:"host" default:"localhost" group:"Networking"`
+		Name    string `yaml:"name" default:"app" help:"Application name"`
+		LogFile string `yaml:"log_file" default:"app.log" group:"Logging"`
+		Port    int    `yaml:"port" default:"8080" group:"Networking" help:"Listen port"`
+		Cache   struct {
+			Size int `yaml:"size" default:"64" group:"Limits"`
+			TTL  int `yaml:"ttl" default:"60"`
+		} `yaml:"cache" group:"Logging"`
+	}{}
+
+	expected := `name: "app" # Application name
+
+# --- Networking ---
+host: "localhost"
+port: 8080        # Listen port
+
+# --- Logging ---
+log_file: "app.log"
+cache:
+  ttl: 60
+
+  # --- Limits ---
+  size: 64
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+
+	expected = `# --- Networking ---
+host: "localhost"
+port: 8080        # Listen port
+
+# --- Logging ---
+log_file: "app.log"
+cache:
+  # --- Limits ---
+  size: 64
+
+  ttl: 60
+
+name: "app" # Application name
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithUngroupedLast()))
+}
+
+type overrideServer struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port" default:"80"`
+}
+
+// Test generation of a minimal override file with the fields that differ from their defaults.
+func TestGenerateYAMLDiffTemplate(t *testing.T) {
+	type config struct {
+		Name     string            `yaml:"name" default:"app" help:"Application name"`
+		Port     int               `yaml:"port" default:"8080"`
+		Debug    bool              `yaml:"debug" default:"true"`
+		Timeout  time.Duration     `yaml:"timeout" default:"5s"`
+		Tags     []string          `yaml:"tags" default:"a,b"`
+		Labels   map[string]string `yaml:"labels"`
+		Database struct {
+			DSN     string `yaml:"dsn"`
+			MaxOpen int    `yaml:"max_open" default:"10"`
+		} `yaml:"database"`
+		Cache struct {
+			Size int `yaml:"size" default:"64"`
+		} `yaml:"cache"`
+		Servers []overrideServer `yaml:"servers"`
+	}
+
+	cfg := config{
+		Name:    "app",
+		Port:    9090,
+		Debug:   false,
+		Timeout: 5 * time.Second,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "env": "prod"},
+		Servers: []overrideServer{{Host: "a", Port: 80}, {Host: "b", Port: 81}},
+	}
+	cfg.Database.MaxOpen = 20
+	cfg.Cache.Size = 64
+
+	expected := `port: 9090
+debug: false
+labels:
+  env: "prod"
+  team: "core"
+database:
+  max_open: 20
+servers:
+  -
+    host: "a"
+    port: 80
+  -
+    host: "b"
+    port: 81
+`
+	assert.Equal(t, expected, GenerateYAMLDiffTemplate(cfg))
+
+	defaults := config{Name: "app", Port: 8080, Debug: true, Timeout: 5 * time.Second, Tags: []string{"a", "b"}}
+	defaults.Database.MaxOpen = 10
+	defaults.Cache.Size = 64
+	assert.Equal(t, "", GenerateYAMLDiffTemplate(defaults), "A configuration with the defaults needs no override")
+}
+
+// Test YAML generation nested under a root key, with a single and a dot-separated key.
+func TestGenerateYAMLTemplate_RootKey(t *testing.T) {
+	cfg := struct {
+		Host   string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port   int    `yaml:"port" default:"8080" help:"The port number"`
+		Server struct {
+			Timeout string `yaml:"timeout" default:"5s" help:"Request timeout"`
+		} `yaml:"server"`
+	}{}
+
+	expected := `myservice:
+  host: "localhost" # The hostname
+  port: 8080        # The port number
+  server:
+    timeout: "5s" # Request timeout
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithRootKey("myservice")))
+
+	expected = `services:
+  myservice:
+    host: "localhost" # The hostname
+    port: 8080        # The port number
+    server:
+      timeout: "5s" # Request timeout
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithRootKey("services.myservice")))
+}
+
+type DBConfig struct {
+	Host string `yaml:"host" default:"localhost" help:"Database host"`
+	Port int    `yaml:"port" default:"5432"`
+}
+
+// Test YAML generation with anchors and merge keys for repeated struct types, decoded back with yaml.v3.
+func TestGenerateYAMLTemplate_YAMLAnchors(t *testing.T) {
+	type config struct {
+		Primary DBConfig `yaml:"primary" help:"Primary database"`
+		Replica DBConfig `yaml:"replica"`
+		Backup  DBConfig `yaml:"backup"`
+	}
+
+	expected := `primary: &dbconfig # Primary database
+  host: "localhost" # Database host
+  port: 5432
+replica:
+  <<: *dbconfig # Shares the settings above; add keys below to override them
+backup:
+  <<: *dbconfig # Shares the settings above; add keys below to override them
+`
+	yamlTemplate := GenerateYAMLTemplate(config{}, WithYAMLAnchors())
+	assert.Equal(t, expected, yamlTemplate)
+
+	var decoded config
+	require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded))
+	assert.Equal(t, DBConfig{Host: "localhost", Port: 5432}, decoded.Replica, "Merged struct should decode like the anchored one")
+	assert.Equal(t, decoded.Primary, decoded.Backup)
+
+	assert.NotContains(t, GenerateYAMLTemplate(config{}), "&dbconfig", "Anchors should be opt-in")
+}
+
+// Test YAML generation of mutually exclusive fields, with all but the first commented out.
+func TestGenerateYAMLTemplate_OneOf(t *testing.T) {
+	cfg := struct {
+		Name string `yaml:"name" default:"app"`
+		File struct {
+			Path string `yaml:"path" default:"/var/data" help:"Data directory"`
+		} `yaml:"file" group:"storage" oneof:"true" help:"Local storage"`
+		S3 struct {
+			Bucket string `yaml:"bucket" default:"data" help:"Bucket name"`
+			Region string `yaml:"region" default:"eu-west-1"`
+		} `yaml:"s3" group:"storage" oneof:"true" help:"S3 storage"`
+	}{}
+
+	expected := `name: "app"
+
+# --- storage ---
+# choose one of the following
+file: # Local storage
+  path: "/var/data" # Data directory
+# s3: # S3 storage
+  # bucket: "data"      # Bucket name
+  # region: "eu-west-1"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+}
+
+// Test that WithCommentPrefix changes the marker of documentation comments, but not of commented-out values.
+func TestGenerateYAMLTemplate_CommentPrefix(t *testing.T) {
+	cfg := struct {
+		Name   string            `yaml:"name" default:"app" help:"Application name"`
+		Labels map[string]string `yaml:"labels"`
+		File   struct {
+			Path string `yaml:"path" default:"/var/data"`
+		} `yaml:"file" group:"storage" oneof:"true" help:"Local storage"`
+		S3 struct {
+			Bucket string `yaml:"bucket" default:"data" help:"Bucket name"`
+		} `yaml:"s3" group:"storage" oneof:"true"`
+	}{}
+
+	expected := `name: "app" ## Application name
+labels:
+  key: value ## Map example
+
+## --- storage ---
+## choose one of the following
+file: ## Local storage
+  path: "/var/data"
+# s3:
+  # bucket: "data" ## Bucket name
+`
+	yamlTemplate := GenerateYAMLTemplate(cfg, WithCommentPrefix("## "))
+	assert.Equal(t, expected, yamlTemplate)
+	assert.Equal(t, GenerateYAMLTemplate(cfg), GenerateYAMLTemplate(cfg, WithCommentPrefix("// ")),
+		"Prefixes that are not YAML comments should be ignored")
+
+	var decoded map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded))
+	assert.Equal(t, "app", decoded["name"])
+}
+
+// Test that comments are aligned independently within each nested block.
+func TestGenerateYAMLTemplate_NestedBlockAlignment(t *testing.T) {
+	cfg := struct {
+		Name string `yaml:"name" default:"app" help:"Application name"`
+		Meta struct {
+			Version string `yaml:"version" default:"1.0" help:"App version"`
+			Build   int    `yaml:"build" default:"42" help:"Build number"`
+		} `yaml:"meta"`
+		Database struct {
+			ConnectionString string `yaml:"connection_string" default:"postgres://localhost/db" help:"DSN"`
+			Pool             int    `yaml:"pool" default:"10" help:"Pool size"`
+		} `yaml:"database" help:"Database settings"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `name: "app" # Application name
+meta:
+  version: "1.0" # App version
+  build: 42      # Build number
+database:   # Database settings
+  connection_string: "postgres://localhost/db" # DSN
+  pool: 10                                     # Pool size
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation with inlined embedded structs.
+func TestGenerateYAMLTemplate_Inline(t *testing.T) {
+	type Common struct {
+		Name  string `yaml:"name" default:"app" help:"Application name"`
+		Debug bool   `yaml:"debug" default:"false"`
+	}
+	cfg := struct {
+		Common `yaml:",inline"`
+		Port   int `yaml:"port" default:"8080" help:"The port number"`
+		Nested struct {
+			Common  `yaml:",inline"`
+			Timeout int `yaml:"timeout" default:"30"`
+		} `yaml:"nested"`
+	}{}
+	yamlTemplate, err := GenerateYAMLTemplateE(cfg)
+	assert.NoError(t, err)
+
+	expected := `name: "app"  # Application name
+debug: false
+port: 8080   # The port number
+nested:
+  name: "app"  # Application name
+  debug: false
+  timeout: 30
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test that conflicts between inlined keys and sibling keys are reported.
+func TestGenerateYAMLTemplateE_InlineConflict(t *testing.T) {
+	type Common struct {
+		Name string `yaml:"name" default:"common"`
+	}
+	cfg := struct {
+		Common `yaml:",inline"`
+		Name   string `yaml:"name" default:"own"`
+	}{}
+	_, err := GenerateYAMLTemplateE(cfg)
+
+	assert.EqualError(t, err, `duplicate key "name" at name`)
+}
+
+// Test YAML generation with omitempty fields.
+func TestGenerateYAMLTemplate_Omitempty(t *testing.T) {
+	cfg := struct {
+		Host  string   `yaml:"host" default:"localhost" help:"The hostname"`
+		Alias string   `yaml:"alias,omitempty" help:"Optional alias"`
+		Tags  []string `yaml:"tags,flow,omitempty" default:"a,b"`
+		Label string   `yaml:"label,omitempty,flow" default:"main"`
+		Note  string   `yaml:",omitempty" default:"none"`
+	}{}
+
+	t.Run("Default", func(t *testing.T) {
+		expected := `host: "localhost" # The hostname
+alias: "null"     # Optional alias (optional, omitted when empty)
+tags: ["a", "b"]  # (optional)
+label: "main"     # (optional)
+note: "none"      # (optional)
+`
+		assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+	})
+
+	t.Run("SkipOmitempty", func(t *testing.T) {
+		expected := `host: "localhost" # The hostname
+`
+		assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithSkipOmitempty()))
+	})
+}
+
+// Test YAML generation of empty omitempty fields of each kind, with and without WithOmitEmpty.
+func TestGenerateYAMLTemplate_OmitEmpty(t *testing.T) {
+	cfg := struct {
+		Name    string            `yaml:"name,omitempty"`
+		Alias   string            `yaml:"alias,omitempty" default:"main"`
+		Retries int               `yaml:"retries,omitempty" default:"0"`
+		Workers int               `yaml:"workers,omitempty" default:"4"`
+		Tags    []string          `yaml:"tags,omitempty"`
+		Ports   []int             `yaml:"ports,omitempty" default:"80"`
+		Labels  map[string]string `yaml:"labels,omitempty"`
+	}{}
+
+	t.Run("Comment", func(t *testing.T) {
+		expected := `name: "null"  # (optional, omitted when empty)
+alias: "main" # (optional)
+retries: 0    # (optional, omitted when empty)
+workers: 4    # (optional)
+tags:         # (optional, omitted when empty)
+  - example
+ports:        # (optional)
+  - 80
+labels:       # (optional, omitted when empty)
+  key: value # Map example
+`
+		assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+	})
+
+	t.Run("WithOmitEmpty", func(t *testing.T) {
+		expected := `alias: "main" # (optional)
+workers: 4    # (optional)
+ports:        # (optional)
+  - 80
+`
+		assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithOmitEmpty()))
+	})
+}
+
+// Test YAML generation of slices in flow style.
+func TestGenerateYAMLTemplate_FlowStyle(t *testing.T) {
+	cfg := struct {
+		Tags    []string `yaml:"tags,flow" default:"a,b,c" help:"Tags"`
+		Ports   []int    `yaml:"ports" default:"80,443" help:"Ports"`
+		Hosts   []string `yaml:"hosts" default:"a,b,c,d"`
+		Empty   []string `yaml:"empty,flow"`
+		Default []string `yaml:"default"`
+	}{}
+
+	t.Run("FlowTag", func(t *testing.T) {
+		expected := `tags: ["a", "b", "c"] # Tags
+ports:                # Ports
+  - 80
+  - 443
+hosts:
+  - a
+  - b
+  - c
+  - d
+empty: []
+default:
+  - example
+`
+		assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+	})
+
+	t.Run("FlowStyleBelow", func(t *testing.T) {
+		expected := `tags: ["a", "b", "c"] # Tags
+ports: [80, 443]      # Ports
+hosts:
+  - a
+  - b
+  - c
+  - d
+empty: []
+default:
+  - example
+`
+		assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithFlowStyleBelow(4)))
+	})
+}
+
+// Test that kong tag options never leak into the key name.
+func TestGenerateYAMLTemplate_KongTagWithOptions(t *testing.T) {
+	cfg := struct {
+		Port    int    `kong:"required,help='Port to listen on'" default:"8080"`
+		Address string `kong:"name='listen',help='Listen address'" default:"0.0.0.0"`
+		Mode    string `kong:"mode" default:"fast"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `port: 8080
+listen: "0.0.0.0"
+mode: "fast"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation with comments at a fixed column.
+func TestGenerateYAMLTemplate_FixedCommentColumn(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port int    `yaml:"port" default:"8080" help:"The port number"`
+		Meta struct {
+			Version string `yaml:"version" default:"1.0" help:"App version"`
+		} `yaml:"meta"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, WithFixedCommentColumn(12))
+
+	expected := `host: "localhost" # The hostname
+port: 8080  # The port number
+meta:
+  version: "1.0" # App version
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation from a struct with json tags only.
+func TestGenerateYAMLTemplate_JSONTags(t *testing.T) {
+	cfg := struct {
+		Host     string `json:"host" default:"localhost" help:"The hostname"`
+		Port     int    `json:"port,omitempty" default:"8080"`
+		Password string `yaml:"password" json:"-"`
+		Internal string `json:"-"`
+		Timeout  string `json:",omitempty" default:"5s"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `host: "localhost" # The hostname
+port: 8080
+password: "null"
+timeout: "5s"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation of map fields with default entries.
+func TestGenerateYAMLTemplate_MapDefault(t *testing.T) {
+	cfg := struct {
+		Labels  map[string]string `yaml:"labels" default:"env=prod, greeting='hello, world', 'a=b'=c, on=x" help:"Labels"`
+		Weights map[string]int    `yaml:"weights" default:"a=1,b=2"`
+		Meta    struct {
+			Limits map[string]float64 `yaml:"limits" default:"cpu=0.5"`
+		} `yaml:"meta"`
+	}{}
+	yamlTemplate, err := GenerateYAMLTemplateE(cfg)
+	require.NoError(t, err)
+
+	expected := `labels:  # Labels
+  env: "prod"
+  greeting: "hello, world"
+  "a=b": "c"
+  "on": "x"
+weights:
+  a: 1
+  b: 2
+meta:
+  limits:
+    cpu: 0.5
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	var decoded struct {
+		Labels  map[string]string `yaml:"labels"`
+		Weights map[string]int    `yaml:"weights"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded))
+	assert.Equal(t, map[string]string{"env": "prod", "greeting": "hello, world", "a=b": "c", "on": "x"}, decoded.Labels)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, decoded.Weights)
+
+	_, err = GenerateYAMLTemplateE(struct {
+		Labels map[string]string `yaml:"labels" default:"env"`
+	}{})
+	assert.ErrorContains(t, err, `invalid default of labels: missing "=" in map entry "env"`)
+}
+
+// Test YAML generation of bool defaults spelled as yes/no, 1/0 or in mixed case.
+func TestGenerateYAMLTemplate_BoolDefault(t *testing.T) {
+	cfg := struct {
+		Enabled bool   `yaml:"enabled" default:"yes"`
+		Debug   bool   `yaml:"debug" default:"0"`
+		Verbose *bool  `yaml:"verbose" default:"True"`
+		Flags   []bool `yaml:"flags" default:"on, off, 1"`
+	}{}
+	yamlTemplate, err := GenerateYAMLTemplateE(cfg)
+	require.NoError(t, err)
+
+	expected := `enabled: true
+debug: false
+verbose: true
+flags:
+  - true
+  - false
+  - true
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	var decoded struct {
+		Enabled bool   `yaml:"enabled"`
+		Flags   []bool `yaml:"flags"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded))
+	assert.True(t, decoded.Enabled)
+	assert.Equal(t, []bool{true, false, true}, decoded.Flags)
+
+	_, err = GenerateYAMLTemplateE(struct {
+		Server struct {
+			TLS bool `yaml:"tls" default:"maybe"`
+		} `yaml:"server"`
+	}{})
+	assert.ErrorContains(t, err, `invalid default of server.tls: invalid boolean "maybe"`)
+
+	_, err = GenerateYAMLTemplateE(struct {
+		Flags []bool `yaml:"flags" default:"true,maybe"`
+	}{})
+	assert.ErrorContains(t, err, `invalid default of flags: invalid boolean "maybe"`)
+}
+
+// Test YAML generation of numeric defaults in their canonical form, and reporting of invalid ones.
+func TestGenerateYAMLTemplate_NumericDefault(t *testing.T) {
+	cfg := struct {
+		Mode    int               `yaml:"mode" default:"0644"`
+		Retries *uint8            `yaml:"retries" default:"03"`
+		Ratio   float64           `yaml:"ratio" default:"0.50"`
+		Limit   float32           `yaml:"limit" default:"1E3"`
+		Ports   []int             `yaml:"ports" default:"080, 443"`
+		Weights map[string]uint   `yaml:"weights" default:"a=01"`
+		Timeout time.Duration     `yaml:"timeout" default:"5s"`
+		Port    int               `yaml:"port" placeholder:"<port>"`
+		Scores  map[string]string `yaml:"scores" default:"a=01"`
+	}{}
+	yamlTemplate, err := GenerateYAMLTemplateE(cfg)
+	require.NoError(t, err)
+
+	expected := `mode: 644
+retries: 3
+ratio: 0.5
+limit: 1000
+ports:
+  - 80
+  - 443
+weights:
+  a: 1
+timeout: 5s
+port: <port>
+scores:
+  a: "01"
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	for _, tc := range []struct {
+		cfg      interface{}
+		expected string
+	}{
+		{struct {
+			Port int32 `yaml:"port" default:"1e99"`
+		}{}, `invalid default of port: invalid int32 "1e99": invalid syntax`},
+		{struct {
+			Port int8 `yaml:"port" default:"300"`
+		}{}, `invalid default of port: invalid int8 "300": value out of range`},
+		{struct {
+			Size uint `yaml:"size" default:"-1"`
+		}{}, `invalid default of size: invalid uint "-1": invalid syntax`},
+		{struct {
+			Ratio float64 `yaml:"ratio" default:"3,14"`
+		}{}, `invalid default of ratio: invalid float64 "3,14": invalid syntax`},
+		{struct {
+			Ports []uint16 `yaml:"ports" default:"80,70000"`
+		}{}, `invalid default of ports: invalid uint16 "70000": value out of range`},
+	} {
+		_, err := GenerateYAMLTemplateE(tc.cfg)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
+
+// Test YAML generation of byte slices as strings, from defaults and from values.
+func TestGenerateYAMLTemplate_Bytes(t *testing.T) {
+	type Config struct {
+		Token  []byte `yaml:"token" default:"abc123" help:"API token"`
+		Key    []byte `yaml:"key" default:"secret" encoding:"base64"`
+		Salt   []byte `yaml:"salt"`
+		Chunks []byte `yaml:"chunks" default:"a,b"`
+	}
+	expected := `token: "abc123" # API token
+key: "c2VjcmV0"
+salt: null
+chunks: "a,b"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}))
+
+	expected = `token: "xyz"  # API token
+key: "AQI="
+salt: null
+chunks: "a,b"
+`
+	assert.Equal(t, expected, GenerateYAMLFromValue(Config{Token: []byte("xyz"), Key: []byte{1, 2}}))
+
+	expected = `key: "AQI="
+`
+	assert.Equal(t, expected, GenerateYAMLDiffTemplate(Config{Token: []byte("abc123"), Key: []byte{1, 2}, Chunks: []byte("a,b")}))
+}
+
+// Test YAML generation with the paths of the fields in the comments.
+func TestGenerateYAMLTemplate_FieldPathComment(t *testing.T) {
+	cfg := struct {
+		Server struct {
+			HTTP struct {
+				Port int `yaml:"port" default:"8080" help:"The port number"`
+			} `yaml:"http"`
+		} `yaml:"server"`
+		Debug bool `kong:"name=verbose"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, WithFieldPathComment())
+
+	expected := `server:       # [server]
+  http: # [server.http]
+    port: 8080 # The port number [server.http.port]
+verbose: null # [verbose]
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation of several or no example entries for slices of structs.
+func TestGenerateYAMLTemplate_SliceExamples(t *testing.T) {
+	type Upstream struct {
+		URL string `yaml:"url" default:"http://localhost"`
+	}
+	cfg := struct {
+		Upstreams []Upstream `yaml:"upstreams"`
+		Mirrors   []Upstream `yaml:"mirrors" examples:"0"`
+		Backups   []Upstream `yaml:"backups" examples:"1" help:"Backup servers"`
+	}{}
+	yamlTemplate, err := GenerateYAMLTemplateE(cfg, WithSliceExamples(2))
+	require.NoError(t, err)
+
+	expected := `upstreams:
+  -
+    url: "http://localhost"
+  -
+    url: "http://localhost"
+mirrors: [] # Array of items
+backups:    # Backup servers
+  -
+    url: "http://localhost"
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	expected = `upstreams: [] # Array of items
+mirrors: []   # Array of items
+backups:      # Backup servers
+  -
+    url: "http://localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithSliceExamples(0)))
+
+	_, err = GenerateYAMLTemplateE(struct {
+		Upstreams []Upstream `yaml:"upstreams" examples:"many"`
+	}{})
+	assert.ErrorContains(t, err, `invalid examples tag "many" on field upstreams`)
+}
+
+// Test compact YAML generation with a single space before every comment.
+func TestGenerateYAMLTemplate_Compact(t *testing.T) {
+	cfg := struct {
+		Host                string `yaml:"host" default:"localhost" help:"The hostname"`
+		MaxConnectionsPerIP int    `yaml:"max_connections_per_ip" default:"100" help:"Connection limit"`
+		Meta                struct {
+			Version string `yaml:"version" default:"1.0" help:"App version"`
+		} `yaml:"meta" help:"Metadata"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, WithCompact(), WithFixedCommentColumn(40))
+
+	expected := `host: "localhost" # The hostname
+max_connections_per_ip: 100 # Connection limit
+meta: # Metadata
+  version: "1.0" # App version
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+	for _, line := range strings.Split(strings.TrimSuffix(yamlTemplate, "\n"), "\n") {
+		assert.Contains(t, line, " # ", "Every line should have a comment")
+		assert.NotContains(t, line, "  #", "Comments should not be padded")
+	}
+}
+
+// Test YAML generation with min/max constraints appended to the comments.
+func TestGenerateYAMLTemplate_Range(t *testing.T) {
+	cfg := struct {
+		Port    int `yaml:"port" default:"8080" min:"1" max:"65535" help:"The port number"`
+		Workers int `yaml:"workers" default:"4" min:"1"`
+		Retries int `yaml:"retries" default:"3" max:"10"`
+		Offset  int `yaml:"offset" default:"0" min:"-10" max:"10"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `port: 8080 # The port number; range: 1-65535
+workers: 4 # min: 1
+retries: 3 # max: 10
+offset: 0  # range: -10 to 10
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation with units of measurement in the comments.
+func TestGenerateYAMLTemplate_Unit(t *testing.T) {
+	cfg := struct {
+		Timeout int `yaml:"timeout" default:"500" unit:"ms" help:"Request timeout" min:"1"`
+		Cache   int `yaml:"cache" default:"64" unit:"MB"`
+		Expiry  int `yaml:"expiry" default:"60" units:"seconds" help:"Cache expiry"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+
+	expected := `timeout: 500 # Request timeout (ms); min: 1
+cache: 64    # (MB)
+expiry: 60   # Cache expiry (seconds)
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test the unit display policies, with the unit of durations inferred from their type.
+func TestGenerateYAMLTemplate_UnitDisplay(t *testing.T) {
+	cfg := struct {
+		Timeout  int           `yaml:"timeout" default:"30" unit:"seconds" help:"Request timeout"`
+		Interval time.Duration `yaml:"interval" default:"1m" help:"Poll interval"`
+		Grace    int           `yaml:"grace" default:"5000" unit:"ms"`
+	}{}
+
+	expected := `timeout: 30  # Request timeout (seconds)
+interval: 1m # Poll interval
+grace: 5000  # (ms)
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg), "Only tagged units should be shown by default")
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithUnitDisplay(UnitDisplayWhenTagged)))
+
+	expected = `timeout: 30  # Request timeout (seconds)
+interval: 1m # Poll interval (duration, e.g. 30s)
+grace: 5000  # (ms)
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithUnitDisplay(UnitDisplayAlways)))
+
+	expected = `timeout: 30  # Request timeout
+interval: 1m # Poll interval
+grace: 5000
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, WithUnitDisplay(UnitDisplayNever)))
+}
+
+// Test YAML generation with a schema directive.
+func TestGenerateYAMLTemplate_SchemaURL(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, WithSchemaURL("https://example.com/config.schema.json"))
+
+	expected := `# yaml-language-server: $schema=https://example.com/config.schema.json
+host: "localhost" # The hostname
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test the framing of the generated YAML with the directive, the leading separator and the trailing newlines.
+func TestGenerateYAMLTemplate_Framing(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port int    `yaml:"port" default:"8080"`
+	}{}
+	body := "host: \"localhost\" # The hostname\nport: 8080"
+
+	tests := []struct {
+		name     string
+		opts     []TemplateOption
+		expected string
+	}{
+		{"default", nil, body + "\n"},
+		{"leading separator", []TemplateOption{WithLeadingSeparator()}, "---\n" + body + "\n"},
+		{"directive", []TemplateOption{WithYAMLDirective()}, "%YAML 1.2\n---\n" + body + "\n"},
+		{"directive and separator", []TemplateOption{WithYAMLDirective(), WithLeadingSeparator()}, "%YAML 1.2\n---\n" + body + "\n"},
+		{"no trailing newline", []TemplateOption{WithTrailingNewlines(0)}, body},
+		{"two trailing newlines", []TemplateOption{WithTrailingNewlines(2)}, body + "\n\n"},
+		{"separator without trailing newline", []TemplateOption{WithLeadingSeparator(), WithTrailingNewlines(0)}, "---\n" + body},
+		{
+			"directive with schema URL",
+			[]TemplateOption{WithYAMLDirective(), WithSchemaURL("https://example.com/config.schema.json")},
+			"%YAML 1.2\n---\n# yaml-language-server: $schema=https://example.com/config.schema.json\n" + body + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlTemplate := GenerateYAMLTemplate(cfg, tt.opts...)
+			assert.Equal(t, tt.expected, yamlTemplate)
+
+			// The YAML decoder only accepts YAML 1.1 directives
+			yamlTemplate = strings.Replace(yamlTemplate, "%YAML 1.2", "%YAML 1.1", 1)
+			var decoded map[string]any
+			require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded), "Framed template should be valid YAML")
+			assert.Equal(t, "localhost", decoded["host"])
+		})
+	}
+
+	// Concatenated templates form a multi-document stream
+	stream := GenerateYAMLTemplate(cfg, WithLeadingSeparator()) + GenerateYAMLTemplate(cfg, WithLeadingSeparator())
+	decoder := yaml.NewDecoder(strings.NewReader(stream))
+	documents := 0
+	for {
+		var decoded map[string]any
+		if err := decoder.Decode(&decoded); err != nil {
+			break
+		}
+		documents++
+	}
+	assert.Equal(t, 2, documents, "Each template should be a separate document")
+
+	_, err := MergeTemplate([]byte("host: example\n"), cfg, WithYAMLDirective())
+	assert.NoError(t, err, "Merging should not parse the directive")
+}
+
+// Test YAML generation of duration slices, whose items are quoted.
+func TestGenerateYAMLTemplate_DurationSlice(t *testing.T) {
+	cfg := struct {
+		Backoffs []time.Duration `yaml:"backoffs" default:"1s, 2s,4s" help:"Retry backoffs"`
+		Delays   []time.Duration `yaml:"delays,flow" default:"100ms"`
+	}{}
+
+	expected := `backoffs:         # Retry backoffs
+  - "1s"
+  - "2s"
+  - "4s"
+delays: ["100ms"]
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg))
+
+	var decoded struct {
+		Backoffs []time.Duration `yaml:"backoffs"`
+		Delays   []time.Duration `yaml:"delays"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(GenerateYAMLTemplate(cfg)), &decoded))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, decoded.Backoffs)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond}, decoded.Delays)
+
+	value := struct {
+		Backoffs []time.Duration `yaml:"backoffs"`
+	}{Backoffs: []time.Duration{time.Minute}}
+	assert.Equal(t, "backoffs:\n  - \"1m0s\"\n", GenerateYAMLFromValue(value))
+}
+
+// appLevel is an application-specific level whose zero value is not a meaningful default.
+type appLevel struct {
+	name string
+}
+
+func (l *appLevel) UnmarshalText(text []byte) error {
+	l.name = string(text)
+	return nil
+}
+
+func (l appLevel) MarshalText() ([]byte, error) {
+	return []byte(l.name), nil
+}
+
+// Test that the default provider supplies the default of fields of types without a default tag.
+func TestGenerateYAMLTemplate_DefaultProvider(t *testing.T) {
+	cfg := struct {
+		Level    appLevel  `yaml:"level" help:"Log level"`
+		Fallback *appLevel `yaml:"fallback"`
+		Audit    appLevel  `yaml:"audit" default:"warn"`
+		Retries  int       `yaml:"retries"`
+	}{}
+	provider := WithDefaultProvider(func(t reflect.Type) (string, bool) {
+		if t == reflect.TypeOf(appLevel{}) {
+			return "info", true
+		}
+		return "", false
+	})
+
+	expected := `level: "info"    # Log level
+fallback: "info"
+audit: "warn"
+retries: null
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, provider))
+	assert.Contains(t, GenerateYAMLTemplate(cfg), "level: null", "Without a provider the field should have no default")
+
+	cfg.Level = appLevel{name: "debug"}
+	assert.Contains(t, GenerateYAMLFromValue(cfg, provider), `level: "debug"`, "Values should take precedence over provided defaults")
+}
+
+// Test YAML generation of map examples embedded from the map_example tag and the provider option.
+func TestGenerateYAMLTemplate_MapExample(t *testing.T) {
+	type Config struct {
+		Plugin struct {
+			Settings map[string]interface{} `yaml:"settings" map_example:"key1: val1\nkey2: 42\nnested:\n  enabled: true" help:"Plugin settings"`
+			Name     string                 `yaml:"name" default:"auth"`
+		} `yaml:"plugin"`
+		Labels map[string]string `yaml:"labels"`
+		Extra  map[string]string `yaml:"extra"`
+	}
+	provider := func(field reflect.StructField) string {
+		if field.Name == "Labels" {
+			return "team: core"
+		}
+		return ""
+	}
+	yamlTemplate := GenerateYAMLTemplate(Config{}, WithMapExampleProvider(provider))
+
+	expected := `plugin:
+  settings:    # Plugin settings
+    key1: val1
+    key2: 42
+    nested:
+      enabled: true
+  name: "auth"
+labels:
+  team: core
+extra:
+  key: value # Map example
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	var decoded Config
+	require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded))
+	assert.Equal(t, map[string]interface{}{"key1": "val1", "key2": 42, "nested": map[string]interface{}{"enabled": true}}, decoded.Plugin.Settings)
+	assert.Equal(t, "auth", decoded.Plugin.Name)
+	assert.Equal(t, map[string]string{"team": "core"}, decoded.Labels)
+}
+
+// Test YAML generation of several numbered map examples, with blocks for maps of structs.
+func TestGenerateYAMLTemplate_MapExampleCount(t *testing.T) {
+	type Backend struct {
+		URL    string `yaml:"url" default:"http://localhost"`
+		Weight int    `yaml:"weight" default:"1"`
+	}
+	type Config struct {
+		Labels   map[string]string  `yaml:"labels" help:"Labels"`
+		Backends map[string]Backend `yaml:"backends"`
+		Extra    map[string]string  `yaml:"extra" map_example:"team: core"`
+	}
+	yamlTemplate := GenerateYAMLTemplate(Config{}, WithMapExampleCount(2))
+
+	expected := `labels:   # Labels
+  example1: value1
+  example2: value2
+backends:
+  example1:
+    url: "http://localhost"
+    weight: 1
+  example2:
+    url: "http://localhost"
+    weight: 1
+extra:
+  team: core
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	var decoded Config
+	require.NoError(t, yaml.Unmarshal([]byte(yamlTemplate), &decoded))
+	assert.Equal(t, map[string]string{"example1": "value1", "example2": "value2"}, decoded.Labels)
+	assert.Equal(t, map[string]Backend{
+		"example1": {URL: "http://localhost", Weight: 1},
+		"example2": {URL: "http://localhost", Weight: 1},
+	}, decoded.Backends)
+
+	assert.Contains(t, GenerateYAMLTemplate(Config{}), "labels:   # Labels\n  key: value # Map example\n", "One example should be the default")
+	assert.Contains(t, GenerateYAMLTemplate(Config{}, WithMapExampleCount(0)), "labels: {}   # Labels\n")
+}
+
+// Builds a configuration struct with the given number of documented fields.
+func largeConfig(fields int) interface{} {
+	structFields := make([]reflect.StructField, fields)
+	for i := range structFields {
+		structFields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(fmt.Sprintf(`yaml:"field_%d" default:"%d" help:"Field number %d"`, i, i, i)),
+		}
+	}
+	return reflect.New(reflect.StructOf(structFields)).Elem().Interface()
+}
+
+func BenchmarkGenerateYAMLTemplate_Aligned(b *testing.B) {
+	cfg := largeConfig(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateYAMLTemplate(cfg)
+	}
+}
+
+func BenchmarkGenerateYAMLTemplate_FixedCommentColumn(b *testing.B) {
+	cfg := largeConfig(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateYAMLTemplate(cfg, WithFixedCommentColumn(40))
+	}
+}
@@ -0,0 +1,130 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// bareTOMLKey matches the keys that can be written without quotes in TOML.
+	bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	// tomlNumber matches the decimal integers and floats of TOML.
+	tomlNumber = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+	// tomlHex matches the hexadecimal integers of TOML, which cannot have a sign.
+	tomlHex = regexp.MustCompile(`^0x[0-9A-Fa-f]+$`)
+)
+
+// GenerateTOMLTemplate generates a TOML template from a given configuration struct.
+// Nested structs become `[section]` tables, slices of structs become `[[section]]` arrays of tables,
+// and help text is rendered as `# comment` lines above each key. Defaults and quoting follow the same
+// rules as GenerateYAMLTemplate. TOML has no null, so keys without a value are written commented out.
+// Key names are taken from the `toml` tag, falling back to `yaml`, `json`, `kong` and the field name.
+func GenerateTOMLTemplate(cfg interface{}, opts ...TemplateOption) string {
+	options := applyTemplateOptions(opts)
+	nodes, _ := buildConfigTreeWithKeys(cfg, options, append([]string{"toml"}, keyTags...))
+
+	var builder strings.Builder
+	writeTOMLTable(&builder, nodes, "")
+	return builder.String()
+}
+
+// Writes the plain keys of a table followed by its nested tables.
+// Nested tables are written after all plain keys, so that no key ends up in the wrong table.
+func writeTOMLTable(builder *strings.Builder, nodes []*Node, table string) {
+	for _, node := range nodes {
+		if node.Kind == KindStruct || (node.Kind == KindStructList && node.examples > 0) {
+			continue
+		}
+		writeTOMLComment(builder, node.comment)
+
+		key := tomlKey(node.Name)
+		switch node.Kind {
+		case KindStructList:
+			builder.WriteString(fmt.Sprintf("%s = []\n", key))
+
+		case KindScalar:
+			if node.value.null {
+				builder.WriteString(fmt.Sprintf("# %s =\n", key))
+				break
+			}
+			builder.WriteString(fmt.Sprintf("%s = %s\n", key, tomlLiteral(node.value)))
+
+		case KindList:
+			items := make([]string, len(node.items))
+			for i, item := range node.items {
+				items[i] = tomlLiteral(item)
+			}
+			builder.WriteString(fmt.Sprintf("%s = [%s]\n", key, strings.Join(items, ", ")))
+
+		case KindMap:
+			entries := make([]string, len(node.entries))
+			for i, entry := range node.entries {
+				entries[i] = fmt.Sprintf("%s = %s", tomlKey(entry.key), tomlLiteral(entry.value))
+			}
+			if len(entries) == 0 {
+				builder.WriteString(fmt.Sprintf("%s = {}\n", key))
+				break
+			}
+			builder.WriteString(fmt.Sprintf("%s = { %s }\n", key, strings.Join(entries, ", ")))
+		}
+	}
+
+	for _, node := range nodes {
+		name := tomlKey(node.Name)
+		if table != "" {
+			name = table + "." + name
+		}
+
+		switch node.Kind {
+		case KindStruct:
+			writeTOMLHeader(builder, "["+name+"]", node.comment)
+			writeTOMLTable(builder, node.Children, name)
+
+		case KindStructList:
+			for i := 0; i < node.examples; i++ {
+				writeTOMLHeader(builder, "[["+name+"]]", node.comment)
+				writeTOMLTable(builder, node.Children, name)
+			}
+		}
+	}
+}
+
+// Writes a table header preceded by an empty line, unless it starts the template, and its help text.
+func writeTOMLHeader(builder *strings.Builder, header, help string) {
+	if builder.Len() > 0 {
+		builder.WriteString("\n")
+	}
+	writeTOMLComment(builder, help)
+	builder.WriteString(header + "\n")
+}
+
+// Writes a `# comment` line if the help text is not empty.
+func writeTOMLComment(builder *strings.Builder, help string) {
+	if help != "" {
+		builder.WriteString("# " + help + "\n")
+	}
+}
+
+// Returns a key as written in TOML, quoting keys that contain characters not allowed in bare keys.
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	return jsonString(key)
+}
+
+// Returns the TOML literal of a scalar. Strings are quoted, and so are bare values that are not
+// TOML integers, floats or booleans, such as durations. Negative hexadecimal integers are written
+// in decimal, as TOML only has unsigned hexadecimal literals.
+func tomlLiteral(value scalar) string {
+	if !value.quoted && (value.text == "true" || value.text == "false" || tomlNumber.MatchString(value.text) ||
+		tomlHex.MatchString(value.text)) {
+		return value.text
+	}
+	if decimal, ok := hexDecimal(value.text); ok && !value.quoted {
+		return decimal
+	}
+	// JSON string escaping is valid in TOML basic strings
+	return jsonString(value.text)
+}
@@ -0,0 +1,123 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tomlConfig struct {
+	Host    string        `toml:"host" default:"localhost" help:"The hostname"`
+	Port    int           `yaml:"port" default:"8080" help:"The port number"`
+	Timeout time.Duration `yaml:"timeout" default:"30s"`
+	Name    string        `yaml:"name"`
+	Tags    []string      `yaml:"tags" default:"a,b"`
+	Labels  map[string]string
+	Server  struct {
+		Ratio float64 `yaml:"ratio" default:"0.5"`
+		TLS   struct {
+			Enabled bool `yaml:"enabled" default:"true"`
+		} `yaml:"tls"`
+	} `yaml:"server" help:"Server settings"`
+	Upstreams []struct {
+		URL    string `json:"url" default:"http://localhost"`
+		Weight int    `yaml:"weight" default:"1"`
+	} `yaml:"upstreams" help:"Upstream servers"`
+}
+
+// Test TOML generation with tables, arrays of tables and comments.
+func TestGenerateTOMLTemplate(t *testing.T) {
+	expected := `# The hostname
+host = "localhost"
+# The port number
+port = 8080
+timeout = "30s"
+# name =
+tags = ["a", "b"]
+labels = {}
+
+# Server settings
+[server]
+ratio = 0.5
+
+[server.tls]
+enabled = true
+
+# Upstream servers
+[[upstreams]]
+url = "http://localhost"
+weight = 1
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(tomlConfig{}))
+}
+
+// Test that the generated TOML unmarshals back into the struct.
+func TestGenerateTOMLTemplate_RoundTrip(t *testing.T) {
+	var cfg tomlConfig
+	_, err := toml.Decode(GenerateTOMLTemplate(tomlConfig{}), &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 30*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, 0.5, cfg.Server.Ratio)
+	assert.True(t, cfg.Server.TLS.Enabled)
+	require.Len(t, cfg.Upstreams, 1)
+	assert.Equal(t, "http://localhost", cfg.Upstreams[0].URL)
+	assert.Equal(t, 1, cfg.Upstreams[0].Weight)
+}
+
+// Test that keys which are not valid bare keys are quoted.
+func TestGenerateTOMLTemplate_QuotedKeys(t *testing.T) {
+	cfg := struct {
+		Host string `toml:"server.host" default:"localhost"`
+	}{}
+
+	assert.Equal(t, "\"server.host\" = \"localhost\"\n", GenerateTOMLTemplate(cfg))
+}
+
+// Test that map defaults are written as inline tables.
+func TestGenerateTOMLTemplate_MapDefault(t *testing.T) {
+	cfg := struct {
+		Labels  map[string]string `yaml:"labels" default:"env=prod,team=core"`
+		Weights map[string]int    `yaml:"weights" default:"a=1"`
+	}{}
+
+	assert.Equal(t, "labels = { env = \"prod\", team = \"core\" }\nweights = { a = 1 }\n", GenerateTOMLTemplate(cfg))
+}
+
+// Test that slices of structs are written with the configured number of example tables.
+func TestGenerateTOMLTemplate_SliceExamples(t *testing.T) {
+	cfg := struct {
+		Servers []struct {
+			Name string `yaml:"name" default:"main"`
+		} `yaml:"servers" examples:"2"`
+		Mirrors []struct {
+			Name string `yaml:"name"`
+		} `yaml:"mirrors" examples:"0"`
+	}{}
+
+	expected := "mirrors = []\n\n[[servers]]\nname = \"main\"\n\n[[servers]]\nname = \"main\"\n"
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg))
+}
+
+// Test that hexadecimal integers are written as TOML integer literals and decode into the struct.
+func TestGenerateTOMLTemplate_Hex(t *testing.T) {
+	type Config struct {
+		Mask   int    `toml:"mask" default:"255" format:"hex"`
+		Offset int    `toml:"offset" default:"-16" format:"hex"`
+		Flags  []uint `toml:"flags" default:"16,0x20" format:"hex"`
+	}
+	tomlTemplate := GenerateTOMLTemplate(Config{})
+	assert.Equal(t, "mask = 0xff\noffset = -16\nflags = [0x10, 0x20]\n", tomlTemplate)
+
+	var cfg Config
+	_, err := toml.Decode(tomlTemplate, &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, Config{Mask: 255, Offset: -16, Flags: []uint{16, 32}}, cfg)
+}
@@ -0,0 +1,559 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Kind classifies a configuration field by the shape of its value.
+type Kind int
+
+const (
+	// KindScalar is a single value.
+	KindScalar Kind = iota
+	// KindStruct is a nested struct whose fields are the children of the node.
+	KindStruct
+	// KindStructList is a slice of structs; the children describe the fields of a single element.
+	KindStructList
+	// KindList is a slice of scalars.
+	KindList
+	// KindMap is a map.
+	KindMap
+)
+
+// String returns the name of the kind, e.g. "struct".
+func (k Kind) String() string {
+	switch k {
+	case KindScalar:
+		return "scalar"
+	case KindStruct:
+		return "struct"
+	case KindStructList:
+		return "struct list"
+	case KindList:
+		return "list"
+	case KindMap:
+		return "map"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// scalar is a format-independent scalar value.
+type scalar struct {
+	// text is the value as written in the default tag or produced by the field value.
+	text string
+	// quoted reports whether the value is a string rather than a number, boolean or other bare literal.
+	quoted bool
+	// null reports that no value is known.
+	null bool
+}
+
+// Node is a field of a configuration struct resolved from its tags, as parsed by ParseConfigTree.
+// The template generators render the same tree, so that all output formats follow the same rules.
+type Node struct {
+	// Name is the key of the field, resolved from the yaml, json and kong tags. It is empty for the root node.
+	Name string
+	// Path is the dot-separated path of keys from the root struct to the field.
+	Path string
+	// Kind is the shape of the value of the field.
+	Kind Kind
+	// Default is the value of the `default` tag, or the default of WithDefaultProvider.
+	Default string
+	// Example is the value of the `example` tag.
+	Example string
+	// Placeholder is the value of the `placeholder` tag.
+	Placeholder string
+	// Help is the value of the `help` tag.
+	Help string
+	// Required reports whether the field is tagged as required.
+	Required bool
+	// Enum lists the allowed values from the `enum` tag.
+	Enum []string
+	// Deprecated is the value of the `deprecated` tag, e.g. "use server.listen instead".
+	Deprecated string
+	// Group is the value of the `group` tag; the fields of a group are rendered together under a header.
+	Group string
+	// OneOf reports whether the field is tagged with `oneof:"true"`, marking the fields of its group as
+	// mutually exclusive alternatives.
+	OneOf bool
+	// Children are the fields of a struct, or of a single element of a slice of structs.
+	Children []*Node
+
+	field reflect.StructField
+	// comment is the rendered comment of the field: the help text with annotations such as the range or "(optional)".
+	comment string
+
+	// value is the value of a scalar node.
+	value scalar
+	// items are the items of a list node; empty when the slice has no default.
+	items []scalar
+	// fromValue reports whether the items of a list node come from the field value rather than the default tag.
+	fromValue bool
+	// flow reports whether a list node is tagged with `yaml:",flow"`.
+	flow bool
+	// mapExample is the YAML example of the entries of a map node, empty for the default placeholder.
+	mapExample string
+	// fromExample reports whether the value of the node comes from the `example` tag.
+	fromExample bool
+	// examples is the number of example entries of a struct list node, see WithSliceExamples,
+	// or of a map node without entries, see WithMapExampleCount.
+	examples int
+	// mapElement are the nodes of the fields of the struct elements of a map node, nil for other elements.
+	mapElement []*Node
+	// entries are the entries of a map node parsed from the default tag, see parseMapDefault.
+	entries []mapEntry
+	// fieldValue is the value of the field in the configuration, invalid for the fields of slice elements.
+	fieldValue reflect.Value
+	// elements are the nodes of the actual elements of a struct list node, see GenerateYAMLDiffTemplate.
+	elements [][]*Node
+}
+
+// mapEntry is an entry of a map node.
+type mapEntry struct {
+	key   string
+	value scalar
+}
+
+// ParseConfigTree parses a configuration struct into a tree of its fields, for tools that render
+// configurations themselves, e.g. a configuration editor. The root node describes the struct itself.
+// The options affecting the fields, such as WithSort or WithSkipOmitempty, apply to the tree as well.
+// Problems found in the struct, such as conflicting keys, are reported with the tree.
+func ParseConfigTree(cfg interface{}, opts ...TemplateOption) (*Node, error) {
+	nodes, err := buildConfigTree(cfg, applyTemplateOptions(opts))
+	return &Node{Kind: KindStruct, Children: nodes}, err
+}
+
+// treeBuilder builds the configuration tree of a struct and collects the problems found in it.
+type treeBuilder struct {
+	options *Options
+	errs    []error
+
+	// keyTags are the tags the key names are resolved from, as in fieldKey.
+	keyTags []string
+
+	// keys holds the keys already used under each parent path, to detect conflicts.
+	keys map[string]map[string]bool
+}
+
+// Builds the configuration tree of a struct, or a pointer to a struct, with keys resolved from the yaml, json
+// and kong tags. The tree is returned even when an error is reported, except for values that are not structs.
+func buildConfigTree(cfg interface{}, options *Options) ([]*Node, error) {
+	return buildConfigTreeWithKeys(cfg, options, keyTags)
+}
+
+// Builds the configuration tree of a struct with keys resolved from the given tags.
+// A "-" value in the first of the tags set on a field excludes it, see isIgnoredField.
+func buildConfigTreeWithKeys(cfg interface{}, options *Options, keyTags []string) ([]*Node, error) {
+	t, v, err := configStruct(cfg)
+	if err != nil {
+		return nil, err
+	}
+	b := &treeBuilder{
+		options: options,
+		keyTags: keyTags,
+		keys:    make(map[string]map[string]bool),
+	}
+	nodes := b.build(t, v, "")
+	return nodes, errors.Join(b.errs...)
+}
+
+// Records a key used under a parent path and reports a conflict if the key is already present.
+func (b *treeBuilder) addKey(parent, key, path string) {
+	if b.keys[parent] == nil {
+		b.keys[parent] = make(map[string]bool)
+	}
+	if b.keys[parent][key] {
+		b.errs = append(b.errs, fmt.Errorf("duplicate key %q at %s", key, path))
+		return
+	}
+	b.keys[parent][key] = true
+}
+
+// Recursively builds the nodes of the fields of a struct.
+func (b *treeBuilder) build(t reflect.Type, v reflect.Value, parent string) []*Node {
+	options := b.options
+	var nodes []*Node
+
+	for _, i := range b.fieldOrder(t, parent) {
+		field := t.Field(i)
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		// Handle ignored fields
+		if isIgnoredField(field, b.keyTags) {
+			continue
+		}
+
+		// Determine the key name
+		fieldName := fieldKey(field, b.options.keyNaming(), b.keyTags...)
+
+		path := fieldName
+		if parent != "" {
+			path = parent + "." + fieldName
+		}
+
+		// Inlined structs merge their keys into the parent
+		if inlined, ok := inlinedStruct(field, b.keyTags); ok {
+			nodes = append(nodes, b.build(inlined, inlinedValue(inlined, v.Field(i)), parent)...)
+			continue
+		}
+
+		// Deprecated fields are left out of fresh templates if configured
+		if options.omitDeprecated && field.Tag.Get("deprecated") != "" {
+			continue
+		}
+
+		// Fields tagged with omitempty are optional
+		optional := hasTagOption(field, "yaml", "omitempty")
+		if optional && options.skipOmitempty {
+			continue
+		}
+
+		b.addKey(parent, fieldName, path)
+
+		node := b.buildNode(field, v.Field(i), fieldName, path)
+		if node.fromExample {
+			node.comment = joinComment(node.comment, "(example)")
+		}
+		if optional {
+			if node.isEmpty() {
+				if options.omitEmpty {
+					continue
+				}
+				node.comment = joinComment(node.comment, "(optional, omitted when empty)")
+			} else {
+				node.comment = joinComment(node.comment, "(optional)")
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Builds the node of a single field from its tags and value.
+func (b *treeBuilder) buildNode(field reflect.StructField, v reflect.Value, key, path string) *Node {
+	options := b.options
+	defaultValue, isExample := fieldTemplateValue(field)
+	provided := false
+	if defaultValue == "" && options.defaultProvider != nil {
+		t := field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		defaultValue, provided = options.defaultProvider(t)
+		if !provided {
+			defaultValue = ""
+		}
+	}
+	node := &Node{
+		Name:        key,
+		Path:        path,
+		Default:     field.Tag.Get("default"),
+		Example:     field.Tag.Get("example"),
+		Placeholder: field.Tag.Get("placeholder"),
+		Help:        field.Tag.Get("help"),
+		Required:    isKongRequired(field),
+		Deprecated:  field.Tag.Get("deprecated"),
+		Group:       field.Tag.Get("group"),
+		OneOf:       field.Tag.Get("oneof") == "true",
+		field:       field,
+		fieldValue:  v,
+	}
+	if provided {
+		node.Default = defaultValue
+	}
+	help := node.Help
+	if options.helpLocale != "" {
+		if translation, ok := lookupTranslation(options.helpLocale, path); ok {
+			help = translation
+		}
+	}
+	node.comment = joinComment(deprecationComment(field), joinRangeComment(joinComment(help, unitComment(field, options.unitDisplay)), field))
+	if enum := kongTagValue(field, "enum"); enum != "" {
+		for _, value := range strings.Split(enum, ",") {
+			node.Enum = append(node.Enum, strings.TrimSpace(value))
+		}
+	}
+
+	// Registered renderers take precedence over the built-in kind handling
+	if render, ok := lookupRenderer(field.Type); ok {
+		value, comment := render(field, defaultValue)
+		node.value = scalar{text: value, null: value == ""}
+		node.fromExample = isExample
+		node.comment = joinComment(node.comment, comment)
+		return node
+	}
+
+	// Types implementing encoding.TextUnmarshaler or encoding.TextMarshaler are scalars,
+	// so their fields are never traversed
+	if isTextScalar(field.Type) {
+		node.value = scalar{null: true}
+		if text, ok := valueText(v, options); ok {
+			node.value = scalar{text: text, quoted: true}
+		} else if defaultValue != "" {
+			node.value = scalar{text: defaultValue, quoted: true}
+			node.fromExample = isExample
+		} else if text, ok := zeroValueText(v); ok {
+			node.value = scalar{text: text, quoted: true}
+		} else if example, ok := scalarExample(field.Type); ok {
+			node.value = scalar{text: example, quoted: true}
+			node.fromExample = true
+		}
+		node.value.quoted = !node.value.null && formatQuoted(field, true)
+		return node
+	}
+
+	// Byte slices are strings rather than lists of numbers
+	if isBytesType(field.Type) {
+		node.value = scalar{null: true}
+		if options.fromValue && v.IsValid() && v.Len() > 0 {
+			node.value = scalar{text: bytesText(field, v.Bytes()), quoted: true}
+		} else if defaultValue != "" {
+			node.value = scalar{text: bytesText(field, []byte(defaultValue)), quoted: true}
+			node.fromExample = isExample
+		}
+		node.value.quoted = !node.value.null && formatQuoted(field, true)
+		return node
+	}
+
+	switch field.Type.Kind() {
+	case reflect.Struct:
+		node.Kind = KindStruct
+		node.Children = b.build(field.Type, v, path)
+
+	case reflect.Slice:
+		// Items of a type with a registered renderer are rendered by it, even for struct types
+		if render, ok := lookupRenderer(field.Type.Elem()); ok {
+			node.Kind = KindList
+			node.flow = hasTagOption(field, "yaml", "flow")
+			node.fromExample = isExample
+			// Without a default, the renderer is called with an empty value like for scalars
+			var comment string
+			for _, item := range strings.Split(defaultValue, ",") {
+				var text string
+				text, comment = render(field, strings.TrimSpace(item))
+				node.items = append(node.items, scalar{text: text, null: text == ""})
+			}
+			node.comment = joinComment(node.comment, comment)
+			break
+		}
+
+		// Handle array of structs
+		if isNestedStruct(field.Type.Elem()) {
+			node.Kind = KindStructList
+			// For anonymous structs or uninitialized fields, using the field value might result in invalid or zero values,
+			// especially if the struct field hasn't been initialized yet. Instead, we use reflect.Zero(field.Type)
+			// to create a zero value of the field's type. This ensures safe traversal and correct template generation
+			// even when the struct is empty or contains anonymous sub-structs.
+			node.Children = b.build(field.Type.Elem(), reflect.Zero(field.Type.Elem()), path)
+			node.examples = options.sliceExamples
+			if tagValue := field.Tag.Get("examples"); tagValue != "" {
+				examples, err := strconv.Atoi(tagValue)
+				if err != nil || examples < 0 {
+					b.errs = append(b.errs, fmt.Errorf("invalid examples tag %q on field %s", tagValue, path))
+				} else {
+					node.examples = examples
+				}
+			}
+			break
+		}
+
+		// Handle array of primitives
+		node.Kind = KindList
+		node.flow = hasTagOption(field, "yaml", "flow")
+		node.items, node.fromValue = sliceValueItems(v, options)
+		if !node.fromValue && defaultValue != "" {
+			node.fromExample = isExample
+			// Durations are quoted so that YAML decoders read them as strings
+			quoted := formatQuoted(field, isStringType(field.Type.Elem()) || field.Type.Elem() == durationType)
+			for _, item := range strings.Split(defaultValue, ",") {
+				text := b.normalizeDefault(field, field.Type.Elem(), strings.TrimSpace(item), path)
+				node.items = append(node.items, scalar{text: text, quoted: quoted})
+			}
+		}
+
+	case reflect.Map:
+		node.Kind = KindMap
+		pairs, err := parseMapDefault(defaultValue)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("invalid default of %s: %w", path, err))
+		}
+		// Values are quoted like the items of lists, so that numeric values stay bare
+		quoted := formatQuoted(field, isStringType(field.Type.Elem()) || field.Type.Elem() == durationType)
+		for _, pair := range pairs {
+			value := b.normalizeDefault(field, field.Type.Elem(), pair.value, path)
+			node.entries = append(node.entries, mapEntry{key: pair.key, value: scalar{text: value, quoted: quoted}})
+		}
+		node.fromExample = isExample && len(pairs) > 0
+		node.mapExample = field.Tag.Get("map_example")
+		if node.mapExample == "" && options.mapExampleProvider != nil {
+			node.mapExample = options.mapExampleProvider(field)
+		}
+		node.examples = options.mapExamples
+		if elem := field.Type.Elem(); isNestedStruct(elem) && node.examples > 0 {
+			node.mapElement = b.build(elem, reflect.Zero(elem), path)
+		}
+
+	default:
+		value := defaultValue
+		node.fromExample = isExample
+		if text, ok := valueText(v, options); ok {
+			value = text
+			node.fromExample = false
+			if field.Tag.Get("format") == "hex" {
+				value = formatHex(field.Type, value)
+			}
+		} else {
+			value = b.normalizeDefault(field, field.Type, value, path)
+		}
+		// Pointers to strings are quoted like strings, but stay a bare null when unset
+		quoted := formatQuoted(field, field.Type.Kind() == reflect.String || (value != "" && isStringType(field.Type)))
+		node.value = scalar{text: value, quoted: quoted, null: value == ""}
+	}
+	return node
+}
+
+// Returns the default value of a bool or numeric field in its canonical form, see normalizeDefault.
+// Invalid values are reported and rendered as they are. Placeholders are illustrative text
+// rather than values, so they are rendered as they are when the field has no default or example.
+func (b *treeBuilder) normalizeDefault(field reflect.StructField, t reflect.Type, text, path string) string {
+	if field.Tag.Get("default") == "" && field.Tag.Get("example") == "" {
+		return text
+	}
+	normalized, err := normalizeDefault(t, text)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("invalid default of %s: %w", path, err))
+	} else if field.Tag.Get("format") == "hex" {
+		normalized = formatHex(t, normalized)
+	}
+	return normalized
+}
+
+// Reports whether a node has no value, i.e. whether a field tagged with omitempty would be omitted.
+// Scalars are empty when their value is unknown or the zero value of their type.
+func (n *Node) isEmpty() bool {
+	switch n.Kind {
+	case KindScalar:
+		if n.value.null {
+			return true
+		}
+		value := typedDefault(n.field.Type, n.value.text)
+		return value == nil || reflect.ValueOf(value).IsZero()
+	case KindList:
+		return len(n.items) == 0
+	case KindMap:
+		return len(n.entries) == 0
+	default:
+		return false
+	}
+}
+
+// Returns the value of an inlined struct, dereferencing pointers and using a zero instance when nil.
+func inlinedValue(t reflect.Type, v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		return v.Elem()
+	}
+	return v
+}
+
+// Returns the comment of a field made of its help text, unit and range.
+func fieldComment(field reflect.StructField) string {
+	return joinRangeComment(joinComment(field.Tag.Get("help"), unitComment(field, UnitDisplayWhenTagged)), field)
+}
+
+// Returns the prefix of the comment of a field set by its `deprecated` tag, e.g. "DEPRECATED: use server.listen instead.",
+// or an empty string if the field is not deprecated. The message is terminated with a period to separate it from the help text.
+func deprecationComment(field reflect.StructField) string {
+	message := field.Tag.Get("deprecated")
+	if message == "" {
+		return ""
+	}
+	if !strings.HasSuffix(message, ".") && !strings.HasSuffix(message, "!") && !strings.HasSuffix(message, "?") {
+		message += "."
+	}
+	return "DEPRECATED: " + message
+}
+
+// UnitDisplayPolicy selects which units of measurement are shown in the comments of fields, see WithUnitDisplay.
+type UnitDisplayPolicy int
+
+const (
+	// UnitDisplayWhenTagged shows the units set by the `unit` tag.
+	UnitDisplayWhenTagged UnitDisplayPolicy = iota
+	// UnitDisplayAlways also shows the units implied by the type of untagged fields, e.g. "(duration)" for time.Duration.
+	UnitDisplayAlways
+	// UnitDisplayNever shows no units.
+	UnitDisplayNever
+)
+
+// Returns a comment with the unit of measurement set by the `unit` tag of a field, or its `units` alias,
+// e.g. "(seconds)", or an empty string if the field has no unit. With UnitDisplayAlways, the unit of untagged
+// fields is inferred from their type.
+func unitComment(field reflect.StructField, policy UnitDisplayPolicy) string {
+	if policy == UnitDisplayNever {
+		return ""
+	}
+	unit := field.Tag.Get("unit")
+	if unit == "" {
+		unit = field.Tag.Get("units")
+	}
+	if unit == "" && policy == UnitDisplayAlways {
+		unit = typeUnit(field.Type)
+	}
+	if unit != "" {
+		return "(" + unit + ")"
+	}
+	return ""
+}
+
+// Returns the unit of measurement implied by a type, or an empty string if there is none.
+// Durations are written with their unit in the value, e.g. "30s", which the comment reminds of.
+func typeUnit(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return "duration, e.g. 30s"
+	}
+	return ""
+}
+
+// Returns a comment describing the numeric constraints set by the `min` and `max` tags of a field,
+// e.g. "range: 1-65535", or an empty string if the field has no constraints. Negative bounds are
+// separated with " to ", e.g. "range: -10 to 10", so that the separator is not read as a minus sign.
+func rangeComment(field reflect.StructField) string {
+	minValue, maxValue := field.Tag.Get("min"), field.Tag.Get("max")
+	switch {
+	case minValue != "" && maxValue != "":
+		separator := "-"
+		if strings.HasPrefix(minValue, "-") || strings.HasPrefix(maxValue, "-") {
+			separator = " to "
+		}
+		return fmt.Sprintf("range: %s%s%s", minValue, separator, maxValue)
+	case minValue != "":
+		return fmt.Sprintf("min: %s", minValue)
+	case maxValue != "":
+		return fmt.Sprintf("max: %s", maxValue)
+	default:
+		return ""
+	}
+}
+
+// Appends the range note of a field to its comment, separated by a semicolon so that it stays apart from the help text.
+func joinRangeComment(comment string, field reflect.StructField) string {
+	note := rangeComment(field)
+	if comment == "" || note == "" {
+		return comment + note
+	}
+	return comment + "; " + note
+}
@@ -0,0 +1,166 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test parsing of a configuration struct into a tree.
+func TestParseConfigTree(t *testing.T) {
+	cfg := struct {
+		Host     string `yaml:"host" default:"localhost" help:"The hostname"`
+		LogLevel string `yaml:"log_level" placeholder:"info" enum:"debug, info" required:""`
+		Database struct {
+			DSN string `yaml:"dsn" help:"Connection string"`
+		} `yaml:"database"`
+		Servers []struct {
+			Name string `yaml:"name"`
+		} `yaml:"servers"`
+		Tags   []string          `yaml:"tags"`
+		Labels map[string]string `yaml:"labels"`
+	}{}
+	root, err := ParseConfigTree(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, KindStruct, root.Kind)
+	require.Len(t, root.Children, 6)
+
+	host := root.Children[0]
+	assert.Equal(t, "host", host.Name)
+	assert.Equal(t, KindScalar, host.Kind)
+	assert.Equal(t, "localhost", host.Default)
+	assert.Equal(t, "The hostname", host.Help)
+
+	logLevel := root.Children[1]
+	assert.Equal(t, "info", logLevel.Placeholder)
+	assert.True(t, logLevel.Required)
+	assert.Equal(t, []string{"debug", "info"}, logLevel.Enum)
+
+	database := root.Children[2]
+	assert.Equal(t, KindStruct, database.Kind)
+	require.Len(t, database.Children, 1)
+	assert.Equal(t, "dsn", database.Children[0].Name)
+	assert.Equal(t, "database.dsn", database.Children[0].Path)
+
+	servers := root.Children[3]
+	assert.Equal(t, KindStructList, servers.Kind)
+	require.Len(t, servers.Children, 1)
+	assert.Equal(t, "name", servers.Children[0].Name)
+
+	assert.Equal(t, KindList, root.Children[4].Kind)
+	assert.Equal(t, KindMap, root.Children[5].Kind)
+	assert.Equal(t, "struct list", KindStructList.String())
+}
+
+// Test that problems found in the struct are reported with the tree.
+func TestParseConfigTree_Conflict(t *testing.T) {
+	cfg := struct {
+		Name  string `yaml:"name"`
+		Alias string `yaml:"name"`
+	}{}
+	root, err := ParseConfigTree(cfg)
+
+	assert.EqualError(t, err, `duplicate key "name" at name`)
+	assert.Len(t, root.Children, 2)
+}
+
+// Test that pointers to structs are described like the structs, and that other values are rejected with an error.
+func TestParseConfigTree_PointerAndNonStruct(t *testing.T) {
+	type Config struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port int    `yaml:"port" default:"8080"`
+	}
+	cfg := Config{}
+
+	expected := GenerateYAMLTemplate(cfg)
+	template, err := GenerateYAMLTemplateE(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, expected, template, "A pointer should be described like the struct")
+	assert.Equal(t, expected, GenerateYAMLTemplate((*Config)(nil)), "A nil pointer should be described like the zero struct")
+
+	schema, err := GenerateJSONSchema(&cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), `"host"`)
+	report, err := CompareYAMLWithStruct([]byte("host: example.com\n"), &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []MissingKey{{Path: "port", Default: "8080"}}, report.Missing)
+	_, err = MergeTemplate([]byte("host: example.com\n"), &cfg)
+	require.NoError(t, err)
+	_, err = GenerateShellCompletion(&cfg, "app", ShellBash)
+	require.NoError(t, err)
+
+	for _, invalid := range []interface{}{42, "config", nil, []Config{}} {
+		_, err = GenerateYAMLTemplateE(invalid)
+		assert.ErrorContains(t, err, "must be a struct", invalid)
+		_, err = GenerateJSONSchema(invalid)
+		assert.ErrorContains(t, err, "must be a struct", invalid)
+		_, err = CompareYAMLWithStruct([]byte("host: example.com\n"), invalid)
+		assert.ErrorContains(t, err, "must be a struct", invalid)
+		_, err = MergeTemplate([]byte("host: example.com\n"), invalid)
+		assert.ErrorContains(t, err, "must be a struct", invalid)
+		_, err = GenerateShellCompletion(invalid, "app", ShellBash)
+		assert.ErrorContains(t, err, "must be a struct", invalid)
+	}
+}
+
+// Test that all generators agree with the tree on pointers to structs: a single optional value, not expanded.
+func TestParseConfigTree_PointerStructGenerators(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `yaml:"cert" env:"CERT" default:"cert.pem"`
+	}
+	cfg := struct {
+		Host  string     `yaml:"host" env:"APP_HOST" default:"localhost"`
+		TLS   *tlsConfig `yaml:"tls" env:"APP_TLS"`
+		Proxy *struct {
+			URL string `yaml:"url" default:"http://proxy"`
+		} `yaml:"proxy"`
+		Limits struct {
+			Rate int `yaml:"rate" default:"10"`
+		} `yaml:"limits"`
+	}{}
+
+	root, err := ParseConfigTree(cfg)
+	require.NoError(t, err)
+	var leaves []string
+	for _, node := range root.Children {
+		if node.Kind == KindStruct {
+			for _, child := range node.Children {
+				leaves = append(leaves, child.Path)
+			}
+			continue
+		}
+		assert.Empty(t, node.Children, node.Path)
+		leaves = append(leaves, node.Path)
+	}
+	assert.Equal(t, []string{"host", "tls", "proxy", "limits.rate"}, leaves)
+
+	var kongPaths []string
+	for _, field := range ParseKongTagsFromStruct(cfg) {
+		kongPaths = append(kongPaths, field.Path)
+	}
+	assert.Equal(t, leaves, kongPaths, "Kong fields should be the leaves of the tree")
+
+	assert.Equal(t, "host: \"localhost\"\ntls: null\nproxy: null\nlimits:\n  rate: 10\n", GenerateYAMLTemplate(cfg))
+
+	docs := GenerateMarkdownDocs(cfg)
+	assert.Contains(t, docs, "| `tls` | `tlsConfig` |")
+	assert.Contains(t, docs, "| `proxy` | `object` |")
+	assert.NotContains(t, docs, "tls.cert", "Pointers to structs should not be expanded in the docs")
+	assert.NotContains(t, docs, "proxy.url")
+
+	data, err := GenerateJSONSchema(cfg)
+	require.NoError(t, err)
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": []any{"object", "null"}}, properties["tls"])
+	assert.Equal(t, map[string]any{"type": []any{"object", "null"}}, properties["proxy"])
+	assert.NotContains(t, schema, "$defs")
+
+	assert.Equal(t, "APP_HOST=localhost\nAPP_TLS=\n", GenerateEnvTemplate(cfg))
+	assert.Equal(t, map[string]any{"host": "localhost", "tls": nil, "proxy": nil, "limits": map[string]any{"rate": 10}},
+		BuildDefaultMap(cfg))
+}
@@ -0,0 +1,196 @@
+package template
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateTemplate checks that the YAML template generated for a configuration struct round-trips into the struct.
+// The template is generated as by GenerateYAMLTemplate and decoded with yaml.Unmarshal into a new instance of the
+// struct type; the error of the decoder is returned if it fails. Otherwise every field with a `default` tag must be
+// decoded to its default, and all fields that are not are reported in the returned error.
+// Users can call it from their tests to make sure the template of their configuration stays valid.
+func ValidateTemplate(cfg interface{}) error {
+	t, _, err := configStruct(cfg)
+	if err != nil {
+		return err
+	}
+
+	options := applyTemplateOptions(nil)
+	template, err := generateYAML(reflect.Zero(t).Interface(), options)
+	if err != nil {
+		return err
+	}
+	// The defaults are checked against the nodes the template was generated from
+	nodes, _ := buildConfigTree(reflect.Zero(t).Interface(), options)
+
+	decoded := reflect.New(t)
+	if err := yaml.Unmarshal([]byte(template), decoded.Interface()); err != nil {
+		return fmt.Errorf("generated template does not decode into %s: %w", t, err)
+	}
+
+	var errs []error
+	checkDecodedDefaults(decoded.Elem(), nodes, "", &errs)
+	return errors.Join(errs...)
+}
+
+// Compares the decoded fields of a struct with the defaults of their nodes, walking the struct as the tree builder does.
+func checkDecodedDefaults(v reflect.Value, nodes []*Node, parent string, errs *[]error) {
+	byName := make(map[string]*Node, len(nodes))
+	for _, node := range nodes {
+		byName[node.Name] = node
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || isIgnoredField(field, keyTags) {
+			continue
+		}
+		if inlined, ok := inlinedStruct(field, keyTags); ok {
+			checkDecodedDefaults(inlinedValue(inlined, v.Field(i)), nodes, parent, errs)
+			continue
+		}
+
+		node := byName[fieldKey(field, keyNaming{}, keyTags...)]
+		if node == nil {
+			continue
+		}
+		path := joinPath(parent, node.Name)
+		value := v.Field(i)
+
+		switch node.Kind {
+		case KindStruct:
+			checkDecodedDefaults(value, node.Children, path, errs)
+		case KindStructList:
+			for j := 0; j < value.Len(); j++ {
+				checkDecodedDefaults(value.Index(j), node.Children, fmt.Sprintf("%s[%d]", path, j), errs)
+			}
+		case KindScalar, KindList, KindMap:
+			if node.Default == "" {
+				continue
+			}
+			expected, err := parseDefault(field.Type, node.Default)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("field %s: invalid default %q: %w", path, node.Default, err))
+				continue
+			}
+			if !reflect.DeepEqual(value.Interface(), expected.Interface()) {
+				*errs = append(*errs, fmt.Errorf("field %s: decoded %v, want default %q", path, describeValue(value), node.Default))
+			}
+		}
+	}
+}
+
+// Parses a default value into a value of the given type, independently of the YAML decoder.
+// Slices are split on commas as in the templates, and maps are parsed with parseMapDefault.
+func parseDefault(t reflect.Type, text string) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return value, unmarshaler.UnmarshalText([]byte(text))
+	}
+	if t == urlType {
+		parsed, err := url.Parse(text)
+		if err != nil {
+			return value, err
+		}
+		value.Set(reflect.ValueOf(*parsed))
+		return value, nil
+	}
+	if t == durationType {
+		duration, err := time.ParseDuration(text)
+		value.SetInt(int64(duration))
+		return value, err
+	}
+
+	if isBytesType(t) {
+		value.SetBytes([]byte(text))
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := parseDefault(t.Elem(), text)
+		if err != nil {
+			return value, err
+		}
+		value.Set(reflect.New(t.Elem()))
+		value.Elem().Set(elem)
+	case reflect.Slice:
+		items := strings.Split(text, ",")
+		value.Set(reflect.MakeSlice(t, len(items), len(items)))
+		for i, item := range items {
+			elem, err := parseDefault(t.Elem(), strings.TrimSpace(item))
+			if err != nil {
+				return value, err
+			}
+			value.Index(i).Set(elem)
+		}
+	case reflect.Map:
+		pairs, err := parseMapDefault(text)
+		if err != nil {
+			return value, err
+		}
+		value.Set(reflect.MakeMapWithSize(t, len(pairs)))
+		for _, pair := range pairs {
+			key, err := parseDefault(t.Key(), pair.key)
+			if err != nil {
+				return value, err
+			}
+			elem, err := parseDefault(t.Elem(), pair.value)
+			if err != nil {
+				return value, err
+			}
+			value.SetMapIndex(key, elem)
+		}
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := parseIntDefault(text, t.Bits())
+		if err != nil {
+			return value, err
+		}
+		value.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := parseUintDefault(text, t.Bits())
+		if err != nil {
+			return value, err
+		}
+		value.SetUint(number)
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return value, err
+		}
+		value.SetFloat(number)
+	case reflect.Bool:
+		boolean, err := parseBoolDefault(text)
+		if err != nil {
+			return value, err
+		}
+		value.SetBool(boolean)
+	default:
+		return value, fmt.Errorf("unsupported type %s", t)
+	}
+	return value, nil
+}
+
+// Formats a decoded value for error messages, dereferencing pointers.
+func describeValue(value reflect.Value) string {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "nil"
+		}
+		value = value.Elem()
+	}
+	return fmt.Sprintf("%#v", value.Interface())
+}
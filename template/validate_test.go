@@ -0,0 +1,119 @@
+package template
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type kitchenSinkCommon struct {
+	Region string `yaml:"region" default:"eu-west-1" help:"Deployment region"`
+}
+
+type kitchenSinkServer struct {
+	Name    string        `yaml:"name" default:"primary"`
+	Port    uint16        `yaml:"port" default:"8080"`
+	Timeout time.Duration `yaml:"timeout" default:"1m30s"`
+}
+
+type kitchenSinkConfig struct {
+	Common kitchenSinkCommon `yaml:",inline"`
+
+	Host      string            `yaml:"host" default:"localhost" help:"The hostname"`
+	Greeting  string            `yaml:"greeting" default:"say \"hi\": # not a comment"`
+	Path      string            `yaml:"path" default:"C:\\config"`
+	Port      int               `yaml:"port" default:"8080" min:"1" max:"65535"`
+	Ratio     float64           `yaml:"ratio" default:"0.75"`
+	Enabled   bool              `yaml:"enabled" default:"true"`
+	Level     string            `yaml:"level" default:"info" enum:"debug,info,warn"`
+	Retries   *int              `yaml:"retries" default:"3"`
+	Name      *string           `yaml:"name" default:"app: main"`
+	Optional  *string           `yaml:"optional"`
+	Timeout   time.Duration     `yaml:"timeout" default:"5s"`
+	Address   net.IP            `yaml:"address" default:"127.0.0.1"`
+	Tags      []string          `yaml:"tags" default:"a,b,c"`
+	Ports     []int             `yaml:"ports" default:"80,443"`
+	Intervals []time.Duration   `yaml:"intervals" default:"1s,1m0s"`
+	Empty     []string          `yaml:"empty"`
+	Labels    map[string]string `yaml:"labels"`
+	Limits    map[string]int    `yaml:"limits" default:"cpu=2, 'mem,max'=512"`
+	Database  struct {
+		DSN     string `yaml:"dsn" placeholder:"postgres://localhost/app"`
+		MaxOpen int    `yaml:"max_open,omitempty" default:"10"`
+		TLS     struct {
+			Enabled bool `yaml:"enabled" default:"false"`
+		} `yaml:"tls"`
+	} `yaml:"database"`
+	Servers []kitchenSinkServer `yaml:"servers"`
+	Ignored string              `yaml:"-"`
+	private string
+}
+
+// Test that the template of a struct covering all supported field kinds round-trips into the struct.
+func TestValidateTemplate(t *testing.T) {
+	require.NoError(t, ValidateTemplate(kitchenSinkConfig{}))
+	require.NoError(t, ValidateTemplate(&kitchenSinkConfig{}))
+}
+
+// Test that the placeholder entries of maps without a default are typed by the element type, so that they decode.
+func TestValidateTemplate_MapExamples(t *testing.T) {
+	type Backend struct {
+		URL string `yaml:"url" default:"http://localhost"`
+	}
+	cfg := struct {
+		Counts   map[string]int           `yaml:"counts"`
+		Ratios   map[string]float64       `yaml:"ratios"`
+		Flags    map[string]*bool         `yaml:"flags"`
+		Timeouts map[string]time.Duration `yaml:"timeouts"`
+		Hosts    map[string][]string      `yaml:"hosts"`
+		Backends map[string]Backend       `yaml:"backends"`
+	}{}
+	require.NoError(t, ValidateTemplate(cfg))
+
+	yamlTemplate := GenerateYAMLTemplate(cfg)
+	assert.Contains(t, yamlTemplate, "counts:\n  key: 0 ")
+	assert.Contains(t, yamlTemplate, "flags:\n  key: false ")
+	assert.Contains(t, yamlTemplate, "timeouts:\n  key: 0s ")
+	assert.Contains(t, yamlTemplate, "backends:\n  key:")
+	assert.Contains(t, GenerateYAMLTemplate(cfg, WithMapExampleCount(2)), "counts:\n  example1: 0\n  example2: 0\n")
+	for _, count := range []int{1, 2} {
+		var decoded struct {
+			Backends map[string]Backend `yaml:"backends"`
+		}
+		require.NoError(t, yaml.Unmarshal([]byte(GenerateYAMLTemplate(cfg, WithMapExampleCount(count))), &decoded))
+		assert.Len(t, decoded.Backends, count)
+	}
+}
+
+// Test that templates which do not round-trip are reported.
+func TestValidateTemplate_Errors(t *testing.T) {
+	mismatch := struct {
+		Port int `yaml:"port" default:"8080"`
+		Host struct {
+			Name string `yaml:"name" default:"localhost"`
+		} `yaml:"port"`
+	}{}
+	assert.ErrorContains(t, ValidateTemplate(mismatch), "duplicate key")
+
+	invalid := struct {
+		Port int `yaml:"port" default:"eighty"`
+	}{}
+	assert.ErrorContains(t, ValidateTemplate(invalid), `invalid default of port: invalid int "eighty"`)
+
+	type percent int
+	RegisterRenderer(reflect.TypeOf(percent(0)), func(field reflect.StructField, defaultVal string) (string, string) {
+		return "50", ""
+	})
+	defer RegisterRenderer(reflect.TypeOf(percent(0)), nil)
+	changed := struct {
+		Share percent `yaml:"share" default:"25"`
+	}{}
+	assert.ErrorContains(t, ValidateTemplate(changed), `field share: decoded 50, want default "25"`)
+
+	assert.ErrorContains(t, ValidateTemplate(42), "must be a struct")
+}
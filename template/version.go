@@ -0,0 +1,84 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaVersionKey is the key recording the schema version with WithSchemaVersionKey.
+const schemaVersionKey = "schema_version"
+
+// schemaVersionComment matches the comment recording the schema version with WithSchemaVersion.
+var schemaVersionComment = regexp.MustCompile(`(?m)^#+[ \t]*kongkit-schema:[ \t]*(\S+)[ \t]*$`)
+
+// schemaVersionCommentLine matches the lines of the comments recording a schema version, with their line break.
+var schemaVersionCommentLine = regexp.MustCompile(`(?m)^#+[ \t]*kongkit-schema:.*\n`)
+
+// SchemaVersionError reports a configuration file recording a schema version other than the current one,
+// see WithSchemaVersion.
+type SchemaVersionError struct {
+	// File is the version recorded in the file, empty if the file records none.
+	File string
+	// Current is the version of WithSchemaVersion.
+	Current string
+}
+
+func (e SchemaVersionError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("configuration file has no schema version, the current one is %q", e.Current)
+	}
+	return fmt.Sprintf("configuration file has schema version %q, the current one is %q", e.File, e.Current)
+}
+
+// ExtractSchemaVersion returns the schema version recorded in a YAML configuration file by WithSchemaVersion,
+// either as a `# kongkit-schema: <version>` comment or as a top-level `schema_version` key,
+// and whether the file records one.
+func ExtractSchemaVersion(yamlBytes []byte) (string, bool) {
+	if match := schemaVersionComment.FindSubmatch(yamlBytes); match != nil {
+		return string(match[1]), true
+	}
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(yamlBytes, &document); err != nil {
+		return "", false
+	}
+	if version, ok := document[schemaVersionKey]; ok && version != nil {
+		return fmt.Sprint(version), true
+	}
+	return "", false
+}
+
+// Returns the line recording the schema version of WithSchemaVersion, or an empty string without a version.
+func schemaVersionLine(options *Options) string {
+	switch {
+	case options.schemaVersion == "":
+		return ""
+	case options.schemaVersionKey:
+		return schemaVersionKey + ": " + strconv.Quote(options.schemaVersion) + "\n"
+	default:
+		return "# kongkit-schema: " + options.schemaVersion + "\n"
+	}
+}
+
+// Returns the error reporting a file recording a schema version other than the one of WithSchemaVersion, or nil.
+func checkSchemaVersion(yamlBytes []byte, options *Options) *SchemaVersionError {
+	if options.schemaVersion == "" {
+		return nil
+	}
+	if version, _ := ExtractSchemaVersion(yamlBytes); version != options.schemaVersion {
+		return &SchemaVersionError{File: version, Current: options.schemaVersion}
+	}
+	return nil
+}
+
+// Reports whether the top-level key recording the schema version is a field of the configuration struct.
+func isSchemaVersionField(nodes []*Node) bool {
+	for _, node := range nodes {
+		if node.Name == schemaVersionKey {
+			return true
+		}
+	}
+	return false
+}
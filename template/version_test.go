@@ -0,0 +1,49 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test recording the schema version in generated templates and reading it back.
+func TestWithSchemaVersion(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+	}{}
+
+	yamlTemplate := GenerateYAMLTemplate(cfg, WithSchemaVersion("v3"))
+	assert.Equal(t, "# kongkit-schema: v3\nhost: \"localhost\" # The hostname\n", yamlTemplate)
+	version, ok := ExtractSchemaVersion([]byte(yamlTemplate))
+	assert.True(t, ok)
+	assert.Equal(t, "v3", version)
+
+	yamlTemplate = GenerateYAMLTemplate(cfg, WithSchemaVersion("v3"), WithSchemaVersionKey())
+	assert.Equal(t, "schema_version: \"v3\"\nhost: \"localhost\" # The hostname\n", yamlTemplate)
+	version, ok = ExtractSchemaVersion([]byte(yamlTemplate))
+	assert.True(t, ok)
+	assert.Equal(t, "v3", version)
+
+	assert.NotContains(t, GenerateYAMLTemplate(cfg, WithSchemaVersionKey()), "schema_version", "The key needs a version")
+}
+
+// Test reading the schema version back from files written by hand.
+func TestExtractSchemaVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		version string
+		ok      bool
+	}{
+		{"# Production\n# kongkit-schema: 2024-01\nhost: a\n", "2024-01", true},
+		{"##  kongkit-schema:  v1  \n", "v1", true},
+		{"host: a\nschema_version: 4\n", "4", true},
+		{"host: a\n", "", false},
+		{"- a\n- b\n", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		version, ok := ExtractSchemaVersion([]byte(tt.input))
+		assert.Equal(t, tt.ok, ok, tt.input)
+		assert.Equal(t, tt.version, version, tt.input)
+	}
+}
@@ -0,0 +1,138 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// keyTags are the tags the key names of fields are resolved from, in order of precedence.
+// The json tag lets structs designed for JSON APIs produce reasonable keys without re-tagging.
+var keyTags = []string{"yaml", "json", "kong"}
+
+// structField describes a field reached while walking a configuration struct.
+type structField struct {
+	reflect.StructField
+
+	// Key is the resolved key name of the field.
+	Key string
+	// Path is the dot-separated path of keys from the root struct to the field.
+	Path string
+}
+
+// Returns the struct type and value of a configuration given as a struct or a pointer to a struct.
+// A nil pointer is described by the zero value of the struct. An error is returned for other values.
+func configStruct(cfg interface{}) (reflect.Type, reflect.Value, error) {
+	t, v := reflect.TypeOf(cfg), reflect.ValueOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsNil() {
+			v = reflect.Zero(t)
+		} else {
+			v = v.Elem()
+		}
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, reflect.Value{}, fmt.Errorf("configuration must be a struct or a pointer to a struct, got %T", cfg)
+	}
+	return t, v, nil
+}
+
+// Walks the exported, non-ignored fields of a struct depth-first and calls visit for every field.
+// Key names are resolved from keyTags and the naming strategy as in fieldKey. Nested structs, see isNestedStruct,
+// are descended into after visit returns true; inlined structs are merged into their parent. A root pointer to
+// a struct is dereferenced, and other types have no fields to walk.
+func walkStruct(t reflect.Type, parent string, keyTags []string, naming keyNaming, visit func(f structField) bool) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		// Handle ignored fields
+		if isIgnoredField(field, keyTags) {
+			continue
+		}
+
+		if inlined, ok := inlinedStruct(field, keyTags); ok {
+			walkStruct(inlined, parent, keyTags, naming, visit)
+			continue
+		}
+		isStruct := isNestedStruct(field.Type)
+
+		key := fieldKey(field, naming, keyTags...)
+		path := key
+		if parent != "" {
+			path = parent + "." + key
+		}
+
+		if visit(structField{StructField: field, Key: key, Path: path}) && isStruct {
+			walkStruct(field.Type, path, keyTags, naming, visit)
+		}
+	}
+}
+
+// Reports whether the fields of a struct type are nested under the key of a field of that type: structs
+// that are not scalars. All generators share this rule, so that they agree on the shape of a configuration.
+// Pointers to structs are not nested: a nil pointer usually leaves a feature disabled, so they are described
+// as a single optional value, e.g. rendered as null in templates, rather than field by field.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isTextScalar(t)
+}
+
+// Returns the struct type whose fields are merged into the parent of a field, if any: structs and pointers to
+// structs tagged with `yaml:",inline"`, and those embedded anonymously without a key name, e.g. an embedded
+// CommonOpts or *CommonOpts, whose fields are promoted like encoding/json does. Embedded structs with a key name,
+// e.g. `yaml:"common"`, are nested under it.
+func inlinedStruct(field reflect.StructField, keyTags []string) (reflect.Type, bool) {
+	t := field.Type
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isTextScalar(t) {
+		return nil, false
+	}
+	if hasTagOption(field, "yaml", "inline") {
+		return t, true
+	}
+	return t, field.Anonymous && !hasKeyName(field, keyTags)
+}
+
+// Reports whether one of the key tags of a field sets a key name, as opposed to only options.
+func hasKeyName(field reflect.StructField, keyTags []string) bool {
+	for _, tagName := range keyTags {
+		tagValue := field.Tag.Get(tagName)
+		if tagName == "kong" {
+			tagValue = kongName(field)
+		} else {
+			tagValue = strings.Split(tagValue, ",")[0]
+		}
+		if tagValue != "" && tagValue != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether a field is excluded with a "-" value in the kong tag or in the first of the key tags set
+// on the field, so that e.g. a `json:"-"` field hidden from a JSON API is kept when it has a yaml tag.
+func isIgnoredField(field reflect.StructField, tagNames []string) bool {
+	if field.Tag.Get("kong") == "-" {
+		return true
+	}
+	for _, tagName := range tagNames {
+		if value := field.Tag.Get(tagName); value != "" {
+			return value == "-"
+		}
+	}
+	return false
+}
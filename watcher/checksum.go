@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+)
+
+// ControlFileBytesChanges monitors changes to a file like ControlFileChanges, but passes the raw content of
+// the file to getCurrentConfigFnFromBytes instead of letting the callback read the file itself.
+//
+// Combined with WithCRC32Check, the file is read only once per event: the bytes that were checksummed are the
+// ones passed to the callback. With WithSudoRead the file is read with sudo instead of os.ReadFile.
+// A file that cannot be read is passed as nil bytes; read errors other than a missing file are reported to the
+// error handler.
+// See ControlFileChanges for the description of the remaining parameters and return values.
+func ControlFileBytesChanges[T any](ctx context.Context, pathToFile string, getCurrentConfigFnFromBytes func([]byte) T, opts ...Option) (<-chan ChangeEvent[T], error) {
+	// content holds the bytes already read by the checksum, until the callback consumes them.
+	// Both run in the emit pipeline under the emit lock, so no further locking is needed. The bytes are not passed
+	// with WithIdempotentRead, whose repeated reads are made before the lock is taken and read the file themselves.
+	var content []byte
+	// read and errorHandler are the ones of the watcher, as set by the options given by the caller
+	var read func(ctx context.Context, path string) ([]byte, error)
+	var errorHandler func(err error)
+	opts = append(opts[:len(opts):len(opts)], func(o *Options) {
+		o.onContent = func(data []byte) {
+			content = data
+		}
+		read, errorHandler = o.readFile, o.errorHandler
+	})
+
+	return ControlFileChanges(ctx, pathToFile, func() T {
+		data := content
+		content = nil
+		if data == nil {
+			var err error
+			if data, err = read(ctx, pathToFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errorHandler(fmt.Errorf("failed to read %s: %w", pathToFile, err))
+			}
+		}
+		return getCurrentConfigFnFromBytes(data)
+	}, opts...)
+}
+
+// Reads a file with os.ReadFile, the default read of the watcher.
+func readFile(_ context.Context, pathToFile string) ([]byte, error) {
+	return os.ReadFile(pathToFile)
+}
+
+// Reads a file with read and computes the CRC-32 (IEEE) checksum of its content.
+func readChecksum(ctx context.Context, read func(ctx context.Context, path string) ([]byte, error), pathToFile string) ([]byte, uint32, error) {
+	data, err := read(ctx, pathToFile)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, crc32.ChecksumIEEE(data), nil
+}
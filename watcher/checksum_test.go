@@ -0,0 +1,88 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestControlFileBytesChanges_CRC32Check
+// This test verifies that WithCRC32Check skips events for which the content of the file did not change,
+// and that ControlFileBytesChanges passes the content of the file to the callback.
+func TestControlFileBytesChanges_CRC32Check(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlFileBytesChanges(ctx, tempFile, func(data []byte) string {
+		return string(data)
+	}, WithDebounce(50*time.Millisecond), WithCRC32Check())
+	require.NoError(t, err, "Failed to start watcher")
+
+	// Rewriting the same content must not trigger an event
+	writeFile(t, tempFile, "initial")
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected event for unchanged content: %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	writeFile(t, tempFile, "updated")
+	select {
+	case event := <-updates:
+		assert.Equal(t, "initial", event.OldConfig, "Old config should be the initial content")
+		assert.Equal(t, "updated", event.NewConfig, "New config should be the updated content")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the change event")
+	}
+
+	writeFile(t, tempFile, "updated")
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected event for unchanged content: %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// Compares reading a file once for the checksum and the configuration, as ControlFileBytesChanges does,
+// with reading it a second time in getCurrentConfigFn, as ControlFileChanges does.
+func benchmarkCRC32Check(b *testing.B, singleRead bool) {
+	pathToFile := filepath.Join(b.TempDir(), "config.yaml")
+	content := strings.Repeat("key: value\n", 4096)
+	if err := os.WriteFile(pathToFile, []byte(content), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	parse := func(data []byte) int {
+		return len(data)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, _, err := readChecksum(context.Background(), readFile, pathToFile)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if !singleRead {
+			if data, err = os.ReadFile(pathToFile); err != nil {
+				b.Fatal(err)
+			}
+		}
+		parse(data)
+	}
+}
+
+func BenchmarkCRC32Check_SingleRead(b *testing.B) {
+	benchmarkCRC32Check(b, true)
+}
+
+func BenchmarkCRC32Check_DoubleRead(b *testing.B) {
+	benchmarkCRC32Check(b, false)
+}
@@ -0,0 +1,34 @@
+package watcher
+
+import "time"
+
+// Clock provides the time to the debounce of the watcher, so that tests can control it, see WithClock
+// and testutil.FakeClock. The default clock is the system clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once the duration has elapsed, unless the timer is stopped first.
+	AfterFunc(d time.Duration, f func()) TimerHandle
+}
+
+// TimerHandle stops a timer started by Clock.AfterFunc. Stop reports whether it stopped the timer before it fired.
+// It is an alias of an unnamed interface, so that clocks can implement Clock without importing this package.
+type TimerHandle = interface {
+	Stop() bool
+}
+
+// systemClock is the Clock of the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) TimerHandle {
+	return time.AfterFunc(d, f)
+}
+
+// clockSetter is implemented by the built-in debounce strategies, which use the clock of the watcher.
+type clockSetter interface {
+	setClock(clock Clock)
+}
@@ -0,0 +1,289 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultEnvPollInterval is the interval at which an EnvSource checks the environment for changes.
+const defaultEnvPollInterval = time.Second
+
+// ConfigSource is a source of a partial configuration, combined with other sources by ControlCompositeChanges.
+// A source implementing fmt.Stringer is named by its String method in the change events.
+type ConfigSource[T any] interface {
+	// Watch starts watching the source and returns a channel receiving its new values until the context is done.
+	// It may be called again after the context of a previous call is done.
+	Watch(ctx context.Context) (<-chan T, error)
+	// CurrentValue returns the last value of the source, including before Watch is called.
+	CurrentValue() T
+}
+
+// ControlCompositeChanges combines a configuration from several sources, e.g. a YAML base file, a JSON overlay
+// and environment variable overrides, and sends an update whenever any of the sources changes.
+// The configuration is built by calling mergeFn with the current values of all sources, in the order of sources.
+//
+// Every source is watched independently and its changes go through the pipeline of the file watcher, so the
+// watcher options (debounce, rate limiting, change filters...) apply to them; each source is debounced separately
+// unless WithBatchAcrossFiles is used. Change events have the name of the changed source as source:
+// its String method if it implements fmt.Stringer, or "source[i]" otherwise.
+// An error is returned if any source fails to start watching.
+func ControlCompositeChanges[T any](ctx context.Context, sources []ConfigSource[T], mergeFn func([]T) T, opts ...Option) (*WatchHandle[T], error) {
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = fmt.Sprintf("source[%d]", i)
+		if stringer, ok := source.(fmt.Stringer); ok {
+			names[i] = stringer.String()
+		}
+	}
+
+	getCurrentConfigFn := func() T {
+		values := make([]T, len(sources))
+		for i, source := range sources {
+			values[i] = source.CurrentValue()
+		}
+		return mergeFn(values)
+	}
+	factory := func() (FileWatcher, error) {
+		return newSourceWatcher(ctx, sources, names)
+	}
+	return WatchFiles(ctx, names, getCurrentConfigFn, append(opts[:len(opts):len(opts)], WithWatcherFactory(factory))...)
+}
+
+// sourceWatcher is a FileWatcher that turns the updates of configuration sources into write events
+// named after the sources.
+type sourceWatcher struct {
+	events chan fsnotify.Event
+	errors chan error
+	cancel context.CancelFunc
+}
+
+// Starts watching all sources, stopping the sources already started if one of them fails.
+func newSourceWatcher[T any](ctx context.Context, sources []ConfigSource[T], names []string) (FileWatcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &sourceWatcher{
+		events: make(chan fsnotify.Event),
+		errors: make(chan error),
+		cancel: cancel,
+	}
+
+	for i, source := range sources {
+		updates, err := source.Watch(ctx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to watch %s: %w", names[i], err)
+		}
+		go func(name string) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-updates:
+					if !ok {
+						return
+					}
+					select {
+					case w.events <- fsnotify.Event{Name: name, Op: fsnotify.Write}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(names[i])
+	}
+	return w, nil
+}
+
+// Sources are watched as a whole, so adding or removing them is a no-op.
+func (w *sourceWatcher) Add(name string) error {
+	return nil
+}
+
+func (w *sourceWatcher) Remove(name string) error {
+	return nil
+}
+
+func (w *sourceWatcher) Close() error {
+	w.cancel()
+	return nil
+}
+
+func (w *sourceWatcher) Events() <-chan fsnotify.Event {
+	return w.events
+}
+
+func (w *sourceWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// FileSource is a ConfigSource reading a configuration file, watched with ControlFileChanges.
+type FileSource[T any] struct {
+	path               string
+	getCurrentConfigFn func() T
+	opts               []Option
+
+	mutex sync.Mutex
+	value T
+}
+
+// NewFileSource creates a source reading the file at path with getCurrentConfigFn, which is called once immediately.
+// The options configure the file watcher of the source.
+func NewFileSource[T any](path string, getCurrentConfigFn func() T, opts ...Option) *FileSource[T] {
+	return &FileSource[T]{
+		path:               path,
+		getCurrentConfigFn: getCurrentConfigFn,
+		opts:               opts,
+		value:              getCurrentConfigFn(),
+	}
+}
+
+func (s *FileSource[T]) Watch(ctx context.Context) (<-chan T, error) {
+	updates, err := ControlFileChanges(ctx, s.path, s.getCurrentConfigFn, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(chan T)
+	go func() {
+		defer close(values)
+		for event := range updates {
+			s.mutex.Lock()
+			s.value = event.NewConfig
+			s.mutex.Unlock()
+
+			select {
+			case values <- event.NewConfig:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return values, nil
+}
+
+func (s *FileSource[T]) CurrentValue() T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.value
+}
+
+// String returns the path of the file.
+func (s *FileSource[T]) String() string {
+	return s.path
+}
+
+// EnvSource is a ConfigSource reading a configuration from the environment variables with a given prefix.
+// Since the environment of a process has no change notifications, it is polled for changes.
+type EnvSource[T any] struct {
+	prefix       string
+	parseFn      func(env map[string]string) T
+	pollInterval time.Duration
+
+	mutex sync.Mutex
+	env   map[string]string
+	value T
+}
+
+// NewEnvSource creates a source parsing the environment variables whose names start with prefix with parseFn,
+// which receives them by name and is called once immediately. The environment is polled at pollInterval,
+// or every second if pollInterval is not positive, and parseFn is called again whenever the variables change.
+func NewEnvSource[T any](prefix string, parseFn func(env map[string]string) T, pollInterval time.Duration) *EnvSource[T] {
+	if pollInterval <= 0 {
+		pollInterval = defaultEnvPollInterval
+	}
+	env := environWithPrefix(prefix)
+	return &EnvSource[T]{
+		prefix:       prefix,
+		parseFn:      parseFn,
+		pollInterval: pollInterval,
+		env:          env,
+		value:        parseFn(env),
+	}
+}
+
+func (s *EnvSource[T]) Watch(ctx context.Context) (<-chan T, error) {
+	values := make(chan T)
+	go func() {
+		defer close(values)
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			env := environWithPrefix(s.prefix)
+			s.mutex.Lock()
+			if maps.Equal(env, s.env) {
+				s.mutex.Unlock()
+				continue
+			}
+			value := s.parseFn(env)
+			s.env, s.value = env, value
+			s.mutex.Unlock()
+
+			select {
+			case values <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return values, nil
+}
+
+func (s *EnvSource[T]) CurrentValue() T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.value
+}
+
+// String returns the prefix of the environment variables, e.g. "env:MYAPP_".
+func (s *EnvSource[T]) String() string {
+	return "env:" + s.prefix
+}
+
+// Returns the environment variables whose names start with prefix, by name.
+func environWithPrefix(prefix string) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, prefix) {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// StaticSource is a ConfigSource with an immutable value, e.g. built-in defaults.
+type StaticSource[T any] struct {
+	value T
+}
+
+// NewStaticSource creates a source that always has the given value.
+func NewStaticSource[T any](value T) *StaticSource[T] {
+	return &StaticSource[T]{value: value}
+}
+
+// Watch returns a channel that never receives a value and is closed when the context is done.
+func (s *StaticSource[T]) Watch(ctx context.Context) (<-chan T, error) {
+	values := make(chan T)
+	go func() {
+		<-ctx.Done()
+		close(values)
+	}()
+	return values, nil
+}
+
+func (s *StaticSource[T]) CurrentValue() T {
+	return s.value
+}
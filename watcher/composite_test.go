@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestControlCompositeChanges
+// This test combines a static base, a file and environment variable overrides into a single configuration.
+// A change of the file and a change of the environment must each trigger an event with the merged configuration.
+func TestControlCompositeChanges(t *testing.T) {
+	tempFile := createTempFile(t, "file=1")
+	defer os.Remove(tempFile)
+	t.Setenv("COMPOSITE_TEST_LEVEL", "info")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	fileSource := NewFileSource(tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	})
+	envSource := NewEnvSource("COMPOSITE_TEST_", func(env map[string]string) string {
+		return "level=" + env["COMPOSITE_TEST_LEVEL"]
+	}, 50*time.Millisecond)
+	sources := []ConfigSource[string]{NewStaticSource("base"), fileSource, envSource}
+
+	handle, err := ControlCompositeChanges(ctx, sources, func(values []string) string {
+		return strings.Join(values, ";")
+	})
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, tempFile, "file=2")
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, "base;file=1;level=info", event.OldConfig, "Old config should merge the initial values")
+		assert.Equal(t, "base;file=2;level=info", event.NewConfig, "New config should include the file change")
+		assert.Equal(t, tempFile, event.Source, "Source should be the path of the file")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the file change")
+	}
+
+	require.NoError(t, os.Setenv("COMPOSITE_TEST_LEVEL", "debug"))
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, "base;file=2;level=debug", event.NewConfig, "New config should include the environment change")
+		assert.Equal(t, "env:COMPOSITE_TEST_", event.Source, "Source should name the environment source")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the environment change")
+	}
+}
+
+// failingSource is a ConfigSource that cannot be watched.
+type failingSource struct{}
+
+func (failingSource) Watch(ctx context.Context) (<-chan string, error) {
+	return nil, errors.New("source unavailable")
+}
+
+func (failingSource) CurrentValue() string {
+	return ""
+}
+
+// TestControlCompositeChanges_SourceError
+// This test verifies that a source failing to start watching is reported with its name.
+func TestControlCompositeChanges_SourceError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := ControlCompositeChanges(ctx, []ConfigSource[string]{NewStaticSource("base"), failingSource{}}, func(values []string) string {
+		return strings.Join(values, ";")
+	})
+	assert.ErrorIs(t, err, ErrWatcherInit, "Source failure should be reported as a watcher initialization failure")
+	assert.ErrorContains(t, err, "failed to watch source[1]: source unavailable")
+}
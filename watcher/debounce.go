@@ -0,0 +1,298 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+)
+
+// DebounceStrategy decides when a stream of file events results in a configuration reload.
+//
+// Event is called for every relevant file event. The key identifies the debounce stream the event
+// belongs to: the path of the changed file, or an empty key when events are batched across files.
+// The strategy calls fire, immediately or later from any goroutine, whenever a reload should happen.
+// Stop is called when the watcher shuts down and must cancel all pending fires. The watcher is only done once
+// the fires in progress have returned, and skips the fires that start after Stop.
+//
+// Implementations must be safe for concurrent use. A strategy instance keeps the state of a single
+// watcher, so it must not be shared between watchers.
+type DebounceStrategy interface {
+	Event(key string, fire func())
+	Stop()
+}
+
+// TrailingDebounce returns a strategy that fires once the events of a stream have been quiet for the given duration.
+// This is the default strategy used by the watcher, with the duration set by WithDebounce.
+// Every stream is debounced by its own Debouncer, and the fires of a stream run one at a time.
+// Stop waits for the fires in progress to return.
+func TrailingDebounce(duration time.Duration) DebounceStrategy {
+	return &trailingDebounce{duration: duration, clock: systemClock{}, debouncers: make(map[string]*Debouncer[func()])}
+}
+
+type trailingDebounce struct {
+	duration   time.Duration
+	clock      Clock
+	mutex      sync.Mutex
+	debouncers map[string]*Debouncer[func()]
+	stopped    bool
+	// goroutines tracks the goroutines running the fires of the debouncers, which Stop waits for.
+	goroutines sync.WaitGroup
+}
+
+func (d *trailingDebounce) Event(key string, fire func()) {
+	d.mutex.Lock()
+	if d.stopped {
+		d.mutex.Unlock()
+		return
+	}
+	debouncer := d.debouncers[key]
+	if debouncer == nil {
+		debouncer = NewDebouncerWithClock[func()](d.duration, d.clock)
+		d.debouncers[key] = debouncer
+		d.goroutines.Add(1)
+		go func() {
+			defer d.goroutines.Done()
+			for fire := range debouncer.Output() {
+				fire()
+			}
+		}()
+	}
+	d.mutex.Unlock()
+
+	debouncer.Submit(fire)
+}
+
+func (d *trailingDebounce) setClock(clock Clock) {
+	d.clock = clock
+}
+
+func (d *trailingDebounce) Stop() {
+	d.mutex.Lock()
+	d.stopped = true
+	for _, debouncer := range d.debouncers {
+		debouncer.Stop()
+	}
+	d.mutex.Unlock()
+
+	// Stopping the debouncers closes their outputs, which ends the goroutines once their fire returns
+	d.goroutines.Wait()
+}
+
+// LeadingDebounce returns a strategy that fires immediately on the first event of a stream
+// and ignores further events until the stream has been quiet for the given duration.
+func LeadingDebounce(duration time.Duration) DebounceStrategy {
+	return &leadingDebounce{duration: duration, clock: systemClock{}, timers: make(map[string]TimerHandle)}
+}
+
+type leadingDebounce struct {
+	duration time.Duration
+	clock    Clock
+	mutex    sync.Mutex
+	timers   map[string]TimerHandle
+}
+
+func (d *leadingDebounce) Event(key string, fire func()) {
+	d.mutex.Lock()
+	if timer, cooling := d.timers[key]; cooling {
+		// Extend the quiet period; the new timer replaces the one of the previous event
+		timer.Stop()
+		d.timers[key] = d.cooldown(key)
+		d.mutex.Unlock()
+		return
+	}
+	d.timers[key] = d.cooldown(key)
+	d.mutex.Unlock()
+
+	fire()
+}
+
+// cooldown starts a timer that ends the quiet period of a stream. Must be called with the mutex held.
+func (d *leadingDebounce) cooldown(key string) TimerHandle {
+	var timer TimerHandle
+	timer = d.clock.AfterFunc(d.duration, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		if d.timers[key] == timer {
+			delete(d.timers, key)
+		}
+	})
+	return timer
+}
+
+func (d *leadingDebounce) setClock(clock Clock) {
+	d.clock = clock
+}
+
+func (d *leadingDebounce) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, timer := range d.timers {
+		timer.Stop()
+	}
+}
+
+// ImmediateFirstDebounce returns a strategy that fires immediately on the first event of a stream after it has been
+// quiet for the given duration, and debounces the further events of a burst like TrailingDebounce: they result in
+// a single fire, with the last fire function, once the stream has been quiet for the duration again.
+func ImmediateFirstDebounce(duration time.Duration) DebounceStrategy {
+	return &immediateFirstDebounce{duration: duration, clock: systemClock{}, pending: make(map[string]*immediateFirstState)}
+}
+
+type immediateFirstDebounce struct {
+	duration time.Duration
+	clock    Clock
+	mutex    sync.Mutex
+	pending  map[string]*immediateFirstState
+}
+
+type immediateFirstState struct {
+	timer TimerHandle
+	// fire is the fire function of the last event since the first one of the burst, nil if there was none.
+	fire func()
+}
+
+func (d *immediateFirstDebounce) Event(key string, fire func()) {
+	d.mutex.Lock()
+	if state, busy := d.pending[key]; busy {
+		state.timer.Stop()
+		state.fire = fire
+		state.timer = d.quiet(key, state)
+		d.mutex.Unlock()
+		return
+	}
+	state := &immediateFirstState{}
+	state.timer = d.quiet(key, state)
+	d.pending[key] = state
+	d.mutex.Unlock()
+
+	fire()
+}
+
+// quiet starts a timer that ends the burst of a stream and fires its last event, if any.
+// Must be called with the mutex held.
+func (d *immediateFirstDebounce) quiet(key string, state *immediateFirstState) TimerHandle {
+	var timer TimerHandle
+	timer = d.clock.AfterFunc(d.duration, func() {
+		d.mutex.Lock()
+		if d.pending[key] != state || state.timer != timer {
+			d.mutex.Unlock()
+			return
+		}
+		delete(d.pending, key)
+		fire := state.fire
+		d.mutex.Unlock()
+
+		if fire != nil {
+			fire()
+		}
+	})
+	return timer
+}
+
+func (d *immediateFirstDebounce) setClock(clock Clock) {
+	d.clock = clock
+}
+
+func (d *immediateFirstDebounce) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for key, state := range d.pending {
+		state.timer.Stop()
+		delete(d.pending, key)
+	}
+}
+
+// MaxWaitDebounce returns a trailing strategy that additionally guarantees a reload at most maxWait
+// after the first pending event, so that a stream of continuous events cannot postpone the reload indefinitely.
+func MaxWaitDebounce(duration, maxWait time.Duration) DebounceStrategy {
+	return &maxWaitDebounce{duration: duration, maxWait: maxWait, clock: systemClock{}, pending: make(map[string]*maxWaitState)}
+}
+
+type maxWaitDebounce struct {
+	duration time.Duration
+	maxWait  time.Duration
+	clock    Clock
+	mutex    sync.Mutex
+	pending  map[string]*maxWaitState
+}
+
+type maxWaitState struct {
+	timer    TimerHandle
+	deadline time.Time
+	fire     func()
+}
+
+func (d *maxWaitDebounce) Event(key string, fire func()) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	state := d.pending[key]
+	if state == nil {
+		state = &maxWaitState{deadline: d.clock.Now().Add(d.maxWait)}
+		d.pending[key] = state
+	} else {
+		state.timer.Stop()
+	}
+	state.fire = fire
+
+	wait := d.duration
+	if remaining := state.deadline.Sub(d.clock.Now()); remaining < wait {
+		wait = remaining
+	}
+	state.timer = d.clock.AfterFunc(wait, func() {
+		d.mutex.Lock()
+		if d.pending[key] != state {
+			d.mutex.Unlock()
+			return
+		}
+		delete(d.pending, key)
+		fire := state.fire
+		d.mutex.Unlock()
+
+		fire()
+	})
+}
+
+func (d *maxWaitDebounce) setClock(clock Clock) {
+	d.clock = clock
+}
+
+func (d *maxWaitDebounce) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for key, state := range d.pending {
+		state.timer.Stop()
+		delete(d.pending, key)
+	}
+}
+
+// fireTracker tracks the fires of a debounce strategy in progress, so that the watcher can wait for them when it
+// stops, whichever goroutine the strategy fires from. Fires starting after stop are skipped.
+type fireTracker struct {
+	mutex   sync.Mutex
+	stopped bool
+	fires   sync.WaitGroup
+}
+
+// Returns fire, tracked until it returns.
+func (t *fireTracker) track(fire func()) func() {
+	return func() {
+		t.mutex.Lock()
+		if t.stopped {
+			t.mutex.Unlock()
+			return
+		}
+		t.fires.Add(1)
+		t.mutex.Unlock()
+		defer t.fires.Done()
+
+		fire()
+	}
+}
+
+// Skips the later fires and waits for the fires in progress to return.
+func (t *fireTracker) stop() {
+	t.mutex.Lock()
+	t.stopped = true
+	t.mutex.Unlock()
+	t.fires.Wait()
+}
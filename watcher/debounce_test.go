@@ -0,0 +1,413 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/watcher/testutil"
+)
+
+// The fake clock of the tests implements Clock without importing this package.
+var _ Clock = (*testutil.FakeClock)(nil)
+
+// everyOtherDebounce is a custom strategy that fires on every other event.
+type everyOtherDebounce struct {
+	mutex  sync.Mutex
+	events int
+}
+
+func (d *everyOtherDebounce) Event(key string, fire func()) {
+	d.mutex.Lock()
+	d.events++
+	fireNow := d.events%2 == 0
+	d.mutex.Unlock()
+
+	if fireNow {
+		fire()
+	}
+}
+
+func (d *everyOtherDebounce) Stop() {}
+
+func (d *everyOtherDebounce) count() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.events
+}
+
+// TestTrailingDebounce
+// This test verifies that the trailing strategy fires once after a burst of events, with the last fire function.
+func TestTrailingDebounce(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debounce := TrailingDebounce(50 * time.Millisecond)
+	debounce.(clockSetter).setClock(clock)
+	defer debounce.Stop()
+
+	fired := make(chan int, 10)
+	for i := 1; i <= 5; i++ {
+		debounce.Event("config.yaml", func() { fired <- i })
+	}
+
+	clock.Advance(49 * time.Millisecond)
+	assert.Empty(t, fired, "Trailing debounce should not fire before the quiet period")
+	clock.Advance(time.Millisecond)
+	select {
+	case last := <-fired:
+		assert.Equal(t, 5, last, "Trailing debounce should fire with the last event")
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the trailing fire")
+	}
+
+	clock.Advance(time.Hour)
+	assert.Zero(t, clock.PendingTimers(), "Trailing debounce should fire once per burst")
+	assert.Empty(t, fired, "Trailing debounce should fire once per burst")
+}
+
+// TestTrailingDebounce_StopWaitsForFire
+// This test verifies that stopping the trailing strategy waits for a fire in progress to return.
+func TestTrailingDebounce_StopWaitsForFire(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debounce := TrailingDebounce(50 * time.Millisecond)
+	debounce.(clockSetter).setClock(clock)
+
+	firing := make(chan struct{})
+	release := make(chan struct{})
+	debounce.Event("config.yaml", func() {
+		close(firing)
+		<-release
+	})
+	clock.Advance(50 * time.Millisecond)
+	<-firing
+
+	stopped := make(chan struct{})
+	go func() {
+		debounce.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop should wait for the fire in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop should return once the fire returned")
+	}
+}
+
+// TestControlFileChanges_DoneWaitsForFire
+// This test verifies that the watcher is only done once the fire of its debounce strategy in progress returned,
+// whichever goroutine the strategy fires from.
+func TestControlFileChanges_DoneWaitsForFire(t *testing.T) {
+	for name, strategy := range map[string]func() DebounceStrategy{
+		"trailing":  func() DebounceStrategy { return TrailingDebounce(time.Second) },
+		"max wait":  func() DebounceStrategy { return MaxWaitDebounce(time.Second, time.Minute) },
+		"immediate": func() DebounceStrategy { return ImmediateFirstDebounce(time.Second) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			clock := testutil.NewFakeClock(time.Now())
+			factory := newFakeWatcherFactory()
+			var reads atomic.Int32
+			reading := make(chan struct{})
+			release := make(chan struct{})
+			handle, err := Watch(ctx, "config.yaml", func() int {
+				if reads.Add(1) == 2 {
+					close(reading)
+					<-release
+				}
+				return 0
+			}, WithDebounceStrategy(strategy()), WithClock(clock), WithWatcherFactory(factory.create))
+			require.NoError(t, err, "Failed to start watcher")
+
+			factory.next(t).events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+			go func() {
+				// The immediate strategy fires without the clock
+				for {
+					select {
+					case <-reading:
+						return
+					case <-time.After(time.Millisecond):
+						clock.Advance(time.Second)
+					}
+				}
+			}()
+			<-reading
+
+			cancel()
+			select {
+			case <-handle.Done():
+				t.Fatal("The watcher should not be done while a fire is in progress")
+			case <-time.After(50 * time.Millisecond):
+			}
+			close(release)
+			select {
+			case <-handle.Done():
+			case <-time.After(time.Second):
+				t.Fatal("The watcher should be done once the fire returned")
+			}
+		})
+	}
+}
+
+// TestLeadingDebounce
+// This test verifies that the leading strategy fires on the first event of a burst and ignores the rest,
+// then fires again for an event after the quiet period.
+func TestLeadingDebounce(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debounce := LeadingDebounce(100 * time.Millisecond)
+	debounce.(clockSetter).setClock(clock)
+	defer debounce.Stop()
+
+	var fired atomic.Int32
+	for i := 0; i < 5; i++ {
+		debounce.Event("config.yaml", func() { fired.Add(1) })
+		clock.Advance(50 * time.Millisecond)
+	}
+	assert.Equal(t, int32(1), fired.Load(), "Leading debounce should fire immediately on the first event only")
+
+	// The quiet period of the last event ends 100ms after it, i.e. in 50ms
+	clock.Advance(49 * time.Millisecond)
+	debounce.Event("config.yaml", func() { fired.Add(1) })
+	assert.Equal(t, int32(1), fired.Load(), "Leading debounce should extend the quiet period with every event")
+
+	clock.Advance(100 * time.Millisecond)
+	debounce.Event("config.yaml", func() { fired.Add(1) })
+	assert.Equal(t, int32(2), fired.Load(), "Leading debounce should fire again after the quiet period")
+}
+
+// TestImmediateFirstDebounce
+// This test verifies that the immediate-first strategy fires on the first event of a burst without waiting,
+// fires once more with the last event of the burst after the quiet period, and fires immediately again afterwards.
+func TestImmediateFirstDebounce(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debounce := ImmediateFirstDebounce(100 * time.Millisecond)
+	debounce.(clockSetter).setClock(clock)
+	defer debounce.Stop()
+
+	var fired atomic.Int32
+	var last atomic.Int32
+	for i := 1; i <= 5; i++ {
+		debounce.Event("config.yaml", func() {
+			fired.Add(1)
+			last.Store(int32(i))
+		})
+		if i == 1 {
+			assert.Equal(t, int32(1), fired.Load(), "The first event should fire immediately")
+		}
+		clock.Advance(50 * time.Millisecond)
+	}
+	assert.Equal(t, int32(1), fired.Load(), "The rest of the burst should be debounced")
+
+	clock.Advance(50 * time.Millisecond)
+	assert.Equal(t, int32(2), fired.Load(), "The burst should fire once more after the quiet period")
+	assert.Equal(t, int32(5), last.Load(), "The burst should fire with its last event")
+
+	debounce.Event("config.yaml", func() { fired.Add(1) })
+	assert.Equal(t, int32(3), fired.Load(), "An event after the quiet period should fire immediately")
+	clock.Advance(time.Hour)
+	assert.Equal(t, int32(3), fired.Load(), "A single event should fire only once")
+}
+
+// TestMaxWaitDebounce
+// This test verifies that the max-wait strategy fires during a continuous stream of events,
+// which would postpone a plain trailing debounce indefinitely.
+func TestMaxWaitDebounce(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debounce := MaxWaitDebounce(100*time.Millisecond, 200*time.Millisecond)
+	debounce.(clockSetter).setClock(clock)
+	defer debounce.Stop()
+
+	var fired atomic.Int32
+	for i := 0; i < 20; i++ {
+		debounce.Event("config.yaml", func() { fired.Add(1) })
+		clock.Advance(25 * time.Millisecond)
+		if i == 6 {
+			assert.Equal(t, int32(0), fired.Load(), "Max-wait debounce should not fire before the max wait")
+		}
+	}
+
+	// The stream of 500ms is cut every 200ms, and its end fires after the quiet period
+	assert.Equal(t, int32(2), fired.Load(), "Max-wait debounce should fire while events keep arriving")
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, int32(3), fired.Load(), "Max-wait debounce should fire at the end of the stream")
+}
+
+// TestControlFileChanges_WithDebounceStrategy
+// This test verifies that a custom debounce strategy fully controls when events are emitted.
+// The custom strategy fires on every other file event, so the number of emitted events must be half the number of file events.
+func TestControlFileChanges_WithDebounceStrategy(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	debounce := &everyOtherDebounce{}
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithDebounceStrategy(debounce))
+	require.NoError(t, err, "Failed to start watcher")
+
+	emitted := 0
+	for i := 0; i < 4; i++ {
+		writeFile(t, tempFile, "update")
+		// Drain the events fired so far, so that the watcher is never blocked on the channel
+	drain:
+		for {
+			select {
+			case <-updates:
+				emitted++
+			case <-time.After(100 * time.Millisecond):
+				break drain
+			}
+		}
+	}
+
+	require.Greater(t, debounce.count(), 1, "Strategy should receive the file events")
+	assert.Equal(t, debounce.count()/2, emitted, "An event should be emitted for every other file event")
+}
+
+// TestControlFileChanges_WithImmediateFirstChange
+// This test verifies that with WithImmediateFirstChange a single edit after a quiet period is emitted
+// without waiting for a long debounce.
+func TestControlFileChanges_WithImmediateFirstChange(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithDebounce(5*time.Second), WithImmediateFirstChange())
+	require.NoError(t, err, "Failed to start watcher")
+
+	start := time.Now()
+	writeFile(t, tempFile, "update")
+	select {
+	case event := <-updates:
+		assert.Equal(t, tempFile, event.Source, "Event should be triggered by the file")
+		assert.Less(t, time.Since(start), time.Second, "The first change should not wait for the debounce")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the first change")
+	}
+}
+
+// countingClock is a testutil.FakeClock counting the timers started, e.g. to wait until every event of a burst
+// has restarted the debounce before advancing the clock.
+type countingClock struct {
+	*testutil.FakeClock
+	timers atomic.Int32
+}
+
+func (c *countingClock) AfterFunc(d time.Duration, f func()) TimerHandle {
+	c.timers.Add(1)
+	return c.FakeClock.AfterFunc(d, f)
+}
+
+// Returns the number of timers started.
+func (c *countingClock) started() int {
+	return int(c.timers.Load())
+}
+
+// TestControlFileChanges_WithClock
+// This test verifies that the debounce of the watcher is measured with the clock of WithClock:
+// the event is only emitted once the fake clock has been advanced by the debounce duration, and is timestamped by it.
+func TestControlFileChanges_WithClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	clock := testutil.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(time.Hour), WithClock(clock), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	factory.next(t).events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+	require.Eventually(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, time.Millisecond,
+		"The event should start the debounce")
+
+	clock.Advance(time.Hour - time.Nanosecond)
+	assert.Equal(t, 1, clock.PendingTimers(), "The debounce should not fire before its duration")
+	clock.Advance(time.Nanosecond)
+	select {
+	case event := <-updates:
+		assert.Equal(t, 2, event.NewConfig, "New config should be read once the debounce fired")
+		assert.Equal(t, clock.Now(), event.Timestamp, "Event should be timestamped by the clock")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the debounced event")
+	}
+}
+
+// TestControlFileChanges_WithDedupWindow
+// This test verifies that WithDedupWindow suppresses a change repeating the last sent configuration within
+// the window, and sends it again once the window is over.
+func TestControlFileChanges_WithDedupWindow(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	clock := testutil.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	factory := newFakeWatcherFactory()
+	var reads atomic.Int32
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() string {
+		if reads.Add(1) == 1 {
+			return "initial"
+		}
+		return "A"
+	}, WithDebounce(time.Second), WithDedupWindow(time.Minute), WithClock(clock), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	events := factory.next(t).events
+
+	// Sends a file event and fires its debounce
+	change := func() {
+		events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+		require.Eventually(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, time.Millisecond,
+			"The event should start the debounce")
+		clock.Advance(time.Second)
+	}
+
+	change()
+	select {
+	case event := <-updates:
+		assert.Equal(t, "A", event.NewConfig, "The first change should be sent")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the first event")
+	}
+
+	change()
+	require.Eventually(t, func() bool { return reads.Load() == 3 }, time.Second, time.Millisecond,
+		"The repeated change should be read")
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected event within the dedup window: %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(time.Minute)
+	change()
+	select {
+	case event := <-updates:
+		assert.Equal(t, "A", event.OldConfig)
+		assert.Equal(t, "A", event.NewConfig, "The repeated change should be sent after the window")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the event after the window")
+	}
+}
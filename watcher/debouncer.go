@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer coalesces a stream of values into the last value submitted before a quiet period.
+// It is independent of file watching and can debounce any stream, e.g. user input or API calls.
+// The file watcher uses it for the default trailing debounce set by WithDebounce.
+//
+// All methods are safe for concurrent use.
+type Debouncer[T any] struct {
+	duration time.Duration
+	clock    Clock
+	mutex    sync.Mutex
+	timer    TimerHandle
+	pending  T
+	hasValue bool
+	stopped  bool
+	output   chan T
+}
+
+// NewDebouncer creates a debouncer that emits the last submitted value once no value
+// has been submitted for the given duration.
+func NewDebouncer[T any](duration time.Duration) *Debouncer[T] {
+	return NewDebouncerWithClock[T](duration, systemClock{})
+}
+
+// NewDebouncerWithClock creates a debouncer like NewDebouncer, whose quiet periods are measured with the clock,
+// e.g. a testutil.FakeClock.
+func NewDebouncerWithClock[T any](duration time.Duration, clock Clock) *Debouncer[T] {
+	return &Debouncer[T]{duration: duration, clock: clock, output: make(chan T, 1)}
+}
+
+// Submit records a value and restarts the quiet period.
+// Values submitted after Stop are ignored.
+func (d *Debouncer[T]) Submit(value T) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.stopped {
+		return
+	}
+
+	d.pending, d.hasValue = value, true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	var timer TimerHandle
+	timer = d.clock.AfterFunc(d.duration, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		// A newer submission or a flush has replaced this timer
+		if d.timer != timer {
+			return
+		}
+		d.emit()
+	})
+	d.timer = timer
+}
+
+// Output returns the channel receiving the debounced values.
+// The channel buffers a single value: if it has not been received before the next emission, it is replaced
+// by the newer value. The channel is closed by Stop.
+func (d *Debouncer[T]) Output() <-chan T {
+	return d.output
+}
+
+// Flush emits the pending value immediately, without waiting for the quiet period.
+// It does nothing if no value is pending.
+func (d *Debouncer[T]) Flush() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.stopped {
+		return
+	}
+	d.emit()
+}
+
+// Stop discards the pending value, as well as an emitted value that has not been received yet,
+// and closes the Output channel.
+func (d *Debouncer[T]) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.stopped {
+		return
+	}
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	var zero T
+	d.pending, d.hasValue = zero, false
+	select {
+	case <-d.output:
+	default:
+	}
+	close(d.output)
+}
+
+// emit sends the pending value to the output channel without blocking. Must be called with the mutex held.
+func (d *Debouncer[T]) emit() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if !d.hasValue {
+		return
+	}
+	value := d.pending
+	var zero T
+	d.pending, d.hasValue = zero, false
+
+	select {
+	case d.output <- value:
+	default:
+		// The previous value has not been received yet; replace it
+		select {
+		case <-d.output:
+		default:
+		}
+		d.output <- value
+	}
+}
@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/watcher/testutil"
+)
+
+// TestDebouncer_Submit
+// This test verifies that a burst of submitted values results in a single emission of the last value.
+func TestDebouncer_Submit(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debouncer := NewDebouncerWithClock[int](50*time.Millisecond, clock)
+	defer debouncer.Stop()
+
+	for i := 1; i <= 5; i++ {
+		debouncer.Submit(i)
+		clock.Advance(10 * time.Millisecond)
+	}
+
+	clock.Advance(39 * time.Millisecond)
+	assert.Empty(t, debouncer.Output(), "Debouncer should not emit before the quiet period")
+	clock.Advance(time.Millisecond)
+	select {
+	case value := <-debouncer.Output():
+		assert.Equal(t, 5, value, "Debouncer should emit the last submitted value")
+	default:
+		t.Fatal("Debouncer should emit once the quiet period is over")
+	}
+
+	clock.Advance(time.Hour)
+	select {
+	case value := <-debouncer.Output():
+		t.Fatalf("Unexpected second emission: %d", value)
+	default:
+	}
+}
+
+// TestDebouncer_Flush
+// This test verifies that Flush emits the pending value without waiting for the quiet period,
+// and that the flushed value is not emitted again when the quiet period ends.
+func TestDebouncer_Flush(t *testing.T) {
+	debouncer := NewDebouncer[string](time.Hour)
+	defer debouncer.Stop()
+
+	debouncer.Flush()
+	debouncer.Submit("value")
+	debouncer.Flush()
+
+	select {
+	case value := <-debouncer.Output():
+		assert.Equal(t, "value", value, "Flush should emit the pending value")
+	default:
+		t.Fatal("Flush should emit the pending value immediately")
+	}
+
+	debouncer.Flush()
+	select {
+	case value := <-debouncer.Output():
+		t.Fatalf("Flush without a pending value should not emit, got %q", value)
+	default:
+	}
+}
+
+// TestDebouncer_Stop
+// This test verifies that Stop discards the pending value and closes the output channel.
+func TestDebouncer_Stop(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Now())
+	debouncer := NewDebouncerWithClock[int](50*time.Millisecond, clock)
+	debouncer.Submit(1)
+	debouncer.Stop()
+	debouncer.Submit(2)
+	clock.Advance(time.Hour)
+
+	select {
+	case value, ok := <-debouncer.Output():
+		assert.False(t, ok, "Output should be closed without emitting, got %d", value)
+	default:
+		t.Fatal("Output should be closed by Stop")
+	}
+	assert.Zero(t, clock.PendingTimers(), "Stop should stop the timer")
+}
+
+// TestDebouncer_Concurrent
+// This test submits values from several goroutines while flushing, to be run with the race detector.
+// The last value received must be one of the submitted values.
+func TestDebouncer_Concurrent(t *testing.T) {
+	debouncer := NewDebouncerWithClock[int](10*time.Millisecond, testutil.NewFakeClock(time.Now()))
+	defer debouncer.Stop()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				debouncer.Submit(g*100 + i + 1)
+				if i%10 == 0 {
+					debouncer.Flush()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	debouncer.Flush()
+
+	select {
+	case value := <-debouncer.Output():
+		require.Greater(t, value, 0, "Debouncer should emit a submitted value")
+		assert.LessOrEqual(t, value, 400, "Debouncer should emit a submitted value")
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the debounced value")
+	}
+}
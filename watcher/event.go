@@ -0,0 +1,75 @@
+package watcher
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChangeEvent represents the old and new configuration states.
+type ChangeEvent[T any] struct {
+	OldConfig T
+	NewConfig T
+
+	// Timestamp is the moment the new configuration was read.
+	Timestamp time.Time
+	// Source is the path of the file whose change triggered the event, empty for reloads.
+	Source string
+	// Operation is the file system operation that triggered the event, e.g. "WRITE" or "CREATE",
+	// or ReloadOperation for reloads triggered by WatchHandle.Reload.
+	Operation string
+	// IsShutdown marks the final event sent with WithShutdownEvent when the context is cancelled.
+	// Both configurations are then the last known configuration.
+	IsShutdown bool
+	// FileModTime is the modification time of the source file when the event was emitted, with WithFileStat.
+	// It is zero without the option, for reloads, and if the file could not be stat-ed.
+	FileModTime time.Time
+}
+
+// changeEventJSON is the JSON representation of a ChangeEvent.
+type changeEventJSON[T any] struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Source     string    `json:"source"`
+	Operation  string    `json:"operation"`
+	OldConfig  T         `json:"old_config"`
+	NewConfig  T         `json:"new_config"`
+	IsShutdown bool      `json:"is_shutdown,omitempty"`
+	// FileModTime is a pointer so that zero times are omitted.
+	FileModTime *time.Time `json:"file_mod_time,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, so that change events can be published to external audit buses.
+// The configurations are marshaled with the standard encoding/json package.
+func (e ChangeEvent[T]) MarshalJSON() ([]byte, error) {
+	encoded := changeEventJSON[T]{
+		Timestamp:  e.Timestamp,
+		Source:     e.Source,
+		Operation:  e.Operation,
+		OldConfig:  e.OldConfig,
+		NewConfig:  e.NewConfig,
+		IsShutdown: e.IsShutdown,
+	}
+	if !e.FileModTime.IsZero() {
+		encoded.FileModTime = &e.FileModTime
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements json.Unmarshaler for events produced by MarshalJSON.
+func (e *ChangeEvent[T]) UnmarshalJSON(data []byte) error {
+	var decoded changeEventJSON[T]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = ChangeEvent[T]{
+		OldConfig:  decoded.OldConfig,
+		NewConfig:  decoded.NewConfig,
+		Timestamp:  decoded.Timestamp,
+		Source:     decoded.Source,
+		Operation:  decoded.Operation,
+		IsShutdown: decoded.IsShutdown,
+	}
+	if decoded.FileModTime != nil {
+		e.FileModTime = *decoded.FileModTime
+	}
+	return nil
+}
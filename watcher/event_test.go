@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChangeEvent_JSONRoundTrip
+// This test verifies that a ChangeEvent survives a JSON round trip with all of its fields intact,
+// including structured configurations marshaled via encoding/json.
+func TestChangeEvent_JSONRoundTrip(t *testing.T) {
+	type config struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	event := ChangeEvent[config]{
+		OldConfig: config{Host: "localhost", Port: 8080},
+		NewConfig: config{Host: "example.com", Port: 9090},
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:    "/etc/app/config.yaml",
+		Operation: "WRITE",
+	}
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err, "Failed to marshal event")
+	assert.JSONEq(t, `{
+		"timestamp": "2024-01-02T03:04:05Z",
+		"source": "/etc/app/config.yaml",
+		"operation": "WRITE",
+		"old_config": {"host": "localhost", "port": 8080},
+		"new_config": {"host": "example.com", "port": 9090}
+	}`, string(data))
+
+	var decoded ChangeEvent[config]
+	require.NoError(t, json.Unmarshal(data, &decoded), "Failed to unmarshal event")
+	assert.Equal(t, event, decoded, "Event should survive a JSON round trip")
+}
+
+// TestChangeEvent_JSONRoundTripPrimitive
+// This test verifies the round trip for a primitive configuration type.
+func TestChangeEvent_JSONRoundTripPrimitive(t *testing.T) {
+	event := ChangeEvent[string]{OldConfig: "initial", NewConfig: "updated", Operation: "CREATE"}
+
+	data, err := json.Marshal(&event)
+	require.NoError(t, err, "Failed to marshal event")
+
+	var decoded ChangeEvent[string]
+	require.NoError(t, json.Unmarshal(data, &decoded), "Failed to unmarshal event")
+	assert.Equal(t, event, decoded, "Event should survive a JSON round trip")
+}
+
+// TestChangeEvent_JSONFileModTime
+// This test verifies that the modification time of WithFileStat survives a JSON round trip, and is omitted when zero.
+func TestChangeEvent_JSONFileModTime(t *testing.T) {
+	event := ChangeEvent[string]{NewConfig: "updated", FileModTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err, "Failed to marshal event")
+	assert.Contains(t, string(data), `"file_mod_time":"2024-01-02T03:04:05Z"`)
+
+	var decoded ChangeEvent[string]
+	require.NoError(t, json.Unmarshal(data, &decoded), "Failed to unmarshal event")
+	assert.Equal(t, event, decoded, "Event should survive a JSON round trip")
+
+	data, err = json.Marshal(ChangeEvent[string]{})
+	require.NoError(t, err, "Failed to marshal event")
+	assert.NotContains(t, string(data), "file_mod_time", "Zero modification time should be omitted")
+}
@@ -0,0 +1,78 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ErrWatcherInit is reported when the file watcher cannot be created, e.g. when the system limit
+// of watchers or open files is reached. The error of the watcher factory is wrapped with it.
+var ErrWatcherInit = errors.New("failed to create watcher")
+
+// ErrWatchAdd is reported when a file cannot be added to the file watcher.
+// The underlying error can be inspected with errors.Is, e.g. against fs.ErrNotExist for a file that does not exist yet.
+type ErrWatchAdd struct {
+	Path string
+	Err  error
+}
+
+func (e ErrWatchAdd) Error() string {
+	return fmt.Sprintf("failed to watch file %s: %v", e.Path, e.Err)
+}
+
+func (e ErrWatchAdd) Unwrap() error {
+	return e.Err
+}
+
+// FileWatcher is the source of file system events used by the watcher.
+// It is implemented on top of fsnotify by default; other implementations can be injected with
+// WithWatcherFactory, e.g. to drive the watcher with synthetic events in tests.
+// The watcher stops (or recovers, see WithAutoRecover) when the Events or Errors channel is closed.
+type FileWatcher interface {
+	Add(name string) error
+	Remove(name string) error
+	Close() error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+}
+
+// fsnotifyWatcher adapts *fsnotify.Watcher to the FileWatcher interface.
+type fsnotifyWatcher struct {
+	*fsnotify.Watcher
+}
+
+func newFSNotifyWatcher() (FileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return fsnotifyWatcher{Watcher: watcher}, nil
+}
+
+func (w fsnotifyWatcher) Events() <-chan fsnotify.Event {
+	return w.Watcher.Events
+}
+
+func (w fsnotifyWatcher) Errors() <-chan error {
+	return w.Watcher.Errors
+}
+
+// Creates a file watcher with the given factory and adds all paths to it.
+// The watcher is closed if any of the paths cannot be added.
+func openFileWatcher(factory func() (FileWatcher, error), paths []string) (FileWatcher, error) {
+	watcher, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWatcherInit, err)
+	}
+
+	for _, pathToFile := range paths {
+		err = watcher.Add(pathToFile)
+		if err != nil {
+			watcher.Close()
+			return nil, ErrWatchAdd{Path: pathToFile, Err: err}
+		}
+	}
+	return watcher, nil
+}
@@ -0,0 +1,503 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/watcher/testutil"
+)
+
+// fakeWatcher is a FileWatcher driven by the test instead of the file system.
+type fakeWatcher struct {
+	events chan fsnotify.Event
+	errors chan error
+
+	mutex  sync.Mutex
+	paths  []string
+	closed bool
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{
+		events: make(chan fsnotify.Event),
+		errors: make(chan error),
+	}
+}
+
+func (w *fakeWatcher) Add(name string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.paths = append(w.paths, name)
+	return nil
+}
+
+func (w *fakeWatcher) Remove(name string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for i, path := range w.paths {
+		if path == name {
+			w.paths = append(w.paths[:i], w.paths[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("path is not watched")
+}
+
+func (w *fakeWatcher) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *fakeWatcher) Events() <-chan fsnotify.Event {
+	return w.events
+}
+
+func (w *fakeWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *fakeWatcher) watchedPaths() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]string(nil), w.paths...)
+}
+
+func (w *fakeWatcher) isClosed() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.closed
+}
+
+// Simulates the death of the underlying watcher by closing its channels.
+func (w *fakeWatcher) die() {
+	close(w.events)
+	close(w.errors)
+}
+
+// fakeWatcherFactory creates fake watchers and records them for the test.
+type fakeWatcherFactory struct {
+	mutex    sync.Mutex
+	watchers []*fakeWatcher
+	created  chan *fakeWatcher
+	failures int
+}
+
+func newFakeWatcherFactory() *fakeWatcherFactory {
+	return &fakeWatcherFactory{created: make(chan *fakeWatcher, 10)}
+}
+
+func (f *fakeWatcherFactory) create() (FileWatcher, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("simulated watcher creation failure")
+	}
+	watcher := newFakeWatcher()
+	f.watchers = append(f.watchers, watcher)
+	f.created <- watcher
+	return watcher, nil
+}
+
+// Waits for the factory to create the next watcher.
+func (f *fakeWatcherFactory) next(t *testing.T) *fakeWatcher {
+	t.Helper()
+	select {
+	case watcher := <-f.created:
+		return watcher
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for watcher creation")
+		return nil
+	}
+}
+
+// TestControlFileChanges_AutoRecover
+// This test verifies that WithAutoRecover recreates a dead file watcher and re-adds the watched path.
+// The injected watcher dies, the recreation fails once, and events of the recovered watcher must still be delivered.
+func TestControlFileChanges_AutoRecover(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	errs := make(chan error, 10)
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(0), WithAutoRecover(), WithErrorHandler(func(err error) {
+		errs <- err
+	}), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	first := factory.next(t)
+	factory.mutex.Lock()
+	factory.failures = 1
+	factory.mutex.Unlock()
+	first.die()
+
+	recovered := factory.next(t)
+	assert.Eventually(t, func() bool {
+		return len(recovered.watchedPaths()) == 1 && recovered.watchedPaths()[0] == "config.yaml"
+	}, time.Second, 10*time.Millisecond, "Recovered watcher should watch the same paths")
+	assert.True(t, first.isClosed(), "Dead watcher should be closed")
+	assert.ErrorContains(t, <-errs, "simulated watcher creation failure", "Failed recovery attempt should be reported")
+
+	recovered.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, 1, event.OldConfig, "Old config should be the initial read")
+		assert.Equal(t, 2, event.NewConfig, "New config should be read after recovery")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for event after recovery")
+	}
+}
+
+// TestControlFileChanges_StopsWhenWatcherDies
+// This test verifies that, without WithAutoRecover, the updates channel is closed when the file watcher dies.
+func TestControlFileChanges_StopsWhenWatcherDies(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() string {
+		return "config"
+	}, WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	factory.next(t).die()
+
+	select {
+	case _, ok := <-updates:
+		assert.False(t, ok, "Channel should be closed after the watcher died")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the updates channel to close")
+	}
+}
+
+// TestControlFileChanges_SyntheticEvents
+// This test drives the watcher with synthetic events through an injected fake watcher.
+// Irrelevant operations must be ignored, and a burst of writes must be coalesced into a single event.
+func TestControlFileChanges_SyntheticEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(100*time.Millisecond), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	watcher := factory.next(t)
+	watcher.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Chmod}
+	for i := 0; i < 5; i++ {
+		watcher.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+	}
+	watcher.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Create}
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, 1, event.OldConfig, "Old config should be the initial read")
+		assert.Equal(t, 2, event.NewConfig, "Config should be read once for the whole burst")
+		assert.Equal(t, "config.yaml", event.Source, "Source should match the synthetic event")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the coalesced event")
+	}
+
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected second event: %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// TestControlFileChanges_SetupErrors
+// This test verifies that setup failures are reported with typed errors that callers can inspect with errors.Is and errors.As.
+func TestControlFileChanges_SetupErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := ControlFileChanges(ctx, "/invalid/path", func() string {
+		return ""
+	})
+	var addErr ErrWatchAdd
+	require.ErrorAs(t, err, &addErr, "Missing file should be reported as ErrWatchAdd")
+	assert.Equal(t, "/invalid/path", addErr.Path, "Error should carry the path")
+	assert.ErrorIs(t, err, fs.ErrNotExist, "Missing file should match fs.ErrNotExist")
+
+	factory := newFakeWatcherFactory()
+	factory.failures = 1
+	_, err = ControlFileChanges(ctx, "config.yaml", func() string {
+		return ""
+	}, WithWatcherFactory(factory.create))
+	assert.ErrorIs(t, err, ErrWatcherInit, "Watcher creation failure should match ErrWatcherInit")
+	assert.ErrorContains(t, err, "simulated watcher creation failure", "Error should wrap the factory error")
+}
+
+// TestControlFileChanges_ShutdownEvent
+// This test verifies that WithShutdownEvent sends a final event with the last known configuration when the context
+// is cancelled, and that no such event is sent when the watcher stops because the file watcher died.
+func TestControlFileChanges_ShutdownEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(0), WithShutdownEvent(), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	factory.next(t).events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+	event := <-updates
+	assert.False(t, event.IsShutdown, "Change events should not be marked as shutdown")
+
+	cancel()
+	select {
+	case event = <-updates:
+		assert.True(t, event.IsShutdown, "Final event should be marked as shutdown")
+		assert.Equal(t, 2, event.OldConfig, "Shutdown event should carry the last known config")
+		assert.Equal(t, 2, event.NewConfig, "Shutdown event should carry the last known config")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the shutdown event")
+	}
+	_, ok := <-updates
+	assert.False(t, ok, "Channel should be closed after the shutdown event")
+
+	factory = newFakeWatcherFactory()
+	updates, err = ControlFileChanges(context.Background(), "config.yaml", func() int {
+		return 0
+	}, WithShutdownEvent(), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	factory.next(t).die()
+	select {
+	case event, ok := <-updates:
+		assert.False(t, ok, "Channel should be closed without a shutdown event, got %+v", event)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the updates channel to close")
+	}
+}
+
+// TestControlFileChanges_PanicErrorContext
+// This test verifies that the error reported for a panic in getCurrentConfigFn names the file whose change
+// triggered the read and includes the stack trace of the panic.
+func TestControlFileChanges_PanicErrorContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	errs := make(chan error, 1)
+	readCounter := 0
+	_, err := ControlFileChanges(ctx, "/etc/app/config.yaml", func() int {
+		readCounter++
+		if readCounter == 2 {
+			panic("simulated panic in getCurrentConfigFn")
+		}
+		return readCounter
+	}, WithDebounce(0), WithErrorHandler(func(err error) {
+		errs <- err
+	}), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	factory.next(t).events <- fsnotify.Event{Name: "/etc/app/config.yaml", Op: fsnotify.Write}
+
+	select {
+	case err := <-errs:
+		var panicErr PanicError
+		require.ErrorAs(t, err, &panicErr, "Panic should be reported as PanicError")
+		assert.Equal(t, "/etc/app/config.yaml", panicErr.Source, "Error should carry the triggering file")
+		assert.Contains(t, err.Error(), "source: /etc/app/config.yaml", "Message should include the triggering file")
+		assert.Contains(t, err.Error(), "simulated panic in getCurrentConfigFn", "Message should include the panic value")
+		assert.Contains(t, err.Error(), "TestControlFileChanges_PanicErrorContext", "Message should include the stack trace")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the panic error")
+	}
+}
+
+// blockingTracer holds the debounce logic on its first event until it is released.
+type blockingTracer struct {
+	NopDebounceTracer
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTracer) OnDebounceStart(path string, eventCount int) {
+	b.once.Do(func() {
+		close(b.started)
+		<-b.release
+	})
+}
+
+// TestControlGlobChanges_InternalBufferSize
+// This test verifies that WithInternalBufferSize lets a burst of events queue while the debounce logic is busy.
+// The changes of many files of a watched directory arrive at once, and every file must still trigger its reload.
+func TestControlGlobChanges_InternalBufferSize(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	const files = 20
+	factory := newFakeWatcherFactory()
+	tracer := &blockingTracer{started: make(chan struct{}), release: make(chan struct{})}
+	readCounter := 0
+	updates, err := ControlGlobChanges(ctx, "conf.d/*.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(20*time.Millisecond), WithInternalBufferSize(files), WithDebounceTracing(tracer),
+		WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	watcher := factory.next(t)
+	watcher.events <- fsnotify.Event{Name: "conf.d/00.yaml", Op: fsnotify.Write}
+	<-tracer.started
+	for i := 1; i <= files; i++ {
+		watcher.events <- fsnotify.Event{Name: fmt.Sprintf("conf.d/%02d.yaml", i), Op: fsnotify.Write}
+	}
+	close(tracer.release)
+
+	sources := make(map[string]bool)
+	for len(sources) < files+1 {
+		select {
+		case event := <-updates:
+			sources[event.Source] = true
+		case <-ctx.Done():
+			t.Fatalf("Timeout waiting for reloads, got %d of %d", len(sources), files+1)
+		}
+	}
+	assert.True(t, sources[fmt.Sprintf("conf.d/%02d.yaml", files)], "Last file of the burst should be reloaded")
+}
+
+// TestControlFileChanges_ShutdownEventNotReceived
+// This test verifies that the watcher finishes shutting down with WithShutdownEvent when the consumer no longer
+// receives once the context is cancelled, and that reloads then fail instead of blocking.
+func TestControlFileChanges_ShutdownEventNotReceived(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	factory := newFakeWatcherFactory()
+	handle, err := Watch(ctx, "config.yaml", func() int {
+		return 0
+	}, WithShutdownEvent(), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	factory.next(t)
+
+	cancel()
+	select {
+	case <-handle.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timeout waiting for the watcher to finish shutting down")
+	}
+	assert.ErrorIs(t, handle.Reload(), ErrWatcherStopped, "Reloads should fail once the watcher stopped")
+	_, ok := <-handle.Events()
+	assert.False(t, ok, "Channel should be closed")
+}
+
+// closeObserver is an Observer recording only whether it was closed.
+type closeObserver struct {
+	closed chan struct{}
+}
+
+func (o *closeObserver) OnEvent(string)                     {}
+func (o *closeObserver) OnDebounceFire(string)              {}
+func (o *closeObserver) OnConfigRead(string, time.Duration) {}
+func (o *closeObserver) OnEventSuppressed(string)           {}
+func (o *closeObserver) OnError(string, error)              {}
+func (o *closeObserver) Close()                             { close(o.closed) }
+
+// TestControlFileChanges_ObserverFactory
+// This test verifies that WithObserverFactory creates an observer for every started watcher, returns the error
+// of the factory, and closes the observer when the watcher fails to start.
+func TestControlFileChanges_ObserverFactory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var observers []*closeObserver
+	option := WithObserverFactory(func() (Observer, error) {
+		observer := &closeObserver{closed: make(chan struct{})}
+		observers = append(observers, observer)
+		return observer, nil
+	})
+
+	factory := newFakeWatcherFactory()
+	factory.failures = 1
+	_, err := ControlFileChanges(ctx, "config.yaml", func() string {
+		return ""
+	}, WithWatcherFactory(factory.create), option)
+	assert.ErrorIs(t, err, ErrWatcherInit, "Watcher creation failure should be returned")
+	require.Len(t, observers, 1, "The observer should be created when the watcher starts")
+	assert.True(t, isClosed(observers[0].closed), "The observer should be closed when the watcher fails to start")
+
+	handle, err := Watch(ctx, "config.yaml", func() string {
+		return ""
+	}, WithWatcherFactory(factory.create), option)
+	require.NoError(t, err, "Failed to start watcher")
+	require.Len(t, observers, 2, "Every watcher should have its own observer")
+	assert.False(t, isClosed(observers[1].closed), "The observer should not be closed while the watcher runs")
+	cancel()
+	<-handle.Done()
+	assert.True(t, isClosed(observers[1].closed), "The observer should be closed when the watcher stops")
+
+	_, err = ControlFileChanges(context.Background(), "config.yaml", func() string {
+		return ""
+	}, WithWatcherFactory(factory.create), WithObserverFactory(func() (Observer, error) {
+		return nil, errors.New("simulated observer failure")
+	}))
+	assert.ErrorContains(t, err, "simulated observer failure", "The error of the factory should be returned")
+}
+
+// Reports whether a channel is closed.
+func isClosed(channel chan struct{}) bool {
+	select {
+	case <-channel:
+		return true
+	default:
+		return false
+	}
+}
+
+// latencyObserver is an Observer recording the latencies of the configuration reads.
+type latencyObserver struct {
+	closeObserver
+	latencies chan time.Duration
+}
+
+func (o *latencyObserver) OnConfigRead(_ string, latency time.Duration) {
+	o.latencies <- latency
+}
+
+// TestControlFileChanges_ObserverReadLatency
+// This test verifies that the latency of the configuration reads reported to the observer is measured with the
+// clock of the watcher.
+func TestControlFileChanges_ObserverReadLatency(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	clock := testutil.NewFakeClock(time.Now())
+	observer := &latencyObserver{closeObserver: closeObserver{closed: make(chan struct{})}, latencies: make(chan time.Duration, 1)}
+	handle, err := Watch(ctx, "config.yaml", func() string {
+		clock.Advance(5 * time.Second)
+		return "config"
+	}, WithObserver(observer), WithClock(clock), WithWatcherFactory(newFakeWatcherFactory().create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	go func() { _ = handle.Reload() }()
+	select {
+	case latency := <-observer.latencies:
+		assert.Equal(t, 5*time.Second, latency, "The latency should be measured with the clock")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the read")
+	}
+}
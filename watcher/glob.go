@@ -0,0 +1,35 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ControlGlobChanges monitors changes to the files matching a glob pattern, e.g. /etc/myapp/conf.d/*.yaml,
+// that together make up a configuration, and sends detected updates through a channel.
+//
+// The directory of the pattern is watched instead of the individual files, so files created after the
+// watcher started are picked up automatically, and changes to files that do not match the pattern are ignored.
+// Creating, writing, removing or renaming a matching file triggers an event with the file as its source.
+// Only the last element of the pattern may contain wildcards, with the syntax of filepath.Match.
+// See ControlFileChanges for the description of the remaining parameters and return values.
+func ControlGlobChanges[T any](ctx context.Context, pattern string, getCurrentConfigFn func() T, opts ...Option) (<-chan ChangeEvent[T], error) {
+	pattern = filepath.Clean(pattern)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	dir := filepath.Dir(pattern)
+	if strings.ContainsAny(dir, "*?[") {
+		return nil, fmt.Errorf("invalid glob pattern %q: wildcards are only supported in the file name", pattern)
+	}
+
+	opts = append(opts[:len(opts):len(opts)], func(o *Options) {
+		o.pathFilter = func(name string) bool {
+			matched, _ := filepath.Match(pattern, filepath.Clean(name))
+			return matched
+		}
+	})
+	return ControlFileChanges(ctx, dir, getCurrentConfigFn, opts...)
+}
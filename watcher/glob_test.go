@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Reads the matching files of a conf.d-style directory, sorted by name, into a single configuration.
+func readConfD(t *testing.T, pattern string) string {
+	t.Helper()
+	matches, _ := filepath.Glob(pattern)
+	sort.Strings(matches)
+	var parts []string
+	for _, match := range matches {
+		data, _ := os.ReadFile(match)
+		parts = append(parts, string(data))
+	}
+	return strings.Join(parts, ",")
+}
+
+// TestControlGlobChanges
+// This test watches a conf.d-style directory. Creating a file that does not match the pattern must be ignored,
+// while creating a new matching file must trigger an event with the new file as its source.
+func TestControlGlobChanges(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "*.yaml")
+	writeFile(t, filepath.Join(dir, "00-base.yaml"), "base")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlGlobChanges(ctx, pattern, func() string {
+		return readConfD(t, pattern)
+	}, WithDebounce(100*time.Millisecond))
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected event for a file that does not match: %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	newFile := filepath.Join(dir, "10-extra.yaml")
+	writeFile(t, newFile, "extra")
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "base", event.OldConfig, "Old config should only include the initial file")
+		assert.Equal(t, "base,extra", event.NewConfig, "New config should include the new file")
+		assert.Equal(t, newFile, event.Source, "Source should be the new file")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the new matching file")
+	}
+
+	require.NoError(t, os.Remove(newFile), "Failed to remove file")
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "base", event.NewConfig, "New config should no longer include the removed file")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the removal of the matching file")
+	}
+}
+
+// TestControlGlobChanges_InvalidPattern
+// This test verifies that patterns with wildcards in the directory, or malformed patterns, are rejected.
+func TestControlGlobChanges_InvalidPattern(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := ControlGlobChanges(ctx, "/etc/*/conf.d/*.yaml", func() string { return "" })
+	assert.ErrorContains(t, err, "wildcards are only supported in the file name")
+
+	_, err = ControlGlobChanges(ctx, "/etc/conf.d/[.yaml", func() string { return "" })
+	assert.ErrorContains(t, err, "invalid glob pattern")
+}
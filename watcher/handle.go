@@ -0,0 +1,70 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrWatcherStopped is returned when a reload is requested from a watcher that has stopped.
+var ErrWatcherStopped = errors.New("watcher stopped")
+
+// ReloadOperation is the Operation of the change events triggered by WatchHandle.Reload.
+const ReloadOperation = "RELOAD"
+
+// WatchHandle gives access to a running watcher created by Watch or WatchFiles.
+type WatchHandle[T any] struct {
+	updates <-chan ChangeEvent[T]
+	fanOut  []chan ChangeEvent[T]
+	reload  func(ctx context.Context, source, operation string) (uint64, error)
+	ack     chan struct{}
+	done    chan struct{}
+}
+
+// Events returns the channel of change events, which is closed when the watcher stops.
+func (h *WatchHandle[T]) Events() <-chan ChangeEvent[T] {
+	return h.updates
+}
+
+// EventsFor returns the i-th channel of change events of WithFanOut. Channel 0 is the Events channel, and the other
+// channels receive a copy of every event sent on it. All channels are closed when the watcher stops.
+// It returns nil if i is not below the number of channels given to WithFanOut, which defaults to 1.
+func (h *WatchHandle[T]) EventsFor(i int) <-chan ChangeEvent[T] {
+	switch {
+	case i == 0:
+		return h.updates
+	case i > 0 && i <= len(h.fanOut):
+		return h.fanOut[i-1]
+	default:
+		return nil
+	}
+}
+
+// Reload immediately re-reads the configuration with getCurrentConfigFn and emits a change event
+// through the same pipeline as file changes, without waiting for a file event or the debounce.
+// The event has the ReloadOperation operation and an empty source.
+//
+// Reload blocks until the event has been received from the Events channel, so it must not be called
+// from the goroutine receiving the events. It returns ErrWatcherStopped if the watcher stops first,
+// or an error if getCurrentConfigFn panics.
+func (h *WatchHandle[T]) Reload() error {
+	_, err := h.reload(context.Background(), "", ReloadOperation)
+	return err
+}
+
+// Ack returns the channel on which the consumer acknowledges that it applied the last event, with WithAckChannel.
+// The watcher does not emit the next event until the last one is acknowledged, and Reload does not return before
+// its event is acknowledged. Without WithAckChannel it returns nil.
+func (h *WatchHandle[T]) Ack() chan<- struct{} {
+	if h.ack == nil {
+		return nil
+	}
+	return h.ack
+}
+
+// Done returns a channel that is closed when the watcher has fully shut down, after the context is cancelled
+// or the file watcher died: its goroutines have exited, the file watcher is closed and the Events channel is closed.
+// Unlike the closing of the Events channel, which only means that no more events are emitted, Done also waits
+// for the background work of the watcher, e.g. the wait for a rotated file, to finish.
+func (h *WatchHandle[T]) Done() <-chan struct{} {
+	return h.done
+}
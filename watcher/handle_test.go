@@ -0,0 +1,349 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchHandle_Reload
+// This test verifies that Reload emits an event with the current configuration without any file change.
+func TestWatchHandle_Reload(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	readCounter := 0
+	handle, err := Watch(ctx, tempFile, func() int {
+		readCounter++
+		return readCounter
+	})
+	require.NoError(t, err, "Failed to start watcher")
+
+	reloaded := make(chan error, 1)
+	go func() {
+		reloaded <- handle.Reload()
+	}()
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, 1, event.OldConfig, "Old config should be the initial read")
+		assert.Equal(t, 2, event.NewConfig, "New config should be read by the reload")
+		assert.Equal(t, ReloadOperation, event.Operation, "Operation should mark the reload")
+		assert.Empty(t, event.Source, "Reload should have no source file")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the reload event")
+	}
+	assert.NoError(t, <-reloaded, "Reload should succeed once the event is received")
+}
+
+// TestWatchHandle_ReloadAfterStop
+// This test verifies that Reload reports ErrWatcherStopped once the watcher has stopped.
+func TestWatchHandle_ReloadAfterStop(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handle, err := Watch(ctx, tempFile, func() string {
+		return "config"
+	})
+	require.NoError(t, err, "Failed to start watcher")
+
+	cancel()
+	for range handle.Events() {
+	}
+
+	assert.ErrorIs(t, handle.Reload(), ErrWatcherStopped)
+}
+
+// TestWatchHandle_TokenBucket
+// This test verifies that WithTokenBucket delays events instead of dropping them.
+// With a burst of one and two events per second, the second of two reloads must wait about half a second.
+func TestWatchHandle_TokenBucket(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	handle, err := Watch(ctx, tempFile, func() string {
+		return "config"
+	}, WithTokenBucket(2, 1))
+	require.NoError(t, err, "Failed to start watcher")
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			_ = handle.Reload()
+		}
+	}()
+
+	var timestamps []time.Time
+	for len(timestamps) < 2 {
+		select {
+		case event := <-handle.Events():
+			timestamps = append(timestamps, event.Timestamp)
+		case <-ctx.Done():
+			t.Fatal("Timeout waiting for rate-limited events")
+		}
+	}
+	assert.GreaterOrEqual(t, timestamps[1].Sub(timestamps[0]), 400*time.Millisecond, "Second event should wait for a token")
+}
+
+// TestWatchHandle_TokenBucketErrors
+// This test verifies that WithTokenBucket rejects a burst below one, and that a wait for a token failing for
+// another reason than the watcher stopping is returned as is.
+func TestWatchHandle_TokenBucketErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	_, err := Watch(ctx, "config.yaml", func() string {
+		return "config"
+	}, WithTokenBucket(2, 0), WithWatcherFactory(factory.create))
+	assert.ErrorContains(t, err, "invalid token bucket burst 0", "A burst below one should be rejected")
+
+	handle, err := Watch(ctx, "config.yaml", func() string {
+		return "config"
+	}, WithTokenBucket(0.001, 1), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	go func() { _ = handle.Reload() }()
+	<-handle.Events()
+
+	// The next token is not available before the deadline of the reload
+	reloadCtx, cancelReload := context.WithTimeout(ctx, time.Second)
+	defer cancelReload()
+	_, err = handle.reload(reloadCtx, "", ReloadOperation)
+	assert.ErrorContains(t, err, "would exceed context deadline", "The error of the token bucket should be returned")
+	assert.NotErrorIs(t, err, ErrWatcherStopped)
+
+	cancel()
+	<-handle.Done()
+	_, err = handle.reload(context.Background(), "", ReloadOperation)
+	assert.ErrorIs(t, err, ErrWatcherStopped)
+}
+
+// TestWatchHandle_Ack
+// This test verifies that, with WithAckChannel, the second event is not delivered until the first one is acknowledged.
+func TestWatchHandle_Ack(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	handle, err := Watch(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(0), WithAckChannel(), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	require.NotNil(t, handle.Ack(), "Ack channel should be available")
+
+	watcher := factory.next(t)
+	watcher.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, 2, event.NewConfig, "First event should be delivered")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the first event")
+	}
+
+	watcher.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+
+	select {
+	case event := <-handle.Events():
+		t.Fatalf("Second event delivered before the first was acknowledged: %+v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	handle.Ack() <- struct{}{}
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, 2, event.OldConfig, "Second event should follow the first")
+		assert.Equal(t, 3, event.NewConfig, "Second event should be delivered after the acknowledgement")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the second event")
+	}
+}
+
+// TestWatchHandle_Done
+// This test verifies that Done is closed after the context is cancelled, once the watcher has fully shut down:
+// the file watcher is closed, the Events channel is closed and no goroutine of the watcher is left running.
+func TestWatchHandle_Done(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	handle, err := Watch(ctx, "config.yaml", func() string {
+		return "config"
+	}, WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	watcher := factory.next(t)
+
+	select {
+	case <-handle.Done():
+		t.Fatal("Done should not be closed while the watcher is running")
+	default:
+	}
+
+	cancel()
+	select {
+	case <-handle.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for Done")
+	}
+
+	assert.True(t, watcher.isClosed(), "File watcher should be closed")
+	_, ok := <-handle.Events()
+	assert.False(t, ok, "Events channel should be closed")
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline, "Goroutines of the watcher should have exited")
+}
+
+// TestWatchHandle_FanOut
+// This test verifies that WithFanOut delivers every event to several concurrent consumers.
+// A stalled consumer must not block the others: its channel only keeps the events that fit into its buffer.
+func TestWatchHandle_FanOut(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	handle, err := Watch(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithFanOut(3), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	assert.Equal(t, handle.Events(), handle.EventsFor(0), "Channel 0 should be the Events channel")
+	assert.Nil(t, handle.EventsFor(3), "Channels beyond the fan-out should be nil")
+
+	// Forwards the configurations received on a channel until it is closed
+	consume := func(events <-chan ChangeEvent[int]) <-chan int {
+		received := make(chan int, 100)
+		go func() {
+			defer close(received)
+			for event := range events {
+				received <- event.NewConfig
+			}
+		}()
+		return received
+	}
+	first, third := consume(handle.EventsFor(0)), consume(handle.EventsFor(2))
+
+	// The consumer of channel 1 is stalled for more events than its buffer holds
+	const reloads = fanOutBufferSize + 4
+	for i := 0; i < reloads; i++ {
+		require.NoError(t, handle.Reload(), "Reload should not be blocked by a stalled consumer")
+		assert.Equal(t, i+2, <-first, "Channel 0 should receive every event")
+		assert.Equal(t, i+2, <-third, "Channel 2 should receive a copy of every event")
+	}
+	cancel()
+
+	var stalled []int
+	for event := range handle.EventsFor(1) {
+		stalled = append(stalled, event.NewConfig)
+	}
+	assert.Len(t, stalled, fanOutBufferSize, "Channel 1 should keep the events that fit into its buffer")
+	assert.Equal(t, 2, stalled[0], "Channel 1 should keep the oldest events")
+	_, open := <-third
+	assert.False(t, open, "Fan-out channels should be closed when the watcher stops")
+}
+
+// TestWithHTTPReloadEndpoint
+// This test verifies that POST and DELETE requests to the reload endpoint reload the configuration
+// and report the version of the event, that a failed reload is reported with 500, and that other methods get 405.
+func TestWithHTTPReloadEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	mux := http.NewServeMux()
+	factory := newFakeWatcherFactory()
+	readCounter := 0
+	handle, err := Watch(ctx, "config.yaml", func() int {
+		readCounter++
+		if readCounter == 4 {
+			panic("invalid configuration")
+		}
+		return readCounter
+	}, WithHTTPReloadEndpoint(mux, "/config/reload"), WithWatcherFactory(factory.create), WithErrorHandler(func(error) {}))
+	require.NoError(t, err, "Failed to start watcher")
+
+	received := make(chan int, 10)
+	go func() {
+		for event := range handle.Events() {
+			received <- event.NewConfig
+		}
+	}()
+
+	// Sends a request to the endpoint and decodes its JSON body
+	request := func(method string) (int, map[string]any) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(method, "/config/reload", nil))
+		var body map[string]any
+		if recorder.Code != http.StatusMethodNotAllowed {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body), "Body should be JSON")
+		}
+		return recorder.Code, body
+	}
+
+	status, body := request(http.MethodPost)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"reloaded": true, "version": "1"}, body)
+	assert.Equal(t, 2, <-received, "POST should reload the configuration")
+
+	status, body = request(http.MethodDelete)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"reloaded": true, "version": "2"}, body)
+	assert.Equal(t, 3, <-received, "DELETE should reload the configuration")
+
+	status, body = request(http.MethodPost)
+	assert.Equal(t, http.StatusInternalServerError, status, "A failed reload should be reported")
+	assert.Equal(t, false, body["reloaded"])
+	assert.Contains(t, body["error"], "invalid configuration")
+
+	status, _ = request(http.MethodGet)
+	assert.Equal(t, http.StatusMethodNotAllowed, status, "GET should not reload the configuration")
+
+	cancel()
+	<-handle.Done()
+	status, body = request(http.MethodPost)
+	assert.Equal(t, http.StatusServiceUnavailable, status, "Reloads should fail once the watcher stopped")
+	assert.Equal(t, ErrWatcherStopped.Error(), body["error"])
+
+	// A watcher restarted with the same mux and path takes the endpoint over
+	ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	handle, err = Watch(ctx, "config.yaml", func() int {
+		return 10
+	}, WithHTTPReloadEndpoint(mux, "/config/reload"), WithWatcherFactory(newFakeWatcherFactory().create))
+	require.NoError(t, err, "Failed to restart watcher")
+
+	// Nobody receives the events, so the reload is bounded by the context of the request
+	requestCtx, cancelRequest := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelRequest()
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/config/reload", nil).WithContext(requestCtx))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "A stalled reload should end with the request")
+
+	go func() {
+		for event := range handle.Events() {
+			received <- event.NewConfig
+		}
+	}()
+	status, body = request(http.MethodPost)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"reloaded": true, "version": "1"}, body)
+	assert.Equal(t, 10, <-received, "The restarted watcher should be reloaded")
+}
@@ -0,0 +1,99 @@
+// Package httpwatcher watches configurations served over HTTP by polling them with conditional requests.
+package httpwatcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// ControlHTTPChanges monitors a configuration served at a URL and sends detected updates through the returned handle.
+// The URL is polled with conditional GET requests (If-None-Match / If-Modified-Since), and fetchFn is called
+// to decode the configuration only when the server returns 200 OK; 304 Not Modified responses are skipped.
+// The response body is closed after fetchFn returns.
+//
+// The configuration is fetched once before the function returns, and an error is returned if that fails.
+// Later polling failures are reported to the error handler of the watcher. Change events have the URL as source.
+// WatchHandle.Reload emits the last fetched configuration without polling.
+func ControlHTTPChanges[T any](ctx context.Context, url string, fetchFn func(resp *http.Response) T, opts ...Option) (*watcher.WatchHandle[T], error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	source := &httpSource[T]{
+		url:     url,
+		fetchFn: fetchFn,
+		options: options,
+	}
+	if _, err := source.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	return watcher.WatchPolling(ctx, url, source.fetch, source.current, options.PollingOptions)
+}
+
+// httpSource fetches a configuration from a URL and keeps the last fetched value and its validators.
+type httpSource[T any] struct {
+	url     string
+	fetchFn func(resp *http.Response) T
+	options *Options
+
+	mutex        sync.Mutex
+	value        T
+	etag         string
+	lastModified string
+}
+
+// Returns the last fetched configuration.
+func (s *httpSource[T]) current() T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.value
+}
+
+// Polls the URL and reports whether a new configuration was fetched.
+func (s *httpSource[T]) fetch(ctx context.Context) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request for %s: %w", s.url, err)
+	}
+	for key, value := range s.options.headers {
+		request.Header.Set(key, value)
+	}
+
+	s.mutex.Lock()
+	if s.etag != "" {
+		request.Header.Set("If-None-Match", s.etag)
+	}
+	if s.lastModified != "" {
+		request.Header.Set("If-Modified-Since", s.lastModified)
+	}
+	s.mutex.Unlock()
+
+	response, err := s.options.client.Do(request)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", s.url, err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to fetch %s: unexpected status %s", s.url, response.Status)
+	}
+
+	value := s.fetchFn(response)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.value = value
+	s.etag = response.Header.Get("ETag")
+	s.lastModified = response.Header.Get("Last-Modified")
+	return true, nil
+}
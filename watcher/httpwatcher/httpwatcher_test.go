@@ -0,0 +1,129 @@
+package httpwatcher
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// configServer serves a configuration with an ETag derived from its version.
+type configServer struct {
+	mutex   sync.Mutex
+	version int
+	body    string
+
+	notModified atomic.Int32
+	authorized  atomic.Bool
+}
+
+func (s *configServer) set(body string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.version++
+	s.body = body
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.authorized.Store(r.Header.Get("Authorization") == "Bearer token")
+	etag := `"` + strconv.Itoa(s.version) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		s.notModified.Add(1)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_, _ = io.WriteString(w, s.body)
+}
+
+func readBody(resp *http.Response) string {
+	data, _ := io.ReadAll(resp.Body)
+	return string(data)
+}
+
+// TestControlHTTPChanges
+// This test verifies that polling skips unchanged configurations with conditional requests
+// and emits an event when the served configuration changes.
+func TestControlHTTPChanges(t *testing.T) {
+	server := &configServer{}
+	server.set("initial")
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var fetches atomic.Int32
+	handle, err := ControlHTTPChanges(ctx, httpServer.URL, func(resp *http.Response) string {
+		fetches.Add(1)
+		return readBody(resp)
+	}, WithPolling(20*time.Millisecond), WithHTTPHeader("Authorization", "Bearer token"))
+	require.NoError(t, err, "Failed to start HTTP watcher")
+
+	assert.Eventually(t, func() bool {
+		return server.notModified.Load() >= 2
+	}, time.Second, 10*time.Millisecond, "Unchanged configuration should be answered with 304")
+	assert.Equal(t, int32(1), fetches.Load(), "fetchFn should not be called for 304 responses")
+	assert.True(t, server.authorized.Load(), "Requests should carry the configured header")
+
+	server.set("updated")
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, "initial", event.OldConfig, "Old config should be the initial fetch")
+		assert.Equal(t, "updated", event.NewConfig, "New config should be the updated fetch")
+		assert.Equal(t, httpServer.URL, event.Source, "Source should be the URL")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the change event")
+	}
+}
+
+// TestControlHTTPChanges_Errors
+// This test verifies that a failing initial fetch is returned and that polling failures are reported to the error handler.
+func TestControlHTTPChanges_Errors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var failing atomic.Bool
+	failing.Store(true)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, "config")
+	}))
+	defer httpServer.Close()
+
+	_, err := ControlHTTPChanges(ctx, httpServer.URL, readBody)
+	assert.ErrorContains(t, err, "unexpected status 500", "Initial fetch failure should be returned")
+
+	failing.Store(false)
+	errs := make(chan error, 10)
+	_, err = ControlHTTPChanges(ctx, httpServer.URL, readBody, WithPolling(20*time.Millisecond),
+		WithWatcherOptions(watcher.WithErrorHandler(func(err error) {
+			errs <- err
+		})))
+	require.NoError(t, err, "Failed to start HTTP watcher")
+
+	failing.Store(true)
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "unexpected status 500", "Polling failure should be reported")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the polling error")
+	}
+}
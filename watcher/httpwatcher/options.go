@@ -0,0 +1,62 @@
+package httpwatcher
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// Options holds the settings of an HTTP watcher.
+type Options struct {
+	watcher.PollingOptions
+	client  *http.Client
+	headers map[string]string
+}
+
+func defaultOptions() *Options {
+	return &Options{
+		PollingOptions: watcher.PollingOptions{Interval: 30 * time.Second},
+		client:         http.DefaultClient,
+		headers:        make(map[string]string),
+	}
+}
+
+// Option defines a function signature for setting Options.
+type Option func(*Options)
+
+// WithPolling
+// This option sets the interval between two requests to the URL.
+// The default interval is 30 seconds.
+func WithPolling(interval time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = interval
+	}
+}
+
+// WithHTTPClient
+// This option sets the HTTP client used for polling, e.g. to configure timeouts or TLS.
+// By default http.DefaultClient is used.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *Options) {
+		o.client = c
+	}
+}
+
+// WithHTTPHeader
+// This option adds a header to every request, e.g. for authentication.
+// Setting the same header again replaces its value.
+func WithHTTPHeader(k, v string) Option {
+	return func(o *Options) {
+		o.headers[k] = v
+	}
+}
+
+// WithWatcherOptions
+// This option passes options to the underlying watcher, e.g. watcher.WithErrorHandler or watcher.WithDebounce.
+// The watcher factory is always replaced by the HTTP poller.
+func WithWatcherOptions(opts ...watcher.Option) Option {
+	return func(o *Options) {
+		o.WatcherOptions = append(o.WatcherOptions, opts...)
+	}
+}
@@ -0,0 +1,41 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrUnexpectedMIMEType is reported when a changed file does not have the content type expected by WithMIMETypeCheck.
+type ErrUnexpectedMIMEType struct {
+	Got  string
+	Want string
+}
+
+func (e ErrUnexpectedMIMEType) Error() string {
+	return fmt.Sprintf("unexpected MIME type %q, want %q", e.Got, e.Want)
+}
+
+// Detects the content type of a file from its first 512 bytes and checks that it contains the expected type.
+func checkMIMEType(path, expected string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to check MIME type of %s: %w", path, err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to check MIME type of %s: %w", path, err)
+	}
+
+	detected := http.DetectContentType(header[:n])
+	if !strings.Contains(detected, expected) {
+		return ErrUnexpectedMIMEType{Got: detected, Want: expected}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestControlFileChanges_MIMETypeCheckRejectsBinary
+// This test verifies that WithMIMETypeCheck rejects a binary file swapped into the config path.
+// No event must be emitted, and the error handler must receive an ErrUnexpectedMIMEType.
+func TestControlFileChanges_MIMETypeCheckRejectsBinary(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	errs := make(chan error, 10)
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithMIMETypeCheck("text/"), WithDebounce(50*time.Millisecond), WithErrorHandler(func(err error) {
+		errs <- err
+	}))
+	require.NoError(t, err, "Failed to start watcher")
+
+	// ELF header followed by binary data
+	writeFile(t, tempFile, "\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+	select {
+	case err := <-errs:
+		var mimeErr ErrUnexpectedMIMEType
+		require.True(t, errors.As(err, &mimeErr), "Error should be ErrUnexpectedMIMEType: %v", err)
+		assert.Equal(t, "text/", mimeErr.Want)
+		assert.Equal(t, "application/octet-stream", mimeErr.Got)
+	case event := <-updates:
+		t.Fatalf("Unexpected event for a binary file: %+v", event)
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for MIME type error")
+	}
+}
+
+// TestControlFileChanges_MIMETypeCheckIgnoresExtension
+// This test verifies that the MIME type check inspects the content rather than the file extension.
+// A JSON file with a binary extension must pass a "text/" check.
+func TestControlFileChanges_MIMETypeCheckIgnoresExtension(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "config.bin")
+	writeFile(t, tempFile, `{"initial": true}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithMIMETypeCheck("text/"), WithDebounce(50*time.Millisecond), WithErrorHandler(func(err error) {
+		t.Errorf("Unexpected error: %v", err)
+	}))
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, tempFile, `{"updated": true}`)
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, `{"updated": true}`, event.NewConfig)
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for file change event")
+	}
+}
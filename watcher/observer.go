@@ -0,0 +1,38 @@
+package watcher
+
+import (
+	"errors"
+	"time"
+)
+
+// Observer receives the activity of a watcher, e.g. to record metrics, see WithObserver.
+// Its methods are called synchronously by the goroutines of the watcher and must not block.
+type Observer interface {
+	// OnEvent is called for every relevant file event, before it is debounced.
+	OnEvent(path string)
+	// OnDebounceFire is called when the debounce of a file fires, before its configuration is read.
+	OnDebounceFire(path string)
+	// OnConfigRead is called when getCurrentConfigFn returned, with the time it took.
+	OnConfigRead(path string, latency time.Duration)
+	// OnEventSuppressed is called when no change event is sent because the content of the file did not change
+	// (WithCRC32Check) or the change was filtered out (WithFieldChangeFilter).
+	OnEventSuppressed(path string)
+	// OnError is called for every error passed to the error handler, with the path of the file it relates to,
+	// or an empty path if it is not known.
+	OnError(path string, err error)
+	// Close is called once when the watcher has stopped.
+	Close()
+}
+
+// Returns the path of the file an error relates to, or an empty path if it is not known.
+func errorPath(err error) string {
+	var panicErr PanicError
+	if errors.As(err, &panicErr) {
+		return panicErr.Source
+	}
+	var addErr ErrWatchAdd
+	if errors.As(err, &addErr) {
+		return addErr.Path
+	}
+	return ""
+}
@@ -1,11 +1,18 @@
 package watcher
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"reflect"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 type ErrorHandler func(err error)
+type PanicHandler func(recovered interface{}, stack []byte)
 type Logger interface {
 	Printf(format string, v ...interface{})
 }
@@ -18,6 +25,54 @@ type Options struct {
 	debounceDuration time.Duration
 	logChanges       bool
 	logger           Logger
+	batchAcrossFiles bool
+	panicHandler     PanicHandler
+	panicToError     bool
+	debounceStrategy DebounceStrategy
+	clock            Clock
+	fileStat         bool
+	// idempotentReadAttempts and idempotentReadInterval repeat the reads until they are stable, see WithIdempotentRead.
+	idempotentReadAttempts int
+	idempotentReadInterval time.Duration
+	// dedupWindow suppresses the events repeating the last sent configuration, see WithDedupWindow.
+	dedupWindow time.Duration
+	// immediateFirstChange selects ImmediateFirstDebounce as the default strategy, see WithImmediateFirstChange.
+	immediateFirstChange bool
+	watcherFactory       func() (FileWatcher, error)
+	autoRecover          bool
+	mimeType             string
+	limiter              *rate.Limiter
+
+	rotationRecovery    bool
+	rotationGracePeriod time.Duration
+	startupGrace        time.Duration
+
+	// changeFilter reports whether a change is interesting, or an error if the configurations are not of the type
+	// of the filter, see WithFieldChangeFilter.
+	changeFilter func(oldConfig, newConfig any) (bool, error)
+	ackChannel   bool
+	// pathFilter selects the events of a watched directory by file name, see ControlGlobChanges.
+	pathFilter func(name string) bool
+
+	crc32Check     bool
+	shutdownEvent  bool
+	debounceTracer DebounceTracer
+	observer       Observer
+	// newObserver creates the observer when the watcher starts, see WithObserverFactory.
+	newObserver    func() (Observer, error)
+	watchParentDir bool
+	fanOut         int
+	// internalBufferSize is the capacity of the channel of raw file events, see WithInternalBufferSize.
+	internalBufferSize int
+	// reloadMux and reloadPath register the reload handler of WithHTTPReloadEndpoint.
+	reloadMux  *http.ServeMux
+	reloadPath string
+	// onContent receives the bytes read for the checksum, see ControlFileBytesChanges.
+	onContent func(data []byte)
+	// readFile reads the file for the checksum and ControlFileBytesChanges, see WithSudoRead.
+	readFile func(ctx context.Context, path string) ([]byte, error)
+	// invalid is the error of an option given an invalid value, returned when the watcher starts.
+	invalid error
 }
 
 func defaultWatcherOptions() *Options {
@@ -27,7 +82,43 @@ func defaultWatcherOptions() *Options {
 		},
 		debounceDuration: 10 * time.Millisecond,
 		logger:           &NoOpLogger{},
+		watcherFactory:   newFSNotifyWatcher,
+		clock:            systemClock{},
+		readFile:         readFile,
+
+		rotationGracePeriod: defaultRotationGracePeriod,
+	}
+}
+
+// PanicError is passed to the error handler when getCurrentConfigFn panics, see WithPanicHandler.
+// Its message includes the file whose change triggered the read and the stack trace of the panic.
+type PanicError struct {
+	// Source is the path of the file whose change triggered the read, empty for reloads.
+	Source string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that panicked, as returned by debug.Stack.
+	Stack []byte
+}
+
+func (e PanicError) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "reload"
+	}
+	return fmt.Sprintf("panic in getCurrentConfigFn (source: %s): %v\n%s", source, e.Value, e.Stack)
+}
+
+// handlePanic dispatches a panic recovered from getCurrentConfigFn to the panic handler and,
+// unless only the panic handler is configured, to the error handler.
+func (o *Options) handlePanic(recovered interface{}, stack []byte, source string) {
+	if o.panicHandler != nil {
+		o.panicHandler(recovered, stack)
+		if !o.panicToError {
+			return
+		}
 	}
+	o.errorHandler(PanicError{Source: source, Value: recovered, Stack: stack})
 }
 
 // Option defines a function signature for setting WatcherOptions.
@@ -57,6 +148,7 @@ func WithFileChangeLogging() Option {
 // This option sets a debounce duration for file change events.
 // When multiple rapid file changes occur, only the final change after the specified duration will trigger an event.
 // This prevents excessive processing caused by frequent updates.
+// The events are debounced with a Debouncer per file, see TrailingDebounce.
 func WithDebounce(duration time.Duration) Option {
 	return func(o *Options) {
 		o.debounceDuration = duration
@@ -72,3 +164,330 @@ func WithLogger(logger Logger) Option {
 		o.logger = logger
 	}
 }
+
+// WithBatchAcrossFiles
+// This option makes the debounce coalesce events from all watched files.
+// When several files change within the debounce window, getCurrentConfigFn is called once and a single event is emitted.
+// By default each file is debounced separately, which results in one event per changed file.
+func WithBatchAcrossFiles() Option {
+	return func(o *Options) {
+		o.batchAcrossFiles = true
+	}
+}
+
+// WithPanicHandler
+// This option sets a handler for panics recovered from getCurrentConfigFn.
+// The handler receives the recovered value and the stack trace captured at the point of recovery.
+// When a panic handler is set, panics are no longer forwarded to the error handler unless WithPropagatedPanicToError is also used.
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(o *Options) {
+		o.panicHandler = handler
+	}
+}
+
+// WithPropagatedPanicToError
+// This option forwards recovered panics to the error handler in addition to the panic handler.
+// It has no effect without WithPanicHandler, since panics are always forwarded to the error handler in that case.
+func WithPropagatedPanicToError() Option {
+	return func(o *Options) {
+		o.panicToError = true
+	}
+}
+
+// WithClock
+// This option sets the clock measuring the debounce, the startup grace period and the durations reported to
+// WithDebounceTracing and WithObserver, and timestamping the events, e.g. a testutil.FakeClock that makes tests
+// of the debounce deterministic. The built-in debounce strategies,
+// including those passed to WithDebounceStrategy, use this clock. By default the system clock is used.
+func WithClock(clock Clock) Option {
+	return func(o *Options) {
+		o.clock = clock
+	}
+}
+
+// WithFileStat
+// This option sets the FileModTime of the change events to the modification time of their source file,
+// stat-ed when the event is emitted, e.g. to correlate reloads with the file system in audit logs.
+// Reloads with WatchHandle.Reload have no source file and no modification time.
+func WithFileStat() Option {
+	return func(o *Options) {
+		o.fileStat = true
+	}
+}
+
+// WithImmediateFirstChange
+// This option emits the first change after a quiet period immediately instead of after the debounce duration,
+// while the further changes of a burst are debounced as usual, see ImmediateFirstDebounce. A single edit then
+// reloads the configuration without delay even with a long debounce. It has no effect with WithDebounceStrategy.
+func WithImmediateFirstChange() Option {
+	return func(o *Options) {
+		o.immediateFirstChange = true
+	}
+}
+
+// WithDebounceStrategy
+// This option replaces the debounce logic with a custom strategy, e.g. LeadingDebounce or MaxWaitDebounce.
+// The strategy decides when file events trigger a configuration reload, and overrides WithDebounce.
+// A strategy instance keeps per-watcher state and must not be shared between watchers.
+func WithDebounceStrategy(strategy DebounceStrategy) Option {
+	return func(o *Options) {
+		o.debounceStrategy = strategy
+	}
+}
+
+// WithAutoRecover
+// This option keeps the watcher alive when the underlying file system watcher dies, e.g. when its event channels close.
+// The file system watcher is recreated and all paths are re-added, retrying with exponential backoff until it succeeds.
+// Failed attempts are passed to the error handler, and the recovery is logged with the configured logger.
+// By default the watcher stops and closes the updates channel in this case.
+func WithAutoRecover() Option {
+	return func(o *Options) {
+		o.autoRecover = true
+	}
+}
+
+// WithWatcherFactory
+// This option replaces the fsnotify-based file watcher with a custom FileWatcher implementation.
+// The factory is called when the watcher starts and, with WithAutoRecover, whenever the file watcher is recreated.
+// This allows feeding synthetic events to the watcher, which makes its behavior testable without the file system.
+func WithWatcherFactory(factory func() (FileWatcher, error)) Option {
+	return func(o *Options) {
+		o.watcherFactory = factory
+	}
+}
+
+// WithMIMETypeCheck
+// This option guards against non-config content, such as a binary accidentally swapped into the config path.
+// Before getCurrentConfigFn is called, the content type of the changed file is detected from its first 512 bytes
+// with http.DetectContentType. If it does not contain expected (e.g. "text/"), the change is rejected and
+// an ErrUnexpectedMIMEType is passed to the error handler.
+// Note that http.DetectContentType reports JSON and YAML files as "text/plain; charset=utf-8".
+func WithMIMETypeCheck(expected string) Option {
+	return func(o *Options) {
+		o.mimeType = expected
+	}
+}
+
+// WithTokenBucket
+// This option limits the rate of emitted events with a token bucket of the given rate (events per second) and burst.
+// Instead of dropping events, the emission of an event waits until a token is available, which suits
+// consumers that must process every change but at a limited rate, e.g. because of expensive validation.
+// The configuration is read once the token is available, so the event carries the latest configuration.
+// Note that waiting blocks the debounce: with a slow consumer and frequent writes, events queue up behind
+// the token bucket, and the waits grow with the number of debounce streams (one per file by default).
+// A burst below 1 would never allow an event, so the watcher fails to start with it.
+func WithTokenBucket(r float64, burst int) Option {
+	return func(o *Options) {
+		if burst < 1 {
+			o.invalid = fmt.Errorf("invalid token bucket burst %d: must be at least 1", burst)
+			return
+		}
+		o.limiter = rate.NewLimiter(rate.Limit(r), burst)
+	}
+}
+
+// WithRotationRecovery
+// This option keeps watching a file that is rotated, e.g. by logrotate renaming config.yaml to config.yaml.1
+// and creating a new config.yaml, after which the watcher would otherwise keep watching the old file.
+// When a watched file is renamed or removed, the watcher polls for the path to reappear, re-adds it and
+// emits a change event with the content of the new file. If the file does not reappear within the grace
+// period (see WithRotationGracePeriod), an ErrRotationTimeout is passed to the error handler.
+func WithRotationRecovery() Option {
+	return func(o *Options) {
+		o.rotationRecovery = true
+	}
+}
+
+// WithRotationGracePeriod
+// This option sets how long WithRotationRecovery waits for a rotated file to reappear. It defaults to 5 seconds.
+func WithRotationGracePeriod(d time.Duration) Option {
+	return func(o *Options) {
+		o.rotationGracePeriod = d
+	}
+}
+
+// WithStartupGrace
+// This option ignores file events for the given duration after the watcher starts, once the initial
+// configuration has been read. This avoids a needless reload when deploy tooling writes the file right
+// after the process starts. Programmatic reloads with WatchHandle.Reload are not affected.
+func WithStartupGrace(d time.Duration) Option {
+	return func(o *Options) {
+		o.startupGrace = d
+	}
+}
+
+// WithIdempotentRead
+// This option reads the configuration up to attempts times, interval apart, until two consecutive reads return
+// equal configurations as compared by reflect.DeepEqual, which then becomes the new configuration. This guards
+// against reading a file that is still being written, and unlike a checksum it also works when the bytes of the
+// file vary for the same configuration, e.g. regenerated JSON with a different key order. If the reads never
+// stabilize, the last one is used and a warning is logged with the logger of WithLogger.
+// reflect.DeepEqual compares the values behind pointers rather than the pointers, and never finds functions or
+// NaN floats equal, so configurations holding them never stabilize. The interval is measured with the clock of
+// WithClock. The reads are made before the change waits for other changes to be sent, and stop early when the
+// watcher stops.
+func WithIdempotentRead(attempts int, interval time.Duration) Option {
+	return func(o *Options) {
+		o.idempotentReadAttempts = attempts
+		o.idempotentReadInterval = interval
+	}
+}
+
+// WithDedupWindow
+// This option remembers the last configuration sent for d, and suppresses the change events of an equal
+// configuration within that window, as compared by reflect.DeepEqual. This smooths out flappy edits, e.g. a file
+// reverted and rewritten by two separate saves, which WithCRC32Check does not catch when the bytes differ.
+// The window starts when an event is sent; suppressed changes do not extend it. Reloads with WatchHandle.Reload
+// are never suppressed.
+func WithDedupWindow(d time.Duration) Option {
+	return func(o *Options) {
+		o.dedupWindow = d
+	}
+}
+
+// WithFieldChangeFilter
+// This option emits events only for interesting changes, e.g. to a specific field of a large configuration.
+// After every change, fn is called with the last configuration and the new one, and the event is suppressed
+// when it returns false. The new configuration still becomes the baseline of the next change, so the next
+// interesting change is compared against the configuration currently in the file.
+// T must be the configuration type of the watcher, or an interface it implements: the watcher fails to start
+// if the initial configuration is not a T. Should a later configuration not be a T, e.g. with a watcher of an
+// interface type, its events are emitted unfiltered and the mismatch is reported once to the error handler.
+// Events of WatchHandle.Reload are never filtered.
+func WithFieldChangeFilter[T any](fn func(oldCfg, newCfg T) bool) Option {
+	return func(o *Options) {
+		o.changeFilter = func(oldConfig, newConfig any) (bool, error) {
+			oldCfg, oldOK := oldConfig.(T)
+			newCfg, newOK := newConfig.(T)
+			if !oldOK || !newOK {
+				mismatch := oldConfig
+				if oldOK {
+					mismatch = newConfig
+				}
+				return true, fmt.Errorf("field change filter takes a %s, but the configuration is a %T", reflect.TypeFor[T](), mismatch)
+			}
+			return fn(oldCfg, newCfg), nil
+		}
+	}
+}
+
+// WithAckChannel
+// This option makes the watcher wait for the consumer to acknowledge every event before emitting the next one,
+// which guarantees that reloads never overlap. The consumer sends on the channel returned by WatchHandle.Ack
+// after applying an event; changes detected in the meantime are held back (and coalesced by the debounce).
+// Since the channel is only available from the handle, use Watch or WatchFiles with this option.
+func WithAckChannel() Option {
+	return func(o *Options) {
+		o.ackChannel = true
+	}
+}
+
+// WithCRC32Check
+// This option skips events for which the content of the file did not change, e.g. when the file is touched or
+// rewritten with the same content by a configuration management tool. Before the configuration is read, the
+// CRC-32 (IEEE) checksum of the file is compared with the one of the previous successful read.
+// With ControlFileChanges the file is read once more for the checksum; use ControlFileBytesChanges to read it
+// only once. Events of WatchHandle.Reload are never skipped.
+func WithCRC32Check() Option {
+	return func(o *Options) {
+		o.crc32Check = true
+	}
+}
+
+// WithShutdownEvent
+// This option sends a final event with IsShutdown set before the updates channel is closed because the context
+// was cancelled, so that consumers can tell a clean shutdown from a watcher that stopped on an error, whose channel
+// is closed without it. Both configurations of the event are the last known configuration.
+// The watcher waits up to a second for the event to be received before it finishes shutting down;
+// consumers that no longer receive once the context is cancelled miss the event, but do not block the shutdown.
+func WithShutdownEvent() Option {
+	return func(o *Options) {
+		o.shutdownEvent = true
+	}
+}
+
+// WithDebounceTracing
+// This option reports the debounce cycles of the watcher to the tracer: when the first event of a path is received,
+// and when the debounce fired, with the number of events aggregated and whether a change event was emitted.
+// Use StdoutDebounceTracer to print the cycles while debugging.
+func WithDebounceTracing(tracer DebounceTracer) Option {
+	return func(o *Options) {
+		o.debounceTracer = tracer
+	}
+}
+
+// WithInternalBufferSize
+// This option sets how many raw file events can queue between the file watcher and the debounce logic, separately
+// from the updates channel. By default one event per watched path can queue, and events received while the queue
+// is full are dropped so that the file watcher is never blocked. The debounce logic coalesces the queued events by
+// path (or across files with WithBatchAcrossFiles), so a dropped event is only missed when no other event of its
+// path is queued or being debounced, e.g. when many files of a directory watched by ControlGlobChanges change at once.
+// Set the size to the number of files that may change together. Values below 1 keep the default.
+func WithInternalBufferSize(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.internalBufferSize = n
+		}
+	}
+}
+
+// WithObserver
+// This option reports the activity of the watcher to the observer: file events, debounce fires, configuration reads
+// with their latency, suppressed events and errors. The observer is closed when the watcher has stopped.
+// See the watcher/otel package for an observer recording OpenTelemetry metrics.
+func WithObserver(observer Observer) Option {
+	return func(o *Options) {
+		o.observer, o.newObserver = observer, nil
+	}
+}
+
+// WithObserverFactory
+// This option reports the activity of the watcher like WithObserver, to an observer created by newObserver
+// when the watcher starts, so that every watcher started with the option has its own observer.
+// An error of newObserver is returned by the function starting the watcher. The observer is closed when the
+// watcher has stopped, or when it fails to start.
+func WithObserverFactory(newObserver func() (Observer, error)) Option {
+	return func(o *Options) {
+		o.observer, o.newObserver = nil, newObserver
+	}
+}
+
+// WithWatchParentDir
+// This option lets the watcher start before a watched file exists, e.g. while waiting for a configuration
+// to be provisioned. The parent directory of a missing file is watched instead, and once the file is created
+// the watch is narrowed to the file and a change event is emitted with the file as its source.
+// Other files of the directory are ignored. Without this option, watching a missing file fails with ErrWatchAdd.
+// It has no effect on ControlGlobChanges, which already watches the directory of its pattern.
+func WithWatchParentDir() Option {
+	return func(o *Options) {
+		o.watchParentDir = true
+	}
+}
+
+// WithFanOut
+// This option delivers the change events to n channels, for consumers that each need every event, e.g. a TLS
+// reloader and a connection pool. The channels are returned by WatchHandle.EventsFor: channel 0 is the Events
+// channel, whose consumer paces the watcher as usual, and the other channels are buffered and receive a copy of
+// every event sent on it. Copies are never waited for: an event is dropped for a consumer whose buffer is full,
+// so that a stalled consumer does not block the others. The events share the configurations they carry.
+func WithFanOut(n int) Option {
+	return func(o *Options) {
+		o.fanOut = n
+	}
+}
+
+// WithHTTPReloadEndpoint
+// This option registers a handler on mux at path that reloads the configuration like WatchHandle.Reload
+// when it receives a POST or DELETE request, e.g. from an orchestration system. It responds with 200 and
+// {"reloaded": true, "version": "..."}, where the version is the number of change events sent so far,
+// or with 500 and {"reloaded": false, "error": "..."} if the reload failed. Other methods get 405.
+// Like Reload, a request waits until the event has been received from the Events channel, but no longer than
+// the context of the request; it then gets 503, as do the requests once the watcher has stopped.
+// The handler is registered once per mux and path: a watcher started again with the same mux and path,
+// e.g. after a restart, takes the registration over.
+func WithHTTPReloadEndpoint(mux *http.ServeMux, path string) Option {
+	return func(o *Options) {
+		o.reloadMux = mux
+		o.reloadPath = path
+	}
+}
@@ -0,0 +1,164 @@
+// Package otel records the activity of configuration watchers as OpenTelemetry metrics.
+package otel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// The names of the recorded metrics.
+const (
+	EventsMetric            = "kongkit.watcher.events"
+	ErrorsMetric            = "kongkit.watcher.errors"
+	ConfigReadLatencyMetric = "kongkit.watcher.config_read_latency"
+	DebounceFiresMetric     = "kongkit.watcher.debounce_fires"
+	EventsSuppressedMetric  = "kongkit.watcher.events_suppressed"
+)
+
+// FilePathKey is the attribute holding the path of the file a measurement relates to.
+// Errors whose file is not known are recorded with an empty path.
+const FilePathKey = attribute.Key("file.path")
+
+// pathCounts holds the counters of a file.
+type pathCounts struct {
+	attributes    metric.MeasurementOption
+	events        int64
+	errors        int64
+	debounceFires int64
+	suppressed    int64
+}
+
+// MeterObserver is a watcher.Observer recording the activity of a watcher with the instruments of a meter:
+// the counters kongkit.watcher.events, kongkit.watcher.errors, kongkit.watcher.debounce_fires and
+// kongkit.watcher.events_suppressed, and the histogram kongkit.watcher.config_read_latency in seconds.
+// Every measurement has the path of the file as its file.path attribute.
+//
+// The counters are observable instruments whose callback is unregistered when the watcher stops,
+// after which the observer no longer records anything. Use one observer per watcher.
+type MeterObserver struct {
+	readLatency  metric.Float64Histogram
+	registration metric.Registration
+
+	mutex  sync.Mutex
+	counts map[string]*pathCounts
+	closed bool
+}
+
+// NewMeterObserver creates the instruments of a MeterObserver with the meter.
+func NewMeterObserver(meter metric.Meter) (*MeterObserver, error) {
+	o := &MeterObserver{counts: make(map[string]*pathCounts)}
+
+	events, err := meter.Int64ObservableCounter(EventsMetric,
+		metric.WithDescription("Number of relevant file events received by the watcher"), metric.WithUnit("{event}"))
+	if err != nil {
+		return nil, err
+	}
+	errs, err := meter.Int64ObservableCounter(ErrorsMetric,
+		metric.WithDescription("Number of errors reported by the watcher"), metric.WithUnit("{error}"))
+	if err != nil {
+		return nil, err
+	}
+	debounceFires, err := meter.Int64ObservableCounter(DebounceFiresMetric,
+		metric.WithDescription("Number of times the debounce of a file fired"), metric.WithUnit("{fire}"))
+	if err != nil {
+		return nil, err
+	}
+	suppressed, err := meter.Int64ObservableCounter(EventsSuppressedMetric,
+		metric.WithDescription("Number of changes not sent because the file content did not change or the change was filtered out"),
+		metric.WithUnit("{event}"))
+	if err != nil {
+		return nil, err
+	}
+	o.readLatency, err = meter.Float64Histogram(ConfigReadLatencyMetric,
+		metric.WithDescription("Time taken to read the configuration"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	o.registration, err = meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		o.mutex.Lock()
+		defer o.mutex.Unlock()
+		for _, counts := range o.counts {
+			observer.ObserveInt64(events, counts.events, counts.attributes)
+			observer.ObserveInt64(errs, counts.errors, counts.attributes)
+			observer.ObserveInt64(debounceFires, counts.debounceFires, counts.attributes)
+			observer.ObserveInt64(suppressed, counts.suppressed, counts.attributes)
+		}
+		return nil
+	}, events, errs, debounceFires, suppressed)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// WithOTELMeter
+// This option records the activity of the watcher as OpenTelemetry metrics with the meter, see MeterObserver.
+// The observer is created when the watcher starts, so the option can be shared by several watchers,
+// each with its own observer. Errors creating the instruments are returned by the function starting the watcher.
+func WithOTELMeter(meter metric.Meter) watcher.Option {
+	return watcher.WithObserverFactory(func() (watcher.Observer, error) {
+		observer, err := NewMeterObserver(meter)
+		if err != nil {
+			return nil, err
+		}
+		return observer, nil
+	})
+}
+
+// Increments a counter of a file, unless the observer is closed.
+func (o *MeterObserver) add(path string, counter func(counts *pathCounts) *int64) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.closed {
+		return
+	}
+	counts, ok := o.counts[path]
+	if !ok {
+		counts = &pathCounts{attributes: metric.WithAttributeSet(attribute.NewSet(FilePathKey.String(path)))}
+		o.counts[path] = counts
+	}
+	*counter(counts)++
+}
+
+func (o *MeterObserver) OnEvent(path string) {
+	o.add(path, func(counts *pathCounts) *int64 { return &counts.events })
+}
+
+func (o *MeterObserver) OnDebounceFire(path string) {
+	o.add(path, func(counts *pathCounts) *int64 { return &counts.debounceFires })
+}
+
+func (o *MeterObserver) OnEventSuppressed(path string) {
+	o.add(path, func(counts *pathCounts) *int64 { return &counts.suppressed })
+}
+
+func (o *MeterObserver) OnError(path string, err error) {
+	o.add(path, func(counts *pathCounts) *int64 { return &counts.errors })
+}
+
+func (o *MeterObserver) OnConfigRead(path string, latency time.Duration) {
+	o.mutex.Lock()
+	closed := o.closed
+	o.mutex.Unlock()
+	if !closed {
+		o.readLatency.Record(context.Background(), latency.Seconds(), metric.WithAttributes(FilePathKey.String(path)))
+	}
+}
+
+// Close unregisters the callback of the counters, and stops recording.
+func (o *MeterObserver) Close() {
+	o.mutex.Lock()
+	o.closed = true
+	o.mutex.Unlock()
+	if err := o.registration.Unregister(); err != nil {
+		otelapi.Handle(err)
+	}
+}
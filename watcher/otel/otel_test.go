@@ -0,0 +1,139 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// Collects the metrics of a reader by name.
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data), "Failed to collect metrics")
+	metrics := make(map[string]metricdata.Metrics)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
+
+// Returns the value of a counter for a file, or 0 if it was not recorded.
+func counterValue(metrics map[string]metricdata.Metrics, name, path string) int64 {
+	sum, ok := metrics[name].Data.(metricdata.Sum[int64])
+	if !ok {
+		return 0
+	}
+	for _, point := range sum.DataPoints {
+		if value, _ := point.Attributes.Value(FilePathKey); value.AsString() == path {
+			return point.Value
+		}
+	}
+	return 0
+}
+
+// TestWithOTELMeter
+// This test verifies that the activity of a watcher is recorded as metrics with the path of the file:
+// a change is counted as an event, a debounce fire and a configuration read, and rewriting the same content
+// with WithCRC32Check as a suppressed event. The counters are no longer reported once the watcher stopped.
+func TestWithOTELMeter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("initial"), 0644))
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	handle, err := watcher.Watch(ctx, path, func() string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}, watcher.WithCRC32Check(), WithOTELMeter(meter))
+	require.NoError(t, err, "Failed to start watcher")
+
+	require.NoError(t, os.WriteFile(path, []byte("updated"), 0644))
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, "updated", event.NewConfig)
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for event")
+	}
+
+	metrics := collect(t, reader)
+	assert.GreaterOrEqual(t, counterValue(metrics, EventsMetric, path), int64(1), "Events should be counted")
+	assert.GreaterOrEqual(t, counterValue(metrics, DebounceFiresMetric, path), int64(1), "Debounce fires should be counted")
+	histogram, ok := metrics[ConfigReadLatencyMetric].Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "Read latency should be recorded as a histogram")
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, uint64(1), histogram.DataPoints[0].Count, "One configuration read should be recorded")
+
+	require.NoError(t, os.WriteFile(path, []byte("updated"), 0644))
+	assert.Eventually(t, func() bool {
+		return counterValue(collect(t, reader), EventsSuppressedMetric, path) >= 1
+	}, time.Second, 10*time.Millisecond, "Unchanged content should be counted as suppressed")
+
+	cancel()
+	<-handle.Done()
+	metrics = collect(t, reader)
+	assert.NotContains(t, metrics, EventsMetric, "Counters should be unregistered once the watcher stopped")
+}
+
+// failingMeter is a meter failing to create its counters.
+type failingMeter struct {
+	noop.Meter
+}
+
+func (failingMeter) Int64ObservableCounter(string, ...metric.Int64ObservableCounterOption) (metric.Int64ObservableCounter, error) {
+	return nil, errors.New("simulated instrument failure")
+}
+
+// TestWithOTELMeter_Lifecycle
+// This test verifies that the option creates an observer for every watcher started with it, so that stopping
+// one watcher does not stop recording the other, and that the errors creating the instruments are returned.
+func TestWithOTELMeter_Lifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("initial"), 0644))
+	read := func() string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+
+	reader := sdkmetric.NewManualReader()
+	option := WithOTELMeter(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	firstCtx, stopFirst := context.WithCancel(ctx)
+	first, err := watcher.Watch(firstCtx, path, read, option)
+	require.NoError(t, err, "Failed to start the first watcher")
+	second, err := watcher.Watch(ctx, path, read, option)
+	require.NoError(t, err, "Failed to start the second watcher")
+
+	stopFirst()
+	<-first.Done()
+	require.NoError(t, os.WriteFile(path, []byte("updated"), 0644))
+	select {
+	case <-second.Events():
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for event")
+	}
+	assert.GreaterOrEqual(t, counterValue(collect(t, reader), EventsMetric, path), int64(1),
+		"The second watcher should keep recording after the first one stopped")
+
+	_, err = watcher.Watch(ctx, path, read, WithOTELMeter(failingMeter{}))
+	assert.ErrorContains(t, err, "simulated instrument failure", "Instrument errors should be returned")
+}
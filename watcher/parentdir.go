@@ -0,0 +1,71 @@
+package watcher
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// awaitedFiles tracks the watched files that did not exist when the watcher started.
+// Their parent directories are watched instead until they are created, see WithWatchParentDir.
+type awaitedFiles struct {
+	// paths maps the cleaned paths of the awaited files, as reported by directory events, to the watched paths.
+	paths map[string]string
+	// dirs counts the awaited files of each watched parent directory.
+	dirs map[string]int
+}
+
+// Returns the awaited files among the paths, i.e. those that do not exist.
+func newAwaitedFiles(paths []string) *awaitedFiles {
+	a := &awaitedFiles{paths: make(map[string]string), dirs: make(map[string]int)}
+	for _, pathToFile := range paths {
+		if _, err := os.Stat(pathToFile); errors.Is(err, fs.ErrNotExist) {
+			a.paths[filepath.Clean(pathToFile)] = pathToFile
+			a.dirs[filepath.Dir(filepath.Clean(pathToFile))]++
+		}
+	}
+	return a
+}
+
+// Returns the paths to add to the file watcher: the parent directories of the awaited files and the other paths.
+func (a *awaitedFiles) watchPaths(paths []string) []string {
+	added := make(map[string]bool, len(paths))
+	result := make([]string, 0, len(paths))
+	for _, pathToFile := range paths {
+		if _, ok := a.paths[filepath.Clean(pathToFile)]; ok {
+			pathToFile = filepath.Dir(filepath.Clean(pathToFile))
+		}
+		if !added[pathToFile] {
+			added[pathToFile] = true
+			result = append(result, pathToFile)
+		}
+	}
+	return result
+}
+
+// Returns the watched path of an awaited file reported by an event of its parent directory.
+func (a *awaitedFiles) lookup(name string) (string, bool) {
+	pathToFile, ok := a.paths[filepath.Clean(name)]
+	return pathToFile, ok
+}
+
+// Narrows the watch of a created file from its parent directory to the file itself.
+// The parent directory is no longer watched once no other file in it is awaited, unless it is watched itself.
+func (a *awaitedFiles) narrow(watcher FileWatcher, pathToFile string, watchesPath map[string]bool) error {
+	if err := watcher.Add(pathToFile); err != nil {
+		return ErrWatchAdd{Path: pathToFile, Err: err}
+	}
+	cleaned := filepath.Clean(pathToFile)
+	delete(a.paths, cleaned)
+
+	dir := filepath.Dir(cleaned)
+	a.dirs[dir]--
+	if a.dirs[dir] == 0 {
+		delete(a.dirs, dir)
+		if !watchesPath[dir] {
+			_ = watcher.Remove(dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// PollingOptions holds the settings of the watchers polling a source instead of watching files,
+// e.g. those of the httpwatcher and sqlwatcher packages, see WatchPolling.
+type PollingOptions struct {
+	// Interval is the time between two polls.
+	Interval time.Duration
+	// WatcherOptions are passed to the underlying watcher, whose watcher factory is always replaced by the poller.
+	WatcherOptions []Option
+}
+
+// WatchPolling monitors a source polled with fetch at the interval of the options, like Watch monitors a file.
+// fetch reports whether it fetched a new configuration, which getCurrentConfigFn then returns; fetch errors are
+// reported to the error handler of the watcher. Change events have name as source, e.g. the URL of the source.
+func WatchPolling[T any](ctx context.Context, name string, fetch func(ctx context.Context) (bool, error), getCurrentConfigFn func() T, options PollingOptions) (*WatchHandle[T], error) {
+	factory := func() (FileWatcher, error) {
+		return NewPollingWatcher(name, options.Interval, fetch), nil
+	}
+	watcherOptions := append(append([]Option(nil), options.WatcherOptions...), WithWatcherFactory(factory))
+	return Watch(ctx, name, getCurrentConfigFn, watcherOptions...)
+}
+
+// pollingWatcher is a FileWatcher that reports a write event whenever polling fetches a new configuration.
+type pollingWatcher struct {
+	name   string
+	fetch  func(ctx context.Context) (bool, error)
+	events chan fsnotify.Event
+	errors chan error
+	cancel context.CancelFunc
+}
+
+// NewPollingWatcher returns a FileWatcher calling fetch at every interval until it is closed. It reports a write
+// event of name whenever fetch reports a new configuration, and the errors of fetch. Add and Remove do nothing.
+func NewPollingWatcher(name string, interval time.Duration, fetch func(ctx context.Context) (bool, error)) FileWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &pollingWatcher{
+		name:   name,
+		fetch:  fetch,
+		events: make(chan fsnotify.Event),
+		errors: make(chan error),
+		cancel: cancel,
+	}
+	go w.run(ctx, interval)
+	return w
+}
+
+func (w *pollingWatcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := w.fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			select {
+			case w.errors <- err:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if changed {
+			select {
+			case w.events <- fsnotify.Event{Name: w.name, Op: fsnotify.Write}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Add does nothing: the poller watches its source.
+func (w *pollingWatcher) Add(name string) error {
+	return nil
+}
+
+// Remove does nothing: the poller watches its source.
+func (w *pollingWatcher) Remove(name string) error {
+	return nil
+}
+
+// Close stops polling.
+func (w *pollingWatcher) Close() error {
+	w.cancel()
+	return nil
+}
+
+func (w *pollingWatcher) Events() <-chan fsnotify.Event {
+	return w.events
+}
+
+func (w *pollingWatcher) Errors() <-chan error {
+	return w.errors
+}
@@ -0,0 +1,65 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewPollingWatcher
+// This test verifies that the polling watcher reports a write event for the fetches reporting a change,
+// nothing for unchanged fetches, the errors of fetch, and that it stops polling once closed.
+func TestNewPollingWatcher(t *testing.T) {
+	var polls atomic.Int32
+	blocked := make(chan struct{})
+	cancelled := make(chan struct{})
+	w := NewPollingWatcher("source", 10*time.Millisecond, func(ctx context.Context) (bool, error) {
+		switch polls.Add(1) {
+		case 2:
+			return true, nil
+		case 3:
+			return false, errors.New("simulated fetch failure")
+		case 4:
+			// Blocks until the watcher is closed, so that no poll can follow
+			close(blocked)
+			<-ctx.Done()
+			close(cancelled)
+			return false, ctx.Err()
+		default:
+			return false, nil
+		}
+	})
+
+	select {
+	case event := <-w.Events():
+		assert.Equal(t, fsnotify.Event{Name: "source", Op: fsnotify.Write}, event)
+		assert.Equal(t, int32(2), polls.Load(), "Only the changed fetch should be reported")
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the change event")
+	}
+	select {
+	case err := <-w.Errors():
+		assert.ErrorContains(t, err, "simulated fetch failure")
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the fetch error")
+	}
+
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the next poll")
+	}
+	require.NoError(t, w.Close())
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Closing should cancel the poll in progress")
+	}
+	assert.Equal(t, int32(4), polls.Load(), "Polling should stop once closed")
+}
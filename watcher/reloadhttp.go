@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// reloadResponse is the JSON body of the responses of the handler of WithHTTPReloadEndpoint.
+type reloadResponse struct {
+	Reloaded bool   `json:"reloaded"`
+	Version  string `json:"version,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// reloadEndpointKey identifies the registration of a reload endpoint.
+type reloadEndpointKey struct {
+	mux  *http.ServeMux
+	path string
+}
+
+var (
+	reloadEndpointsMutex sync.Mutex
+	// reloadEndpoints holds the endpoints registered on muxes, which cannot be unregistered.
+	reloadEndpoints = make(map[reloadEndpointKey]*reloadEndpoint)
+)
+
+// reloadEndpoint is the handler of WithHTTPReloadEndpoint registered on a mux at a path. It reloads the watcher
+// attached to it, so that a watcher restarted with the same mux and path takes the registration over.
+type reloadEndpoint struct {
+	mutex sync.Mutex
+	// reload reloads the attached watcher, nil once it stopped.
+	reload func(ctx context.Context) (uint64, error)
+	// attachment identifies the last attached watcher.
+	attachment uint64
+}
+
+// Attaches a reload to the endpoint of mux at path, registering the endpoint on first use.
+// Returns the function detaching it, which does nothing once another reload is attached.
+func attachReloadEndpoint(mux *http.ServeMux, path string, reload func(ctx context.Context) (uint64, error)) func() {
+	reloadEndpointsMutex.Lock()
+	key := reloadEndpointKey{mux: mux, path: path}
+	endpoint, ok := reloadEndpoints[key]
+	if !ok {
+		endpoint = &reloadEndpoint{}
+		mux.Handle(path, endpoint)
+		reloadEndpoints[key] = endpoint
+	}
+	reloadEndpointsMutex.Unlock()
+
+	endpoint.mutex.Lock()
+	defer endpoint.mutex.Unlock()
+	endpoint.reload = reload
+	endpoint.attachment++
+	attachment := endpoint.attachment
+	return func() {
+		endpoint.mutex.Lock()
+		defer endpoint.mutex.Unlock()
+		if endpoint.attachment == attachment {
+			endpoint.reload = nil
+		}
+	}
+}
+
+// ServeHTTP reloads the attached watcher within the context of the request.
+func (e *reloadEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	e.mutex.Lock()
+	reload := e.reload
+	e.mutex.Unlock()
+
+	var version uint64
+	err := ErrWatcherStopped
+	if reload != nil {
+		version, err = reload(r.Context())
+	}
+
+	status := http.StatusOK
+	response := reloadResponse{Reloaded: true, Version: strconv.FormatUint(version, 10)}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrWatcherStopped) || r.Context().Err() != nil:
+		status = http.StatusServiceUnavailable
+		response = reloadResponse{Error: err.Error()}
+	default:
+		status = http.StatusInternalServerError
+		response = reloadResponse{Error: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
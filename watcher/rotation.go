@@ -0,0 +1,45 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// rotationPollInterval is the delay between checks for a rotated file to reappear.
+	rotationPollInterval = 50 * time.Millisecond
+	// defaultRotationGracePeriod is how long a rotated file may take to reappear, see WithRotationGracePeriod.
+	defaultRotationGracePeriod = 5 * time.Second
+)
+
+// ErrRotationTimeout is reported when a renamed or removed file does not reappear within the rotation grace period.
+var ErrRotationTimeout = errors.New("rotated file did not reappear")
+
+// rotationResult is the outcome of waiting for a rotated file to reappear.
+type rotationResult struct {
+	path string
+	err  error
+}
+
+// Polls for a renamed or removed file to reappear at its path until the grace period elapses.
+// Returns ctx.Err() if the context is done first, or ErrRotationTimeout if the file does not reappear.
+func waitForFile(ctx context.Context, path string, gracePeriod time.Duration) error {
+	deadline := time.Now().Add(gracePeriod)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s within %s", ErrRotationTimeout, path, gracePeriod)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rotationPollInterval):
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestControlFileChanges_RotationRecovery
+// This test simulates a log rotation by renaming the watched file and creating a new file at its path.
+// The watcher must emit an event with the content of the new file and keep watching the new file afterwards.
+func TestControlFileChanges_RotationRecovery(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+	defer os.Remove(tempFile + ".1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithRotationRecovery(), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	w := factory.next(t)
+
+	// The new file is complete before the rename is reported, so that it is found at once
+	require.NoError(t, os.Rename(tempFile, tempFile+".1"), "Failed to rotate file")
+	writeFile(t, tempFile, "rotated")
+	w.events <- fsnotify.Event{Name: tempFile, Op: fsnotify.Rename}
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "initial", event.OldConfig, "Old config should match initial value")
+		assert.Equal(t, "rotated", event.NewConfig, "New config should be read from the new file")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for rotation event")
+	}
+	assert.Equal(t, []string{tempFile}, w.watchedPaths(), "The new file should be watched")
+
+	writeFile(t, tempFile, "updated")
+	w.events <- fsnotify.Event{Name: tempFile, Op: fsnotify.Write}
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "rotated", event.OldConfig, "Old config should match the rotated file")
+		assert.Equal(t, "updated", event.NewConfig, "Changes to the new file should be detected")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for change of the new file")
+	}
+}
+
+// TestControlFileChanges_RotationTimeout
+// This test verifies that a removed file which does not reappear within the grace period is reported as ErrRotationTimeout.
+func TestControlFileChanges_RotationTimeout(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	errs := make(chan error, 10)
+	_, err := ControlFileChanges(ctx, tempFile, func() string {
+		return ""
+	}, WithRotationRecovery(), WithRotationGracePeriod(100*time.Millisecond), WithErrorHandler(func(err error) {
+		errs <- err
+	}))
+	require.NoError(t, err, "Failed to start watcher")
+
+	require.NoError(t, os.Remove(tempFile), "Failed to remove file")
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrRotationTimeout, "Missing file should be reported after the grace period")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for rotation error")
+	}
+}
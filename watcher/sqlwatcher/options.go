@@ -0,0 +1,60 @@
+package sqlwatcher
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// Options holds the settings of a SQL watcher.
+type Options struct {
+	watcher.PollingOptions
+	queryTimeout time.Duration
+	// isolation runs the query in a read-only transaction with this isolation level when set, see WithTxIsolation.
+	isolation *sql.IsolationLevel
+}
+
+func defaultOptions() *Options {
+	return &Options{}
+}
+
+// Option defines a function signature for setting Options.
+type Option func(*Options)
+
+// WithPolling
+// This option sets the interval between two executions of the query.
+// It is required: there is no default interval, as the right one depends on the load the database can take.
+func WithPolling(interval time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = interval
+	}
+}
+
+// WithQueryTimeout
+// This option bounds the time an execution of the query, including scanFn, may take.
+// By default the query runs until the watcher stops.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.queryTimeout = timeout
+	}
+}
+
+// WithTxIsolation
+// This option runs the query in a read-only transaction with the isolation level, e.g. sql.LevelRepeatableRead
+// when the configuration is spread over several tables. By default the query runs outside of a transaction.
+// The level must be supported by the driver, otherwise the polls fail.
+func WithTxIsolation(level sql.IsolationLevel) Option {
+	return func(o *Options) {
+		o.isolation = &level
+	}
+}
+
+// WithWatcherOptions
+// This option passes options to the underlying watcher, e.g. watcher.WithErrorHandler or watcher.WithDebounce.
+// The watcher factory is always replaced by the SQL poller.
+func WithWatcherOptions(opts ...watcher.Option) Option {
+	return func(o *Options) {
+		o.WatcherOptions = append(o.WatcherOptions, opts...)
+	}
+}
@@ -0,0 +1,125 @@
+// Package sqlwatcher watches configurations stored in SQL databases by polling them with a query.
+package sqlwatcher
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+// ErrPollingRequired is returned by ControlSQLChanges when no positive polling interval is set with WithPolling.
+var ErrPollingRequired = errors.New("polling interval required")
+
+// ControlSQLChanges monitors a configuration stored in a database and sends detected updates through the returned handle.
+// The query is executed at the interval of WithPolling, and scanFn is called with its rows to build the configuration.
+// The rows are closed after scanFn returns. Results equal to the previous configuration, as compared by
+// reflect.DeepEqual, are skipped, so that unchanged tables do not send events. The database is owned by the caller,
+// and is not closed when the watcher stops.
+//
+// The configuration is queried once before the function returns, and an error is returned if that fails.
+// Later polling failures are reported to the error handler of the watcher. Change events have the query as source.
+// WatchHandle.Reload emits the last queried configuration without polling.
+func ControlSQLChanges[T any](ctx context.Context, db *sql.DB, query string, scanFn func(*sql.Rows) (T, error), opts ...Option) (*watcher.WatchHandle[T], error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.Interval <= 0 {
+		return nil, ErrPollingRequired
+	}
+	if db == nil {
+		return nil, errors.New("no database to watch")
+	}
+
+	source := &sqlSource[T]{
+		db:      db,
+		query:   query,
+		scanFn:  scanFn,
+		options: options,
+	}
+	if _, err := source.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	return watcher.WatchPolling(ctx, query, source.fetch, source.current, options.PollingOptions)
+}
+
+// sqlSource queries a configuration from a database and keeps the last queried value.
+type sqlSource[T any] struct {
+	db      *sql.DB
+	query   string
+	scanFn  func(*sql.Rows) (T, error)
+	options *Options
+
+	mutex   sync.Mutex
+	value   T
+	fetched bool
+}
+
+// Returns the last queried configuration.
+func (s *sqlSource[T]) current() T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.value
+}
+
+// Executes the query and reports whether it returned a new configuration.
+func (s *sqlSource[T]) fetch(ctx context.Context) (bool, error) {
+	if s.options.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.options.queryTimeout)
+		defer cancel()
+	}
+
+	value, err := s.run(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to query the configuration: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.fetched && reflect.DeepEqual(s.value, value) {
+		return false, nil
+	}
+	s.value, s.fetched = value, true
+	return true, nil
+}
+
+// Executes the query, in a read-only transaction with WithTxIsolation, and scans its rows.
+func (s *sqlSource[T]) run(ctx context.Context) (T, error) {
+	var zero T
+	if s.options.isolation == nil {
+		rows, err := s.db.QueryContext(ctx, s.query)
+		if err != nil {
+			return zero, err
+		}
+		return s.scan(rows)
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: *s.options.isolation, ReadOnly: true})
+	if err != nil {
+		return zero, err
+	}
+	// The transaction only reads, so it is rolled back rather than committed
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, s.query)
+	if err != nil {
+		return zero, err
+	}
+	return s.scan(rows)
+}
+
+// Scans the rows of the query with scanFn and closes them.
+func (s *sqlSource[T]) scan(rows *sql.Rows) (T, error) {
+	defer rows.Close()
+	value, err := s.scanFn(rows)
+	if err != nil {
+		return value, err
+	}
+	return value, rows.Err()
+}
@@ -0,0 +1,118 @@
+package sqlwatcher
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/vsysa/kongkit/watcher"
+)
+
+const settingsQuery = "SELECT key, value FROM settings ORDER BY key"
+
+// Opens an in-memory SQLite database with a settings table.
+func openSettingsDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err, "Failed to open database")
+	t.Cleanup(func() { _ = db.Close() })
+	// Every connection to :memory: opens a new database
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec("CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT)")
+	require.NoError(t, err, "Failed to create table")
+	_, err = db.Exec("INSERT INTO settings (key, value) VALUES ('host', 'localhost'), ('port', '8080')")
+	require.NoError(t, err, "Failed to insert settings")
+	return db
+}
+
+// Scans the settings into a map, counting the polls.
+func scanSettings(polls *atomic.Int32) func(rows *sql.Rows) (map[string]string, error) {
+	return func(rows *sql.Rows) (map[string]string, error) {
+		polls.Add(1)
+		settings := make(map[string]string)
+		for rows.Next() {
+			var key, value string
+			if err := rows.Scan(&key, &value); err != nil {
+				return nil, err
+			}
+			settings[key] = value
+		}
+		return settings, nil
+	}
+}
+
+// TestControlSQLChanges
+// This test verifies that polling an unchanged table sends no event,
+// and that an event is sent when the queried configuration changes.
+func TestControlSQLChanges(t *testing.T) {
+	db := openSettingsDB(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var polls atomic.Int32
+	handle, err := ControlSQLChanges(ctx, db, settingsQuery, scanSettings(&polls),
+		WithPolling(20*time.Millisecond), WithQueryTimeout(time.Second), WithTxIsolation(sql.LevelSerializable))
+	require.NoError(t, err, "Failed to start SQL watcher")
+
+	require.Eventually(t, func() bool {
+		return polls.Load() >= 3
+	}, time.Second, 10*time.Millisecond, "The table should be polled")
+	select {
+	case event := <-handle.Events():
+		t.Fatalf("Unexpected event for an unchanged table: %+v", event)
+	default:
+	}
+
+	_, err = db.Exec("UPDATE settings SET value = '9090' WHERE key = 'port'")
+	require.NoError(t, err, "Failed to update settings")
+
+	select {
+	case event := <-handle.Events():
+		assert.Equal(t, map[string]string{"host": "localhost", "port": "8080"}, event.OldConfig, "Old config should be the initial query")
+		assert.Equal(t, map[string]string{"host": "localhost", "port": "9090"}, event.NewConfig, "New config should be the updated query")
+		assert.Equal(t, settingsQuery, event.Source, "Source should be the query")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the change event")
+	}
+}
+
+// TestControlSQLChanges_Errors
+// This test verifies that the polling interval is required, that a failing initial query is returned
+// and that polling failures are reported to the error handler.
+func TestControlSQLChanges_Errors(t *testing.T) {
+	db := openSettingsDB(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var polls atomic.Int32
+	_, err := ControlSQLChanges(ctx, db, settingsQuery, scanSettings(&polls))
+	assert.ErrorIs(t, err, ErrPollingRequired, "The polling interval should be required")
+
+	_, err = ControlSQLChanges(ctx, db, "SELECT key, value FROM missing", scanSettings(&polls), WithPolling(20*time.Millisecond))
+	assert.ErrorContains(t, err, "no such table", "Initial query failure should be returned")
+
+	errs := make(chan error, 10)
+	_, err = ControlSQLChanges(ctx, db, settingsQuery, scanSettings(&polls), WithPolling(20*time.Millisecond),
+		WithWatcherOptions(watcher.WithErrorHandler(func(err error) {
+			errs <- err
+		})))
+	require.NoError(t, err, "Failed to start SQL watcher")
+
+	_, err = db.Exec("DROP TABLE settings")
+	require.NoError(t, err, "Failed to drop table")
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "no such table", "Polling failure should be reported")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the polling error")
+	}
+}
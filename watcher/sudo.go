@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrSudoUnavailable is returned by the reads of WithSudoRead and SudoReadFile when the sudo binary is not found.
+// It does not wrap the error of the lookup, so that a missing binary is not mistaken for a missing file.
+var ErrSudoUnavailable = errors.New("sudo is not available")
+
+// SudoOption defines a function signature for setting the options of WithSudoRead and SudoReadFile.
+type SudoOption func(*sudoOptions)
+
+type sudoOptions struct {
+	sudoPath string
+}
+
+// WithSudoPath
+// This option sets the path of the sudo binary used by WithSudoRead and SudoReadFile, for systems where it is not in PATH
+// or where a specific binary must be used. By default "sudo" is looked up in PATH.
+func WithSudoPath(p string) SudoOption {
+	return func(o *sudoOptions) {
+		o.sudoPath = p
+	}
+}
+
+// WithSudoRead
+// This option reads the watched files with `sudo -n cat` instead of os.ReadFile, for configuration files owned by
+// root that cannot be read by the process user, where os.ReadFile fails with EACCES. It replaces the reads of the
+// watcher: the content passed to getCurrentConfigFnFromBytes by ControlFileBytesChanges and the checksum of
+// WithCRC32Check. With ControlFileChanges, getCurrentConfigFn reads the file itself and can use SudoReadFile.
+//
+// Security implications: the files are read with root privileges, so the caller must make sure that the watched
+// paths cannot be controlled by untrusted input, and sudoers should only allow `cat` on the configuration files.
+// sudo runs non-interactively (-n) and fails instead of prompting for a password. Each read starts a sudo process,
+// which is usually logged by the system, so it is best combined with WithDebounce or WithTokenBucket.
+// The reads are bound to the context of the watcher, and fail once it is done or if the sudo binary is not
+// available.
+func WithSudoRead(opts ...SudoOption) Option {
+	return func(o *Options) {
+		o.readFile = func(ctx context.Context, path string) ([]byte, error) {
+			return SudoReadFile(ctx, path, opts...)
+		}
+	}
+}
+
+// SudoReadFile reads a file with `sudo -n cat`, as WithSudoRead does, for getCurrentConfigFn callbacks
+// reading root-owned files themselves. See WithSudoRead for the security implications.
+// The read fails if the context is done or the sudo binary is not available.
+func SudoReadFile(ctx context.Context, path string, opts ...SudoOption) ([]byte, error) {
+	options := &sudoOptions{sudoPath: "sudo"}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s with sudo: %w", path, err)
+	}
+	sudoPath, err := exec.LookPath(options.sudoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s with sudo: %w: %v", path, ErrSudoUnavailable, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, sudoPath, "-n", "cat", "--", path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("failed to read %s with sudo: %w: %s", path, err, message)
+		}
+		return nil, fmt.Errorf("failed to read %s with sudo: %w", path, err)
+	}
+	return stdout.Bytes(), nil
+}
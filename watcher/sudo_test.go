@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Creates a fake sudo binary that drops the -n flag and runs the command as the current user.
+func createFakeSudo(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sudo")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nshift\nexec \"$@\"\n"), 0755), "Failed to create fake sudo")
+	return path
+}
+
+// Creates a fake sudo binary like createFakeSudo, which also appends the read path to a log file.
+func createLoggingFakeSudo(t *testing.T) (sudo, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	sudo, log = filepath.Join(dir, "sudo"), filepath.Join(dir, "sudo.log")
+	script := "#!/bin/sh\necho \"$4\" >> " + log + "\nshift\nexec \"$@\"\n"
+	require.NoError(t, os.WriteFile(sudo, []byte(script), 0755), "Failed to create fake sudo")
+	return sudo, log
+}
+
+// TestWithSudoRead
+// This test verifies that WithSudoRead reads the file with sudo for the checksum and the content passed to
+// getCurrentConfigFnFromBytes, and that failing reads are reported to the error handler.
+func TestWithSudoRead(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	sudo, log := createLoggingFakeSudo(t)
+	updates, err := ControlFileBytesChanges(ctx, tempFile, func(data []byte) string {
+		return string(data)
+	}, WithDebounce(50*time.Millisecond), WithCRC32Check(), WithSudoRead(WithSudoPath(sudo)))
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, tempFile, "updated")
+	select {
+	case event := <-updates:
+		assert.Equal(t, "initial", event.OldConfig, "Old config should be the initial content")
+		assert.Equal(t, "updated", event.NewConfig, "New config should be the updated content")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the change event")
+	}
+	logged, err := os.ReadFile(log)
+	require.NoError(t, err, "The file should be read with sudo")
+	// The initial configuration, the initial checksum and the single read of the change
+	assert.Equal(t, strings.Repeat(tempFile+"\n", 3), string(logged), "Every read should go through sudo")
+
+	errs := make(chan error, 10)
+	_, err = ControlFileBytesChanges(ctx, tempFile, func(data []byte) string {
+		return string(data)
+	}, WithSudoRead(WithSudoPath(filepath.Join(t.TempDir(), "missing"))), WithErrorHandler(func(err error) {
+		errs <- err
+	}))
+	require.NoError(t, err, "Failed to start watcher")
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrSudoUnavailable, "The failing read should be reported")
+	default:
+		t.Fatal("The failing initial read should be reported")
+	}
+}
+
+// TestSudoReadFile
+// This test verifies that SudoReadFile returns the file contents printed by the sudo command.
+func TestSudoReadFile(t *testing.T) {
+	tempFile := createTempFile(t, "port: 8080")
+	defer os.Remove(tempFile)
+
+	data, err := SudoReadFile(context.Background(), tempFile, WithSudoPath(createFakeSudo(t)))
+	require.NoError(t, err)
+	assert.Equal(t, "port: 8080", string(data))
+}
+
+// TestSudoReadFile_Errors
+// This test verifies that SudoReadFile fails when the context is done, the sudo binary is missing or the read fails.
+func TestSudoReadFile_Errors(t *testing.T) {
+	sudo := createFakeSudo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := SudoReadFile(ctx, "config.yaml", WithSudoPath(sudo))
+	assert.ErrorIs(t, err, context.Canceled, "Read should fail with a done context")
+
+	_, err = SudoReadFile(context.Background(), "config.yaml", WithSudoPath(filepath.Join(t.TempDir(), "missing")))
+	assert.ErrorIs(t, err, ErrSudoUnavailable, "Read should fail without a sudo binary")
+
+	_, err = SudoReadFile(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), WithSudoPath(sudo))
+	assert.ErrorContains(t, err, "No such file", "Read should report the error of the command")
+}
@@ -0,0 +1,112 @@
+// Package testutil provides helpers to test code using the configuration watcher.
+package testutil
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock whose time only moves with Advance, to make tests of the debounce of a watcher fast and
+// deterministic. It implements watcher.Clock and is passed to the watcher with watcher.WithClock.
+//
+// All methods are safe for concurrent use.
+type FakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*Timer
+	// sequence orders the timers with the same deadline by creation.
+	sequence int
+}
+
+// NewFakeClock creates a fake clock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Timer is a timer of a FakeClock started by AfterFunc.
+type Timer struct {
+	clock    *FakeClock
+	deadline time.Time
+	sequence int
+	f        func()
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// AfterFunc starts a timer calling f once the clock has been advanced by d.
+// Timers with a non-positive duration fire on the next call to Advance.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) interface{ Stop() bool } {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.sequence++
+	timer := &Timer{clock: c, deadline: c.now.Add(d), sequence: c.sequence, f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Stop prevents the timer from firing. It reports whether the timer was stopped before it fired.
+func (t *Timer) Stop() bool {
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d and fires all timers whose deadline has passed, in the order of their
+// deadlines. Every timer fires synchronously in the goroutine calling Advance, with the clock set to its deadline,
+// so timers started by a firing timer also fire if their deadline is within d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	end := c.now.Add(d)
+	for {
+		timer := c.nextTimer(end)
+		if timer == nil {
+			break
+		}
+		if timer.deadline.After(c.now) {
+			c.now = timer.deadline
+		}
+		c.mutex.Unlock()
+		timer.f()
+		c.mutex.Lock()
+	}
+	c.now = end
+	c.mutex.Unlock()
+}
+
+// PendingTimers returns the number of timers that have neither fired nor been stopped, e.g. to wait until the
+// code under test has started a timer before advancing the clock.
+func (c *FakeClock) PendingTimers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+// Removes and returns the earliest timer whose deadline is not after end, or nil if there is none.
+// Must be called with the mutex held.
+func (c *FakeClock) nextTimer(end time.Time) *Timer {
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		if c.timers[i].deadline.Equal(c.timers[j].deadline) {
+			return c.timers[i].sequence < c.timers[j].sequence
+		}
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	if len(c.timers) == 0 || c.timers[0].deadline.After(end) {
+		return nil
+	}
+	timer := c.timers[0]
+	c.timers = c.timers[1:]
+	return timer
+}
@@ -0,0 +1,34 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFakeClock_Advance
+// This test verifies that Advance fires the timers whose deadline has passed in the order of their deadlines,
+// with the clock set to each deadline, including timers started by a firing timer, and skips stopped timers.
+func TestFakeClock_Advance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	var fired []time.Duration
+	record := func() { fired = append(fired, clock.Now().Sub(start)) }
+	clock.AfterFunc(30*time.Millisecond, record)
+	clock.AfterFunc(10*time.Millisecond, func() {
+		record()
+		clock.AfterFunc(10*time.Millisecond, record)
+	})
+	stopped := clock.AfterFunc(15*time.Millisecond, record)
+	clock.AfterFunc(time.Second, record)
+
+	assert.True(t, stopped.Stop(), "Stop should stop a pending timer")
+	assert.False(t, stopped.Stop(), "Stop should report a timer that is already stopped")
+
+	clock.Advance(50 * time.Millisecond)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}, fired)
+	assert.Equal(t, start.Add(50*time.Millisecond), clock.Now(), "Clock should be advanced by the duration")
+	assert.Equal(t, 1, clock.PendingTimers(), "Later timers should still be pending")
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DebounceTracer receives the timing of the debounce cycles of a watcher, see WithDebounceTracing.
+// A cycle starts with the first file event for a path and ends when the debounce strategy fires for it,
+// which helps diagnosing why expected change events do not arrive, e.g. a debounce extended by a stream
+// of events or an event suppressed by WithFieldChangeFilter or WithCRC32Check.
+// The methods are called from the goroutines of the watcher and must not block.
+type DebounceTracer interface {
+	// OnDebounceStart is called when the first event of a cycle is received; eventCount is the number of
+	// events in the cycle so far.
+	OnDebounceStart(path string, eventCount int)
+	// OnDebounceEnd is called when the debounce strategy fired and the cycle was processed, with the time
+	// since the first event, the number of events aggregated and whether a change event was emitted.
+	OnDebounceEnd(path string, duration time.Duration, eventCount int, emitted bool)
+}
+
+// StdoutDebounceTracer is a DebounceTracer printing every debounce cycle to standard output.
+type StdoutDebounceTracer struct{}
+
+func (StdoutDebounceTracer) OnDebounceStart(path string, eventCount int) {
+	fmt.Fprintf(os.Stdout, "debounce start: path=%s events=%d\n", path, eventCount)
+}
+
+func (StdoutDebounceTracer) OnDebounceEnd(path string, duration time.Duration, eventCount int, emitted bool) {
+	fmt.Fprintf(os.Stdout, "debounce end: path=%s duration=%s events=%d emitted=%t\n", path, duration, eventCount, emitted)
+}
+
+// NopDebounceTracer is a DebounceTracer that ignores all debounce cycles.
+type NopDebounceTracer struct{}
+
+func (NopDebounceTracer) OnDebounceStart(path string, eventCount int) {}
+
+func (NopDebounceTracer) OnDebounceEnd(path string, duration time.Duration, eventCount int, emitted bool) {
+}
+
+// debounceCycle is a debounce cycle in progress.
+type debounceCycle struct {
+	start  time.Time
+	events int
+}
+
+// debounceCycles tracks the debounce cycles of a watcher by debounce key and reports them to a tracer.
+type debounceCycles struct {
+	tracer DebounceTracer
+	// clock measures the duration of the cycles, see WithClock.
+	clock Clock
+
+	mutex  sync.Mutex
+	cycles map[string]*debounceCycle
+}
+
+func newDebounceCycles(tracer DebounceTracer, clock Clock) *debounceCycles {
+	return &debounceCycles{tracer: tracer, clock: clock, cycles: make(map[string]*debounceCycle)}
+}
+
+// Records an event of a debounce key, starting a new cycle if none is in progress.
+func (c *debounceCycles) event(key, path string) {
+	c.mutex.Lock()
+	cycle, ok := c.cycles[key]
+	if !ok {
+		cycle = &debounceCycle{start: c.clock.Now()}
+		c.cycles[key] = cycle
+	}
+	cycle.events++
+	events := cycle.events
+	c.mutex.Unlock()
+
+	if !ok {
+		c.tracer.OnDebounceStart(path, events)
+	}
+}
+
+// Takes the cycle in progress of a debounce key, so that later events start a new cycle.
+// The returned function ends the cycle once it has been processed.
+func (c *debounceCycles) fire(key, path string) func(emitted bool) {
+	c.mutex.Lock()
+	cycle, ok := c.cycles[key]
+	delete(c.cycles, key)
+	c.mutex.Unlock()
+
+	if !ok {
+		return func(bool) {}
+	}
+	return func(emitted bool) {
+		c.tracer.OnDebounceEnd(path, c.clock.Now().Sub(cycle.start), cycle.events, emitted)
+	}
+}
@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/watcher/testutil"
+)
+
+// tracedCycle is a debounce cycle reported to recordingTracer.
+type tracedCycle struct {
+	path     string
+	duration time.Duration
+	events   int
+	emitted  bool
+}
+
+// recordingTracer is a DebounceTracer recording the cycles for the test.
+type recordingTracer struct {
+	mutex  sync.Mutex
+	starts []string
+	ends   chan tracedCycle
+}
+
+func (r *recordingTracer) OnDebounceStart(path string, eventCount int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.starts = append(r.starts, path)
+}
+
+func (r *recordingTracer) OnDebounceEnd(path string, duration time.Duration, eventCount int, emitted bool) {
+	r.ends <- tracedCycle{path: path, duration: duration, events: eventCount, emitted: emitted}
+}
+
+// TestControlFileChanges_DebounceTracing
+// This test verifies that WithDebounceTracing reports a cycle per burst of events, with its duration on the clock
+// of the watcher, the number of aggregated events and whether a change event was emitted, also when the change
+// is suppressed by a filter.
+func TestControlFileChanges_DebounceTracing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	clock := &countingClock{FakeClock: testutil.NewFakeClock(time.Now())}
+	factory := newFakeWatcherFactory()
+	tracer := &recordingTracer{ends: make(chan tracedCycle, 10)}
+	readCounter := 0
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(100*time.Millisecond), WithDebounceTracing(tracer), WithFieldChangeFilter(func(oldCfg, newCfg int) bool {
+		// Only even configurations are interesting
+		return newCfg%2 == 0
+	}), WithClock(clock), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	// Sends a file event and waits for it to restart the debounce
+	watcher := factory.next(t)
+	change := func() {
+		started := clock.started()
+		watcher.events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+		require.Eventually(t, func() bool { return clock.started() == started+1 }, time.Second, time.Millisecond,
+			"The event should restart the debounce")
+	}
+
+	for i := 0; i < 3; i++ {
+		change()
+		clock.Advance(25 * time.Millisecond)
+	}
+	// The cycle lasts from the first event until the debounce fires, 100ms after the last one
+	clock.Advance(75 * time.Millisecond)
+	<-updates
+
+	select {
+	case cycle := <-tracer.ends:
+		assert.Equal(t, tracedCycle{path: "config.yaml", duration: 150 * time.Millisecond, events: 3, emitted: true}, cycle, "Burst should be reported as one emitted cycle")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the end of the first cycle")
+	}
+
+	change()
+	clock.Advance(100 * time.Millisecond)
+	select {
+	case cycle := <-tracer.ends:
+		assert.Equal(t, tracedCycle{path: "config.yaml", duration: 100 * time.Millisecond, events: 1, emitted: false}, cycle, "Filtered change should be reported as not emitted")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the end of the second cycle")
+	}
+
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	assert.Equal(t, []string{"config.yaml", "config.yaml"}, tracer.starts, "Every cycle should be started once")
+}
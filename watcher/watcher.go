@@ -3,17 +3,25 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"os"
+	"reflect"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
-// ChangeEvent represents the old and new configuration states.
-type ChangeEvent[T any] struct {
-	OldConfig T
-	NewConfig T
-}
+const (
+	// recoverInitialBackoff is the delay before the second attempt to recreate a dead file watcher.
+	recoverInitialBackoff = 100 * time.Millisecond
+	// recoverMaxBackoff caps the delay between attempts to recreate a dead file watcher.
+	recoverMaxBackoff = 30 * time.Second
+	// fanOutBufferSize is the capacity of the channels of WatchHandle.EventsFor, see WithFanOut.
+	fanOutBufferSize = 16
+	// shutdownEventTimeout bounds the wait for the consumer to receive the shutdown event, see WithShutdownEvent.
+	shutdownEventTimeout = time.Second
+)
 
 // ControlFileChanges monitors changes to a specified file and sends detected updates through a channel.
 // It supports debounce behavior, context-based graceful shutdown, and customizable error handling and logging.
@@ -30,79 +38,490 @@ type ChangeEvent[T any] struct {
 //
 // The function ensures safe concurrent access, supports panic recovery within the configuration reader,
 // and avoids excessive notifications using debounce logic.
+//
+// Events carry the configurations by value. For large configuration structs, use a pointer type for T
+// (e.g. func() *Config) so that only pointers are copied. getCurrentConfigFn must then return a newly
+// allocated configuration on every call and the configurations must not be mutated after they are returned:
+// the watcher keeps the last emitted configuration as OldConfig of the next event, so reusing or mutating
+// a single instance would make OldConfig and NewConfig point to the same value.
 func ControlFileChanges[T any](ctx context.Context, pathToFile string, getCurrentConfigFn func() T, opts ...Option) (<-chan ChangeEvent[T], error) {
+	return ControlFilesChanges(ctx, []string{pathToFile}, getCurrentConfigFn, opts...)
+}
+
+// ControlFilesChanges monitors changes to several files that together make up a configuration
+// and sends detected updates through a channel.
+//
+// By default every file is debounced separately, so changes to different files within the debounce
+// window produce separate events. Use WithBatchAcrossFiles to coalesce them into a single event.
+// See ControlFileChanges for the description of the remaining parameters and return values.
+func ControlFilesChanges[T any](ctx context.Context, paths []string, getCurrentConfigFn func() T, opts ...Option) (<-chan ChangeEvent[T], error) {
+	handle, err := WatchFiles(ctx, paths, getCurrentConfigFn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return handle.Events(), nil
+}
+
+// Watch monitors changes to a specified file like ControlFileChanges, but returns a handle
+// that also allows triggering reloads programmatically.
+func Watch[T any](ctx context.Context, pathToFile string, getCurrentConfigFn func() T, opts ...Option) (*WatchHandle[T], error) {
+	return WatchFiles(ctx, []string{pathToFile}, getCurrentConfigFn, opts...)
+}
+
+// WatchFiles monitors changes to several files like ControlFilesChanges, but returns a handle
+// that also allows triggering reloads programmatically.
+func WatchFiles[T any](ctx context.Context, paths []string, getCurrentConfigFn func() T, opts ...Option) (*WatchHandle[T], error) {
 	updates := make(chan ChangeEvent[T])
-	var mutex sync.Mutex
-	var debounceTimer *time.Timer
+	// done is closed when the watcher starts shutting down, to release pending sends
+	done := make(chan struct{})
+	// finished is closed when the watcher has shut down, after its goroutines have exited
+	finished := make(chan struct{})
+	// stopCtx is cancelled with done, to interrupt the waits of reloads that are bound by another context
+	stopCtx, stopWaits := context.WithCancel(ctx)
+	var goroutines sync.WaitGroup
+	// fires tracks the fires of the debounce strategy in progress, which may run on goroutines of the strategy
+	var fires fireTracker
+	// emitLock serializes the emissions and guards their state. It is a channel rather than a mutex,
+	// so that reloads can give up waiting for it, see WithHTTPReloadEndpoint.
+	emitLock := make(chan struct{}, 1)
+	stopped := false
 
 	options := defaultWatcherOptions()
 	for _, opt := range opts {
 		opt(options)
 	}
+	if options.invalid != nil {
+		stopWaits()
+		return nil, options.invalid
+	}
+
+	if options.newObserver != nil {
+		var err error
+		if options.observer, err = options.newObserver(); err != nil {
+			stopWaits()
+			return nil, fmt.Errorf("failed to create the observer: %w", err)
+		}
+	}
+	observer := options.observer
+	if observer != nil {
+		handler := options.errorHandler
+		options.errorHandler = func(err error) {
+			observer.OnError(errorPath(err), err)
+			handler(err)
+		}
+	}
+
+	debounce := options.debounceStrategy
+	if debounce == nil && options.immediateFirstChange {
+		debounce = ImmediateFirstDebounce(options.debounceDuration)
+	} else if debounce == nil {
+		debounce = TrailingDebounce(options.debounceDuration)
+	}
+	if setter, ok := debounce.(clockSetter); ok {
+		setter.setClock(options.clock)
+	}
+
+	// fanOut receives copies of the events for the consumers of WatchHandle.EventsFor, see WithFanOut
+	fanOut := make([]chan ChangeEvent[T], max(options.fanOut, 1)-1)
+	for i := range fanOut {
+		fanOut[i] = make(chan ChangeEvent[T], fanOutBufferSize)
+	}
+	// Sends a copy of an event to every fan-out channel, dropping it for the consumers that are not keeping up
+	sendFanOut := func(event ChangeEvent[T]) {
+		for _, channel := range fanOut {
+			select {
+			case channel <- event:
+			default:
+			}
+		}
+	}
+
+	// ack receives the acknowledgements of the consumer, see WithAckChannel
+	var ack chan struct{}
+	if options.ackChannel {
+		ack = make(chan struct{})
+	}
 
 	// Initialize the configuration with the current state of the file.
 	oldConfig := getCurrentConfigFn()
+	if options.changeFilter != nil {
+		// The filter is checked against the initial configuration, so that a filter of another type fails the
+		// setup instead of silently letting every change through
+		if _, err := options.changeFilter(oldConfig, oldConfig); err != nil {
+			stopWaits()
+			if observer != nil {
+				observer.Close()
+			}
+			return nil, err
+		}
+	}
 
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	// checksums holds the checksum of the last successful read of each file, see WithCRC32Check
+	checksums := make(map[string]uint32)
+	if options.crc32Check {
+		for _, pathToFile := range paths {
+			if _, sum, err := readChecksum(ctx, options.readFile, pathToFile); err == nil {
+				checksums[pathToFile] = sum
+			}
+		}
+	}
+
+	// Files that do not exist yet are awaited by watching their parent directories, see WithWatchParentDir
+	awaited := newAwaitedFiles(nil)
+	if options.watchParentDir && options.pathFilter == nil {
+		awaited = newAwaitedFiles(paths)
 	}
 
-	err = watcher.Add(pathToFile)
+	watcher, err := openFileWatcher(options.watcherFactory, awaited.watchPaths(paths))
 	if err != nil {
-		return nil, fmt.Errorf("failed to watch file %s: %w", pathToFile, err)
+		stopWaits()
+		if observer != nil {
+			observer.Close()
+		}
+		return nil, err
+	}
+	// File events are ignored until the startup grace period is over
+	graceEnd := options.clock.Now().Add(options.startupGrace)
+
+	// readLock serializes the calls to getCurrentConfigFn, as the stabilizing reads of WithIdempotentRead
+	// are made without holding emitLock
+	var readLock sync.Mutex
+	readConfig := func() T {
+		readLock.Lock()
+		defer readLock.Unlock()
+		return getCurrentConfigFn()
+	}
+	// Waits for a duration on the clock of the watcher. Reports false if ctx is done first.
+	sleep := func(ctx context.Context, d time.Duration) bool {
+		wake := make(chan struct{})
+		timer := options.clock.AfterFunc(d, func() { close(wake) })
+		defer timer.Stop()
+		select {
+		case <-wake:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	// Reads the configuration repeatedly, interval apart, until two consecutive reads are equal with
+	// WithIdempotentRead. Configurations are compared with reflect.DeepEqual: the values behind pointers are
+	// compared rather than the pointers, and functions are never equal. Reports false if ctx is done first.
+	stabilizeConfig := func(ctx context.Context, source string) (T, bool) {
+		config := readConfig()
+		for attempt := 1; attempt < options.idempotentReadAttempts; attempt++ {
+			if !sleep(ctx, options.idempotentReadInterval) {
+				return config, false
+			}
+			next := readConfig()
+			if reflect.DeepEqual(config, next) {
+				return next, true
+			}
+			config = next
+		}
+		options.logger.Printf("Configuration of %q did not stabilize after %d reads, using the last one", source, options.idempotentReadAttempts)
+		return config, true
+	}
+
+	// version counts the change events sent, see WithHTTPReloadEndpoint
+	var version uint64
+	// lastSent and lastSentConfig are the time and configuration of the last change event sent, see WithDedupWindow
+	var lastSent time.Time
+	var lastSentConfig T
+	// filterMismatchReported is set once a configuration not matching WithFieldChangeFilter was reported
+	filterMismatchReported := false
+
+	// Reads the configuration and sends a change event for it. All reloads go through this pipeline,
+	// whether triggered by a file event or by WatchHandle.Reload. The waits of the pipeline end when the watcher
+	// stops or when callerCtx is done, e.g. with the request of WithHTTPReloadEndpoint.
+	// Returns the version of the sent event, or 0 if no event was sent.
+	emit := func(callerCtx context.Context, source, operation string) (sentVersion uint64, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := debug.Stack()
+				options.handlePanic(r, stack, source)
+				err = PanicError{Source: source, Value: r, Stack: stack}
+			}
+		}()
+
+		waitCtx, cancelWaits := context.WithCancel(callerCtx)
+		defer cancelWaits()
+		defer context.AfterFunc(stopCtx, cancelWaits)()
+		// Returns the error of an interrupted wait
+		interrupted := func() error {
+			if stopCtx.Err() != nil {
+				return ErrWatcherStopped
+			}
+			return callerCtx.Err()
+		}
+
+		if options.limiter != nil {
+			if err := options.limiter.Wait(waitCtx); err != nil {
+				if stopCtx.Err() != nil {
+					return 0, ErrWatcherStopped
+				}
+				return 0, fmt.Errorf("failed to wait for the token bucket: %w", err)
+			}
+		}
+
+		// The stabilizing reads wait between reads, so they are made before taking the lock,
+		// and the emission uses their result instead of reading again
+		readStart := options.clock.Now()
+		var stableConfig T
+		stabilized := options.idempotentReadAttempts > 1
+		if stabilized {
+			var ok bool
+			if stableConfig, ok = stabilizeConfig(waitCtx, source); !ok {
+				return 0, interrupted()
+			}
+		}
+
+		select {
+		case emitLock <- struct{}{}:
+		case <-waitCtx.Done():
+			return 0, interrupted()
+		}
+		defer func() { <-emitLock }()
+		if stopped {
+			return 0, ErrWatcherStopped
+		}
+
+		var sum uint32
+		checked := false
+		if options.crc32Check && operation != ReloadOperation {
+			if data, dataSum, readErr := readChecksum(ctx, options.readFile, source); readErr == nil {
+				if last, ok := checksums[source]; ok && last == dataSum {
+					if observer != nil {
+						observer.OnEventSuppressed(source)
+					}
+					return 0, nil
+				}
+				sum, checked = dataSum, true
+				if options.onContent != nil && !stabilized {
+					options.onContent(data)
+				}
+			}
+		}
+
+		newConfig := stableConfig
+		if !stabilized {
+			newConfig = readConfig()
+		}
+		if observer != nil {
+			observer.OnConfigRead(source, options.clock.Now().Sub(readStart))
+		}
+		if checked {
+			// Only remember the checksum once the configuration was read without panicking
+			checksums[source] = sum
+		}
+		if options.changeFilter != nil && operation != ReloadOperation {
+			interesting, filterErr := options.changeFilter(oldConfig, newConfig)
+			if filterErr != nil && !filterMismatchReported {
+				filterMismatchReported = true
+				options.errorHandler(filterErr)
+			}
+			if !interesting {
+				// The change is not interesting, but the next one must be compared against the new configuration
+				oldConfig = newConfig
+				if observer != nil {
+					observer.OnEventSuppressed(source)
+				}
+				return 0, nil
+			}
+		}
+		if options.dedupWindow > 0 && operation != ReloadOperation && version > 0 &&
+			options.clock.Now().Sub(lastSent) < options.dedupWindow && reflect.DeepEqual(lastSentConfig, newConfig) {
+			if observer != nil {
+				observer.OnEventSuppressed(source)
+			}
+			return 0, nil
+		}
+		changeEvent := ChangeEvent[T]{
+			OldConfig: oldConfig,
+			NewConfig: newConfig,
+			Timestamp: options.clock.Now(),
+			Source:    source,
+			Operation: operation,
+		}
+		if options.fileStat && source != "" {
+			if info, err := os.Stat(source); err == nil {
+				changeEvent.FileModTime = info.ModTime()
+			}
+		}
+		// Do not emit events once the watcher is stopping, even if the consumer is still receiving
+		if waitCtx.Err() != nil {
+			return 0, interrupted()
+		}
+		select {
+		case <-waitCtx.Done():
+			return 0, interrupted()
+		case updates <- changeEvent:
+			oldConfig = newConfig
+			version++
+			lastSent, lastSentConfig = changeEvent.Timestamp, newConfig
+			sendFanOut(changeEvent)
+			if options.logger != nil {
+				if operation == ReloadOperation {
+					options.logger.Printf("Configuration reloaded")
+				} else {
+					options.logger.Printf("File changed: %s", source)
+				}
+			}
+		}
+
+		// Hold the next event back until the consumer has applied this one
+		if ack != nil {
+			select {
+			case <-waitCtx.Done():
+			case <-ack:
+			}
+		}
+		return version, nil
+	}
+
+	// The endpoint of WithHTTPReloadEndpoint reloads this watcher until it stops
+	detachEndpoint := func() {}
+	if options.reloadMux != nil {
+		detachEndpoint = attachReloadEndpoint(options.reloadMux, options.reloadPath, func(ctx context.Context) (uint64, error) {
+			return emit(ctx, "", ReloadOperation)
+		})
 	}
 
 	go func() {
-		defer close(updates)
 		defer func() {
-			if debounceTimer != nil {
-				debounceTimer.Stop()
+			goroutines.Wait()
+			if observer != nil {
+				observer.Close()
 			}
-			mutex.Lock()
-			defer mutex.Unlock()
+			close(finished)
+		}()
+		defer func() {
+			close(done)
+			stopWaits()
+			detachEndpoint()
+			debounce.Stop()
+			fires.stop()
+			// No event is sent once stopped is set, so the channels can be closed without holding the lock
+			emitLock <- struct{}{}
+			stopped = true
+			lastConfig := oldConfig
+			<-emitLock
 			watcher.Close()
+			if options.shutdownEvent && ctx.Err() != nil {
+				shutdownEvent := ChangeEvent[T]{
+					OldConfig:  lastConfig,
+					NewConfig:  lastConfig,
+					Timestamp:  options.clock.Now(),
+					IsShutdown: true,
+				}
+				// The consumer may have stopped receiving with the cancellation of the context
+				select {
+				case updates <- shutdownEvent:
+				case <-time.After(shutdownEventTimeout):
+				}
+				sendFanOut(shutdownEvent)
+			}
+			close(updates)
+			for _, channel := range fanOut {
+				close(channel)
+			}
 		}()
 
-		eventChannel := make(chan fsnotify.Event, 1)
+		bufferSize := options.internalBufferSize
+		if bufferSize == 0 {
+			bufferSize = len(paths)
+		}
+		eventChannel := make(chan fsnotify.Event, bufferSize)
 		defer close(eventChannel)
 
+		// cycles reports the debounce cycles to the tracer, see WithDebounceTracing
+		var cycles *debounceCycles
+		if options.debounceTracer != nil {
+			cycles = newDebounceCycles(options.debounceTracer, options.clock)
+		}
+
 		// Goroutine for processing aggregated events with debounce logic
-		// This ensures that rapid consecutive file changes trigger only one update after the debounce duration.
+		// The debounce strategy decides when consecutive file changes trigger an update;
+		// by default only one update is triggered after the debounce duration.
+		goroutines.Add(1)
 		go func() {
+			defer goroutines.Done()
 			for {
 				select {
 				case <-ctx.Done():
 					return
-				case event := <-eventChannel:
-					if debounceTimer != nil {
-						debounceTimer.Stop()
+				case event, ok := <-eventChannel:
+					if !ok {
+						return
 					}
-					debounceTimer = time.AfterFunc(options.debounceDuration, func() {
-						defer func() {
-							if r := recover(); r != nil {
-								options.errorHandler(fmt.Errorf("panic in getCurrentConfigFn: %v", r))
-							}
-						}()
-						mutex.Lock()
-						defer mutex.Unlock()
 
-						newConfig := getCurrentConfigFn()
-						select {
-						case <-ctx.Done():
-							return
-						default:
-							updates <- ChangeEvent[T]{OldConfig: oldConfig, NewConfig: newConfig}
-							oldConfig = newConfig
-							if options.logger != nil {
-								options.logger.Printf("File changed: %s", event.Name)
+					debounceKey := event.Name
+					if options.batchAcrossFiles {
+						debounceKey = ""
+					}
+					if cycles != nil {
+						cycles.event(debounceKey, event.Name)
+					}
+
+					debounce.Event(debounceKey, fires.track(func() {
+						emitted := false
+						if cycles != nil {
+							end := cycles.fire(debounceKey, event.Name)
+							defer func() { end(emitted) }()
+						}
+						if observer != nil {
+							observer.OnDebounceFire(event.Name)
+						}
+
+						// Removed files have no content to check
+						if options.mimeType != "" && event.Op&(fsnotify.Remove|fsnotify.Rename) == 0 {
+							if err := checkMIMEType(event.Name, options.mimeType); err != nil {
+								options.errorHandler(err)
+								return
 							}
 						}
-					})
+
+						sent, _ := emit(ctx, event.Name, event.Op.String())
+						emitted = sent != 0
+					}))
 				}
 			}
 		}()
 
+		// Recreates the file watcher after it died, retrying with backoff until it succeeds or the context is done.
+		// Returns nil if the watcher must stop instead.
+		recoverWatcher := func() FileWatcher {
+			if !options.autoRecover {
+				return nil
+			}
+			options.logger.Printf("Watcher closed unexpectedly, recovering")
+			watcher.Close()
+
+			backoff := recoverInitialBackoff
+			for attempt := 1; ; attempt++ {
+				recovered, err := openFileWatcher(options.watcherFactory, awaited.watchPaths(paths))
+				if err == nil {
+					options.logger.Printf("Watcher recovered after %d attempt(s)", attempt)
+					return recovered
+				}
+				options.errorHandler(fmt.Errorf("failed to recover watcher: %w", err))
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, recoverMaxBackoff)
+			}
+		}
+
+		// Rotated files are waited for in the background, and re-added by the main loop once they reappear
+		rotated := make(chan rotationResult)
+		pendingRotations := make(map[string]bool)
+		watchesPath := make(map[string]bool, len(paths))
+		for _, pathToFile := range paths {
+			watchesPath[pathToFile] = true
+		}
+
 		// Main watcher loop
 		for {
 			select {
@@ -110,13 +529,65 @@ func ControlFileChanges[T any](ctx context.Context, pathToFile string, getCurren
 				options.logger.Printf("Watcher stopped by context cancellation")
 				return
 
-			case event, ok := <-watcher.Events:
+			case event, ok := <-watcher.Events():
 				if !ok {
-					return
+					recovered := recoverWatcher()
+					if recovered == nil {
+						return
+					}
+					watcher = recovered
+					continue
+				}
+
+				if options.watchParentDir && options.pathFilter == nil {
+					if pathToFile, ok := awaited.lookup(event.Name); ok {
+						// An awaited file appeared in its parent directory
+						if event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+							continue
+						}
+						if err := awaited.narrow(watcher, pathToFile, watchesPath); err != nil {
+							options.errorHandler(err)
+							continue
+						}
+						options.logger.Printf("File created: %s", pathToFile)
+						event.Name = pathToFile
+					} else if !watchesPath[event.Name] {
+						// Other files of the parent directory of an awaited file
+						continue
+					}
 				}
 
-				// Process only relevant file events (write or create)
-				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				if options.rotationRecovery && event.Op&(fsnotify.Rename|fsnotify.Remove) != 0 &&
+					watchesPath[event.Name] && !pendingRotations[event.Name] {
+					pendingRotations[event.Name] = true
+					goroutines.Add(1)
+					go func(pathToFile string) {
+						defer goroutines.Done()
+						result := rotationResult{path: pathToFile, err: waitForFile(ctx, pathToFile, options.rotationGracePeriod)}
+						select {
+						case rotated <- result:
+						case <-done:
+						}
+					}(event.Name)
+					continue
+				}
+
+				if options.clock.Now().Before(graceEnd) {
+					continue
+				}
+
+				// Process only relevant file events (write or create, and removal of the files of a directory)
+				relevant := fsnotify.Write | fsnotify.Create
+				if options.pathFilter != nil {
+					if !options.pathFilter(event.Name) {
+						continue
+					}
+					relevant |= fsnotify.Remove | fsnotify.Rename
+				}
+				if event.Op&relevant != 0 {
+					if observer != nil {
+						observer.OnEvent(event.Name)
+					}
 					select {
 					case eventChannel <- event:
 					default:
@@ -124,14 +595,41 @@ func ControlFileChanges[T any](ctx context.Context, pathToFile string, getCurren
 					}
 				}
 
-			case err, ok := <-watcher.Errors:
+			case result := <-rotated:
+				delete(pendingRotations, result.path)
+				if result.err != nil {
+					if ctx.Err() == nil {
+						options.errorHandler(result.err)
+					}
+					continue
+				}
+
+				// The old watch may already be gone with the old file
+				_ = watcher.Remove(result.path)
+				if err := watcher.Add(result.path); err != nil {
+					options.errorHandler(ErrWatchAdd{Path: result.path, Err: err})
+					continue
+				}
+				options.logger.Printf("File rotated: %s", result.path)
+
+				select {
+				case eventChannel <- fsnotify.Event{Name: result.path, Op: fsnotify.Create}:
+				default:
+				}
+
+			case err, ok := <-watcher.Errors():
 				if !ok {
-					return
+					recovered := recoverWatcher()
+					if recovered == nil {
+						return
+					}
+					watcher = recovered
+					continue
 				}
 				options.errorHandler(err)
 			}
 		}
 	}()
 
-	return updates, nil
+	return &WatchHandle[T]{updates: updates, fanOut: fanOut, reload: emit, ack: ack, done: finished}, nil
 }
@@ -2,12 +2,19 @@ package watcher
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/vsysa/kongkit/watcher/testutil"
 )
 
 // Helper function to write to a file.
@@ -51,6 +58,9 @@ func TestControlFileChanges_Basic(t *testing.T) {
 	case event := <-updates:
 		assert.Equal(t, "initial", event.OldConfig, "Old config should match initial value")
 		assert.Equal(t, "updated", event.NewConfig, "New config should match updated value")
+		assert.Equal(t, tempFile, event.Source, "Source should match the watched file")
+		assert.NotEmpty(t, event.Operation, "Operation should be set")
+		assert.WithinDuration(t, time.Now(), event.Timestamp, time.Second, "Timestamp should be set")
 	case <-ctx.Done():
 		t.Fatal("Timeout waiting for file change event")
 	}
@@ -61,25 +71,36 @@ func TestControlFileChanges_Basic(t *testing.T) {
 // When multiple rapid updates are made to a file, only the final state after the debounce interval should trigger an update event.
 // The test ensures intermediate changes are ignored and the last valid update is processed correctly.
 func TestControlFileChanges_WithDebounce(t *testing.T) {
-	tempFile := createTempFile(t, "initial")
-	defer os.Remove(tempFile)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	updates, err := ControlFileChanges(ctx, tempFile, func() string {
-		data, _ := os.ReadFile(tempFile)
-		return string(data)
-	}, WithDebounce(500*time.Millisecond))
+	clock := &countingClock{FakeClock: testutil.NewFakeClock(time.Now())}
+	factory := newFakeWatcherFactory()
+	var mutex sync.Mutex
+	content := "initial"
+	updates, err := ControlFileChanges(ctx, "config.yaml", func() string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return content
+	}, WithDebounce(500*time.Millisecond), WithClock(clock), WithWatcherFactory(factory.create))
 	require.NoError(t, err, "Failed to start watcher with debounce")
+	events := factory.next(t).events
 
 	// Trigger multiple rapid changes
-	writeFile(t, tempFile, "update1")
-	writeFile(t, tempFile, "update2")
-	writeFile(t, tempFile, "update3")
+	for i, update := range []string{"update1", "update2", "update3"} {
+		mutex.Lock()
+		content = update
+		mutex.Unlock()
+		events <- fsnotify.Event{Name: "config.yaml", Op: fsnotify.Write}
+		require.Eventually(t, func() bool { return clock.started() == i+1 }, time.Second, time.Millisecond,
+			"Every change should restart the debounce")
+		clock.Advance(100 * time.Millisecond)
+	}
 
-	// Wait for debounce period
-	time.Sleep(1 * time.Second)
+	// The debounce period ends 500ms after the last change
+	clock.Advance(399 * time.Millisecond)
+	assert.Equal(t, 1, clock.PendingTimers(), "The debounce should not fire before its duration")
+	clock.Advance(time.Millisecond)
 
 	select {
 	case event := <-updates:
@@ -88,6 +109,7 @@ func TestControlFileChanges_WithDebounce(t *testing.T) {
 	case <-ctx.Done():
 		t.Fatal("Timeout waiting for debounce event")
 	}
+	assert.Zero(t, clock.PendingTimers(), "The changes should fire a single event")
 }
 
 // TestControlFileChanges_ErrorHandling
@@ -175,3 +197,526 @@ func TestControlFileChanges_PanicRecoveryInConfigRead(t *testing.T) {
 		t.Fatal("Timeout waiting for watcher event after panic recovery")
 	}
 }
+
+// TestControlFilesChanges_PerFileDebounce
+// This test verifies that, by default, changes to different files are debounced separately.
+// Two files are updated within the same debounce window, and an event is expected for each of them.
+func TestControlFilesChanges_PerFileDebounce(t *testing.T) {
+	firstFile := createTempFile(t, "a1")
+	defer os.Remove(firstFile)
+	secondFile := createTempFile(t, "b1")
+	defer os.Remove(secondFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlFilesChanges(ctx, []string{firstFile, secondFile}, func() string {
+		first, _ := os.ReadFile(firstFile)
+		second, _ := os.ReadFile(secondFile)
+		return string(first) + "," + string(second)
+	}, WithDebounce(200*time.Millisecond))
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, firstFile, "a2")
+	writeFile(t, secondFile, "b2")
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-updates:
+			assert.Equal(t, "a2,b2", event.NewConfig, "New config should contain both updates")
+		case <-ctx.Done():
+			t.Fatalf("Timeout waiting for event %d", i+1)
+		}
+	}
+}
+
+// TestControlFilesChanges_BatchAcrossFiles
+// This test verifies that WithBatchAcrossFiles coalesces changes to several files into a single event.
+// Three files are updated within the debounce window, and exactly one event with the final state is expected.
+func TestControlFilesChanges_BatchAcrossFiles(t *testing.T) {
+	var paths []string
+	for i := 0; i < 3; i++ {
+		path := createTempFile(t, "initial")
+		defer os.Remove(path)
+		paths = append(paths, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	readCounter := 0
+	updates, err := ControlFilesChanges(ctx, paths, func() int {
+		readCounter++
+		return readCounter
+	}, WithDebounce(200*time.Millisecond), WithBatchAcrossFiles())
+	require.NoError(t, err, "Failed to start watcher")
+
+	for _, path := range paths {
+		writeFile(t, path, "updated")
+	}
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, 1, event.OldConfig, "Old config should be the initial read")
+		assert.Equal(t, 2, event.NewConfig, "Config should be read once for all files")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for batched event")
+	}
+
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected second event: %+v", event)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// TestControlFilesChanges_PanicRecoveryPerFile
+// This test verifies that a panic in getCurrentConfigFn only drops the change that triggered it.
+// The read for the first file panics, and the following changes to both files must still be emitted
+// against the last successfully read configuration.
+func TestControlFilesChanges_PanicRecoveryPerFile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	factory := newFakeWatcherFactory()
+	panics := make(chan interface{}, 1)
+	readCounter := 0
+	updates, err := ControlFilesChanges(ctx, []string{"a.yaml", "b.yaml"}, func() int {
+		readCounter++
+		// The first read is performed by the library to initialize the initial configuration value.
+		if readCounter == 2 {
+			panic("simulated panic in getCurrentConfigFn")
+		}
+		return readCounter
+	}, WithDebounce(0), WithPanicHandler(func(recovered interface{}, stack []byte) {
+		panics <- recovered
+	}), WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	fake := factory.next(t)
+
+	fake.events <- fsnotify.Event{Name: "a.yaml", Op: fsnotify.Write}
+	select {
+	case recovered := <-panics:
+		assert.Equal(t, "simulated panic in getCurrentConfigFn", recovered, "Panic should be passed to the handler")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for panic in getCurrentConfigFn")
+	}
+
+	for _, expected := range []ChangeEvent[int]{
+		{Source: "b.yaml", OldConfig: 1, NewConfig: 3},
+		{Source: "a.yaml", OldConfig: 3, NewConfig: 4},
+	} {
+		fake.events <- fsnotify.Event{Name: expected.Source, Op: fsnotify.Write}
+		select {
+		case event := <-updates:
+			assert.Equal(t, expected.Source, event.Source, "Event should come from the changed file")
+			assert.Equal(t, expected.OldConfig, event.OldConfig, "Old config should be the last successful read")
+			assert.Equal(t, expected.NewConfig, event.NewConfig, "New config should be read after the panic")
+		case <-ctx.Done():
+			t.Fatalf("Timeout waiting for event from %s", expected.Source)
+		}
+	}
+}
+
+// TestControlFileChanges_PanicHandler
+// This test verifies that panics in getCurrentConfigFn are passed to the panic handler together with a stack trace,
+// and that they are forwarded to the error handler only when WithPropagatedPanicToError is set.
+func TestControlFileChanges_PanicHandler(t *testing.T) {
+	for _, propagate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("propagate=%v", propagate), func(t *testing.T) {
+			tempFile := createTempFile(t, "initial")
+			defer os.Remove(tempFile)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			type recoveredPanic struct {
+				value interface{}
+				stack []byte
+			}
+			panics := make(chan recoveredPanic, 1)
+			errs := make(chan error, 1)
+
+			readCounter := 0
+			opts := []Option{
+				WithDebounce(0),
+				WithPanicHandler(func(recovered interface{}, stack []byte) {
+					panics <- recoveredPanic{value: recovered, stack: stack}
+				}),
+				WithErrorHandler(func(err error) {
+					errs <- err
+				}),
+			}
+			if propagate {
+				opts = append(opts, WithPropagatedPanicToError())
+			}
+			_, err := ControlFileChanges(ctx, tempFile, func() string {
+				readCounter++
+				if readCounter == 2 {
+					panic("simulated panic in getCurrentConfigFn")
+				}
+				return "config"
+			}, opts...)
+			require.NoError(t, err, "Failed to start watcher")
+
+			writeFile(t, tempFile, "updated")
+
+			select {
+			case p := <-panics:
+				assert.Equal(t, "simulated panic in getCurrentConfigFn", p.value, "Panic handler should receive the recovered value")
+				assert.Contains(t, string(p.stack), "runtime/debug.Stack", "Panic handler should receive the stack trace")
+			case <-ctx.Done():
+				t.Fatal("Timeout waiting for panic handler")
+			}
+
+			select {
+			case err := <-errs:
+				assert.True(t, propagate, "Panic should not be forwarded to the error handler: %v", err)
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, propagate, "Panic should be forwarded to the error handler")
+			}
+		})
+	}
+}
+
+// TestControlFileChanges_PointerConfig
+// This test verifies that a pointer configuration type is emitted as distinct snapshots:
+// the old config of an event is the new config of the previous event, and never the same pointer as the new config.
+func TestControlFileChanges_PointerConfig(t *testing.T) {
+	type bigConfig struct {
+		Content string
+		Data    [1024]byte
+	}
+
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlFileChanges(ctx, tempFile, func() *bigConfig {
+		data, _ := os.ReadFile(tempFile)
+		return &bigConfig{Content: string(data)}
+	}, WithDebounce(50*time.Millisecond))
+	require.NoError(t, err, "Failed to start watcher")
+
+	var previous *bigConfig
+	for i, content := range []string{"first", "second"} {
+		writeFile(t, tempFile, content)
+
+		select {
+		case event := <-updates:
+			assert.NotSame(t, event.OldConfig, event.NewConfig, "Old and new configs should be distinct snapshots")
+			assert.Equal(t, content, event.NewConfig.Content, "New config should hold the written content")
+			if i == 0 {
+				assert.Equal(t, "initial", event.OldConfig.Content, "Old config should hold the initial content")
+			} else {
+				assert.Same(t, previous, event.OldConfig, "Old config should be the previous new config")
+				assert.Equal(t, "first", event.OldConfig.Content, "Old config should not be mutated by later reads")
+			}
+			previous = event.NewConfig
+		case <-ctx.Done():
+			t.Fatal("Timeout waiting for file change event")
+		}
+	}
+}
+
+// TestControlFileChanges_StartupGrace
+// This test verifies that file events are ignored during the startup grace period and detected afterwards.
+func TestControlFileChanges_StartupGrace(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithStartupGrace(300*time.Millisecond))
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, tempFile, "during grace")
+
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected event during the startup grace period: %+v", event)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	writeFile(t, tempFile, "after grace")
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "initial", event.OldConfig, "Old config should be the initial read")
+		assert.Equal(t, "after grace", event.NewConfig, "New config should match the write after the grace period")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for file change event after the grace period")
+	}
+}
+
+// TestControlFileChanges_FieldChangeFilter
+// This test verifies that WithFieldChangeFilter suppresses uninteresting changes while keeping the baseline up to date.
+// Only changes of the first line (the "password") are emitted, and the emitted event compares against the last read,
+// including the suppressed changes.
+func TestControlFileChanges_FieldChangeFilter(t *testing.T) {
+	tempFile := createTempFile(t, "secret\nport=1")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	password := func(config string) string {
+		return strings.SplitN(config, "\n", 2)[0]
+	}
+	updates, err := ControlFileChanges(ctx, tempFile, func() string {
+		data, _ := os.ReadFile(tempFile)
+		return string(data)
+	}, WithFieldChangeFilter(func(oldCfg, newCfg string) bool {
+		return password(oldCfg) != password(newCfg)
+	}))
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, tempFile, "secret\nport=2")
+
+	select {
+	case event := <-updates:
+		t.Fatalf("Unexpected event for an uninteresting change: %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	writeFile(t, tempFile, "rotated\nport=2")
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "secret\nport=2", event.OldConfig, "Old config should include the suppressed change")
+		assert.Equal(t, "rotated\nport=2", event.NewConfig, "New config should match the interesting change")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the interesting change")
+	}
+}
+
+// TestControlFileChanges_FieldChangeFilterType
+// This test verifies that a field change filter of another type than the configuration fails the start of the watcher
+// instead of silently emitting every change.
+func TestControlFileChanges_FieldChangeFilterType(t *testing.T) {
+	tempFile := createTempFile(t, "initial")
+	defer os.Remove(tempFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := ControlFileChanges(ctx, tempFile, func() string {
+		return "initial"
+	}, WithFieldChangeFilter(func(oldCfg, newCfg int) bool {
+		return oldCfg != newCfg
+	}))
+	assert.ErrorContains(t, err, "field change filter takes a int, but the configuration is a string",
+		"A filter of another type should fail the start")
+}
+
+// eventObserver records the paths of the relevant file events.
+type eventObserver struct {
+	closeObserver
+	events chan string
+}
+
+func (o *eventObserver) OnEvent(path string) {
+	o.events <- path
+}
+
+// TestControlFileChanges_WatchParentDir
+// This test verifies that WithWatchParentDir starts watching a file that does not exist yet.
+// Other files of the directory must be ignored, the creation of the file must trigger an event,
+// and later writes must still be detected once the watch is narrowed to the file.
+func TestControlFileChanges_WatchParentDir(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := ControlFileChanges(ctx, configFile, func() string { return "" })
+	require.Error(t, err, "Watching a missing file should fail without WithWatchParentDir")
+
+	clock := testutil.NewFakeClock(time.Now())
+	factory := newFakeWatcherFactory()
+	observer := &eventObserver{closeObserver: closeObserver{closed: make(chan struct{})}, events: make(chan string, 10)}
+	updates, err := ControlFileChanges(ctx, configFile, func() string {
+		data, _ := os.ReadFile(configFile)
+		return string(data)
+	}, WithWatchParentDir(), WithDebounce(time.Second), WithClock(clock), WithObserver(observer),
+		WithWatcherFactory(factory.create))
+	require.NoError(t, err, "Failed to start watcher")
+	w := factory.next(t)
+	assert.Equal(t, []string{dir}, w.watchedPaths(), "The parent directory should be watched")
+
+	// Sends a file event and fires its debounce
+	change := func(op fsnotify.Op) {
+		w.events <- fsnotify.Event{Name: configFile, Op: op}
+		require.Eventually(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, time.Millisecond,
+			"The event should start the debounce")
+		clock.Advance(time.Second)
+	}
+
+	// The events are handled in order, so the event of the other file is handled before the creation is observed
+	w.events <- fsnotify.Event{Name: filepath.Join(dir, "other.yaml"), Op: fsnotify.Create}
+	writeFile(t, configFile, "created")
+	change(fsnotify.Create)
+	select {
+	case path := <-observer.events:
+		assert.Equal(t, configFile, path, "Other files of the directory should be ignored")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the event of the created file")
+	}
+	assert.Equal(t, []string{configFile}, w.watchedPaths(), "The watch should be narrowed to the file")
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "", event.OldConfig, "Old config should be the read of the missing file")
+		assert.Equal(t, "created", event.NewConfig, "New config should be the created file")
+		assert.Equal(t, configFile, event.Source, "Source should be the watched file")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the creation of the file")
+	}
+
+	writeFile(t, configFile, "updated")
+	change(fsnotify.Write)
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, "updated", event.NewConfig, "New config should match the update")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for the update of the created file")
+	}
+}
+
+// TestControlFilesChanges_FileStat
+// This test verifies that WithFileStat reports the modification time of the file that triggered the event.
+// The other watched file has an old modification time, which must not be reported.
+func TestControlFilesChanges_FileStat(t *testing.T) {
+	otherFile := createTempFile(t, "a1")
+	defer os.Remove(otherFile)
+	changedFile := createTempFile(t, "b1")
+	defer os.Remove(changedFile)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(otherFile, old, old))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updates, err := ControlFilesChanges(ctx, []string{otherFile, changedFile}, func() string {
+		data, _ := os.ReadFile(changedFile)
+		return string(data)
+	}, WithFileStat())
+	require.NoError(t, err, "Failed to start watcher")
+
+	writeFile(t, changedFile, "b2")
+	select {
+	case event := <-updates:
+		assert.Equal(t, changedFile, event.Source, "Event should be triggered by the changed file")
+		assert.WithinDuration(t, time.Now(), event.FileModTime, 2*time.Second, "Modification time of the changed file should be reported")
+	case <-ctx.Done():
+		t.Fatal("Timeout waiting for event")
+	}
+}
+
+// recordingLogger is a Logger recording the formatted messages for the test.
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+// TestWatchHandle_IdempotentRead
+// This test verifies that WithIdempotentRead reads the configuration until two consecutive reads are equal,
+// and uses the last read with a warning when the reads never stabilize.
+func TestWatchHandle_IdempotentRead(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// The file settles on the third read of the first change, and never during the second one
+	reads := []int{0, 1, 2, 2, 3, 4, 5}
+	readCounter := 0
+	logger := &recordingLogger{}
+	handle, err := Watch(ctx, "config.yaml", func() int {
+		value := reads[readCounter]
+		readCounter++
+		return value
+	}, WithIdempotentRead(3, time.Millisecond), WithLogger(logger), WithWatcherFactory(newFakeWatcherFactory().create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	go func() {
+		_ = handle.Reload()
+		_ = handle.Reload()
+	}()
+
+	event := <-handle.Events()
+	assert.Equal(t, 2, event.NewConfig, "The first stable configuration should be used")
+	event = <-handle.Events()
+	assert.Equal(t, 5, event.NewConfig, "The last read should be used when the reads never stabilize")
+	assert.Equal(t, len(reads), readCounter, "Every change should be read until stable or up to the attempts")
+
+	// The warning is logged before the event is sent
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	var warnings []string
+	for _, message := range logger.messages {
+		if strings.Contains(message, "did not stabilize") {
+			warnings = append(warnings, message)
+		}
+	}
+	assert.Equal(t, []string{`Configuration of "" did not stabilize after 3 reads, using the last one`}, warnings,
+		"A warning should be logged for the unstable reads only")
+}
+
+// TestWatchHandle_IdempotentReadClock
+// This test verifies that the stabilizing reads of WithIdempotentRead wait on the clock of the watcher,
+// without holding up other changes, and that the watcher stops while they wait.
+func TestWatchHandle_IdempotentReadClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	clock := testutil.NewFakeClock(time.Now())
+	var mutex sync.Mutex
+	value := 1
+	handle, err := Watch(ctx, "config.yaml", func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return value
+	}, WithIdempotentRead(2, time.Hour), WithClock(clock), WithWatcherFactory(newFakeWatcherFactory().create))
+	require.NoError(t, err, "Failed to start watcher")
+
+	// Two reloads wait for their second read at the same time
+	go func() { _ = handle.Reload() }()
+	go func() { _ = handle.Reload() }()
+	require.Eventually(t, func() bool {
+		return clock.PendingTimers() == 2
+	}, time.Second, time.Millisecond, "The reloads should wait on the clock")
+
+	clock.Advance(time.Hour)
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-handle.Events():
+			assert.Equal(t, 1, event.NewConfig, "The stable configuration should be sent")
+		case <-ctx.Done():
+			t.Fatal("Timeout waiting for the reload event")
+		}
+	}
+
+	go func() { _ = handle.Reload() }()
+	require.Eventually(t, func() bool {
+		return clock.PendingTimers() == 1
+	}, time.Second, time.Millisecond, "The reload should wait on the clock")
+	cancel()
+	select {
+	case <-handle.Done():
+	case <-time.After(time.Second):
+		t.Fatal("The watcher should stop while the reads wait")
+	}
+}